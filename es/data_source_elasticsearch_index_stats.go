@@ -0,0 +1,227 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func dataSourceElasticsearchIndexStats() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_index_stats` can be used to retrieve the indexing, search and merge statistics of indices, e.g. to size autoscaling policies or alerting thresholds from the actual load. The statistics cover all the shards of the indices, primaries and replicas, since they were allocated.",
+		Read:        dataSourceElasticsearchIndexStatsRead,
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "_all",
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "The name of the indices, wildcard expressions and comma separated lists are supported. Defaults to `_all`.",
+			},
+			"indices": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The statistics of the indices, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the index.",
+						},
+						"docs_count": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of documents of the index, including the replicas.",
+						},
+						"indexing_total": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of indexing operations.",
+						},
+						"indexing_time_in_millis": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The time spent on indexing operations, in milliseconds.",
+						},
+						"indexing_ops_per_busy_second": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The number of indexing operations per second spent on indexing, `indexing_total` divided by `indexing_time_in_millis`. It is not a rate over the wall-clock time.",
+						},
+						"write_load": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The average number of write threads used by the indexing operations, available from ElasticSearch >= 8.6 and `0` otherwise.",
+						},
+						"search_query_total": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of search queries.",
+						},
+						"search_query_time_in_millis": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The time spent on search queries, in milliseconds.",
+						},
+						"search_queries_per_busy_second": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: "The number of search queries per second spent on queries, `search_query_total` divided by `search_query_time_in_millis`. It is not a rate over the wall-clock time.",
+						},
+						"merges_current": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of running merges.",
+						},
+						"merges_total": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The number of merges.",
+						},
+						"merges_total_time_in_millis": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The time spent on merges, in milliseconds.",
+						},
+						"merges_total_size_in_bytes": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "The size of the merged segments, in bytes.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchIndexStatsRead(d *schema.ResourceData, meta interface{}) error {
+	index := d.Get("index").(string)
+
+	path, err := uritemplates.Expand("/{index}/_stats/docs,indexing,search,merge", map[string]string{
+		"index": index,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for index stats: %+v", err)
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	var body json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return fmt.Errorf("error getting the stats of the indices %s: %+v", index, err)
+	}
+
+	var response IndexStatsResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling index stats body: %+v: %+v", err, body)
+	}
+
+	d.SetId(fmt.Sprintf("index_stats/%s", index))
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("indices", flattenIndexStats(response.Indices))
+	return ds.err
+}
+
+func flattenIndexStats(indices map[string]IndexStats) []interface{} {
+	names := make([]string, 0, len(indices))
+	for name := range indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flattened := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		stats := indices[name].Total
+		flattened = append(flattened, map[string]interface{}{
+			"name":                           name,
+			"docs_count":                     stats.Docs.Count,
+			"indexing_total":                 stats.Indexing.IndexTotal,
+			"indexing_time_in_millis":        stats.Indexing.IndexTimeInMillis,
+			"indexing_ops_per_busy_second":   indexStatsOpsPerBusySecond(stats.Indexing.IndexTotal, stats.Indexing.IndexTimeInMillis),
+			"write_load":                     stats.Indexing.WriteLoad,
+			"search_query_total":             stats.Search.QueryTotal,
+			"search_query_time_in_millis":    stats.Search.QueryTimeInMillis,
+			"search_queries_per_busy_second": indexStatsOpsPerBusySecond(stats.Search.QueryTotal, stats.Search.QueryTimeInMillis),
+			"merges_current":                 stats.Merges.Current,
+			"merges_total":                   stats.Merges.Total,
+			"merges_total_time_in_millis":    stats.Merges.TotalTimeInMillis,
+			"merges_total_size_in_bytes":     stats.Merges.TotalSizeInBytes,
+		})
+	}
+	return flattened
+}
+
+// indexStatsOpsPerBusySecond returns the number of operations per second of
+// the time spent on them, not of the wall-clock time, 0 when no time was spent
+func indexStatsOpsPerBusySecond(total, timeInMillis int) float64 {
+	if timeInMillis == 0 {
+		return 0
+	}
+	return float64(total) * 1000 / float64(timeInMillis)
+}
+
+type IndexStatsResponse struct {
+	Indices map[string]IndexStats `json:"indices"`
+}
+
+type IndexStats struct {
+	Total IndexStatsDetails `json:"total"`
+}
+
+type IndexStatsDetails struct {
+	Docs struct {
+		Count int `json:"count"`
+	} `json:"docs"`
+	Indexing struct {
+		IndexTotal        int     `json:"index_total"`
+		IndexTimeInMillis int     `json:"index_time_in_millis"`
+		WriteLoad         float64 `json:"write_load"`
+	} `json:"indexing"`
+	Search struct {
+		QueryTotal        int `json:"query_total"`
+		QueryTimeInMillis int `json:"query_time_in_millis"`
+	} `json:"search"`
+	Merges struct {
+		Current           int `json:"current"`
+		Total             int `json:"total"`
+		TotalTimeInMillis int `json:"total_time_in_millis"`
+		TotalSizeInBytes  int `json:"total_size_in_bytes"`
+	} `json:"merges"`
+}
@@ -0,0 +1,75 @@
+package es
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccElasticsearchDataSourceIndexStats(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceIndexStats,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_index_stats.test", "indices.#", "1"),
+					resource.TestCheckResourceAttr("data.elasticsearch_index_stats.test", "indices.0.name", "terraform-test-index-stats"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_index_stats.test", "indices.0.indexing_ops_per_busy_second"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_index_stats.test", "indices.0.merges_total"),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenIndexStats(t *testing.T) {
+	var response IndexStatsResponse
+	body := `{"indices":{
+		"b":{"total":{"docs":{"count":10},"indexing":{"index_total":200,"index_time_in_millis":400,"write_load":0.5},"search":{"query_total":0,"query_time_in_millis":0},"merges":{"current":1,"total":3,"total_time_in_millis":30,"total_size_in_bytes":1024}}},
+		"a":{"total":{"docs":{"count":0}}}
+	}}`
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	flattened := flattenIndexStats(response.Indices)
+	if len(flattened) != 2 || flattened[0].(map[string]interface{})["name"] != "a" {
+		t.Fatalf("expected the stats of 2 indices sorted by name, got %v", flattened)
+	}
+	expected := map[string]interface{}{
+		"name":                           "b",
+		"docs_count":                     10,
+		"indexing_total":                 200,
+		"indexing_time_in_millis":        400,
+		"indexing_ops_per_busy_second":   float64(500),
+		"write_load":                     0.5,
+		"search_query_total":             0,
+		"search_query_time_in_millis":    0,
+		"search_queries_per_busy_second": float64(0),
+		"merges_current":                 1,
+		"merges_total":                   3,
+		"merges_total_time_in_millis":    30,
+		"merges_total_size_in_bytes":     1024,
+	}
+	if !reflect.DeepEqual(flattened[1], expected) {
+		t.Errorf("expected the stats %v, got %v", expected, flattened[1])
+	}
+}
+
+var testAccElasticsearchDataSourceIndexStats = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test-index-stats"
+  number_of_shards   = 1
+  number_of_replicas = 0
+}
+
+data "elasticsearch_index_stats" "test" {
+  index = elasticsearch_index.test.name
+}
+`
@@ -0,0 +1,131 @@
+package es
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+func dataSourceElasticsearchKibanaAlert() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_kibana_alert` can be used to retrieve an existing Kibana alert by name, e.g. to reference its ID or to generate an import block.",
+		Read:        dataSourceElasticsearchKibanaAlertRead,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the alert to retrieve, it must match exactly one alert.",
+			},
+			"space_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ID of the Kibana space to search the alert in, the default space when not set.",
+			},
+			"alert_type_id": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"consumer": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"enabled": {
+				Type:     schema.TypeBool,
+				Computed: true,
+			},
+			"tags": {
+				Type:     schema.TypeSet,
+				Computed: true,
+				Elem:     &schema.Schema{Type: schema.TypeString},
+			},
+			"schedule": {
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"interval": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"throttle": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"notify_when": {
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	name := d.Get("name").(string)
+	spaceID := d.Get("space_id").(string)
+
+	var alerts []kibana.Alert
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		alerts, err = kibanaFindAlerts(client, spaceID, name, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// the search is a full text query, only keep exact matches
+	var matches []kibana.Alert
+	for _, alert := range alerts {
+		if alert.Name == name {
+			matches = append(matches, alert)
+		}
+	}
+
+	if len(matches) == 0 {
+		return fmt.Errorf("no Kibana alert found with name %q%s", name, kibanaSpaceDescription(spaceID))
+	} else if len(matches) > 1 {
+		return fmt.Errorf("1 Kibana alert expected with name %q%s, found %d", name, kibanaSpaceDescription(spaceID), len(matches))
+	}
+	alert := matches[0]
+
+	d.SetId(alert.ID)
+
+	schedule := make([]map[string]interface{}, 0, 1)
+	schedule = append(schedule, map[string]interface{}{"interval": alert.Schedule.Interval})
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("alert_type_id", alert.AlertTypeID)
+	ds.set("consumer", alert.Consumer)
+	ds.set("enabled", alert.Enabled)
+	ds.set("tags", alert.Tags)
+	ds.set("schedule", schedule)
+	ds.set("throttle", alert.Throttle)
+	ds.set("notify_when", alert.NotifyWhen)
+
+	return ds.err
+}
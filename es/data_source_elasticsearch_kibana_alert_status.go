@@ -0,0 +1,104 @@
+package es
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+var executionStatusKibanaVersion, _ = version.NewVersion("7.11.0")
+
+func dataSourceElasticsearchKibanaAlertStatus() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_kibana_alert_status` can be used to retrieve the execution status of a Kibana alert, e.g. to monitor alerts which are failing to run.",
+		Read:        dataSourceElasticsearchKibanaAlertStatusRead,
+		Schema: map[string]*schema.Schema{
+			"alert_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "ID of the alert.",
+			},
+			"execution_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Status of the last execution of the alert, e.g. `ok`, `active`, `error` or `pending`.",
+			},
+			"last_execution_date": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Date of the last execution of the alert.",
+			},
+			"last_duration": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Duration of the last execution of the alert in milliseconds, only returned from Kibana >= 8.0.",
+			},
+			"error_reason": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Reason of the error of the last execution, if the status is `error`.",
+			},
+			"error_message": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Message of the error of the last execution, if the status is `error`.",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchKibanaAlertStatusRead(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(executionStatusKibanaVersion) {
+		return fmt.Errorf("Kibana Alert execution status only available from Kibana >= 7.11, got version %s", elasticVersion.String())
+	}
+
+	id := d.Get("alert_id").(string)
+	spaceID := ""
+
+	var alert kibana.Alert
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		alert, err = kibanaGetAlert(client, id, spaceID, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	d.SetId(id)
+
+	status := kibana.AlertExecutionStatus{}
+	if alert.ExecutionStatus != nil {
+		status = *alert.ExecutionStatus
+	}
+	errorReason, errorMessage := "", ""
+	if status.Error != nil {
+		errorReason, errorMessage = status.Error.Reason, status.Error.Message
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("execution_status", status.Status)
+	ds.set("last_execution_date", status.LastExecutionDate)
+	ds.set("last_duration", status.LastDuration)
+	ds.set("error_reason", errorReason)
+	ds.set("error_message", errorMessage)
+
+	return ds.err
+}
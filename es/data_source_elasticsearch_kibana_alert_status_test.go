@@ -0,0 +1,48 @@
+package es
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchDataSourceKibanaAlertStatus_basic(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if elasticVersion.LessThan(executionStatusKibanaVersion) {
+				t.Skip("Kibana Alert execution status only supported on Kibana >= 7.11")
+			}
+		},
+		Providers: testAccKibanaProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceKibanaAlertStatus,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.elasticsearch_kibana_alert_status.test", "id", "elasticsearch_kibana_alert.test", "id"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_kibana_alert_status.test", "execution_status"),
+				),
+			},
+		},
+	})
+}
+
+var testAccElasticsearchDataSourceKibanaAlertStatus = testAccElasticsearchKibanaAlertNoActionsV77 + `
+data "elasticsearch_kibana_alert_status" "test" {
+  alert_id = elasticsearch_kibana_alert.test.id
+}
+`
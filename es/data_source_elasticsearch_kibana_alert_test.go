@@ -0,0 +1,129 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchDataSourceKibanaAlert_basic(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers: testAccKibanaProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceKibanaAlert,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.elasticsearch_kibana_alert.test", "id", "elasticsearch_kibana_alert.test", "id"),
+					resource.TestCheckResourceAttr("data.elasticsearch_kibana_alert.test", "alert_type_id", ".index-threshold"),
+					resource.TestCheckResourceAttr("data.elasticsearch_kibana_alert.test", "enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchDataSourceKibanaAlert_space(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	spaceID := "terraform-test"
+	if allowed {
+		if err := testKibanaAlertCreateSpace(spaceID); err != nil {
+			t.Errorf("error creating space fixture: %+v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers: testAccKibanaProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceKibanaAlertSpace(spaceID),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrPair("data.elasticsearch_kibana_alert.test", "id", "elasticsearch_kibana_alert.test", "id"),
+					resource.TestCheckResourceAttr("data.elasticsearch_kibana_alert.test", "space_id", spaceID),
+				),
+			},
+			{
+				// the alert isn't in the default space
+				Config:      testAccElasticsearchDataSourceKibanaAlertSpace(spaceID) + testAccElasticsearchDataSourceKibanaAlertDefaultSpace,
+				ExpectError: regexp.MustCompile("no Kibana alert found with name"),
+			},
+		},
+	})
+}
+
+var testAccElasticsearchDataSourceKibanaAlert = testAccElasticsearchKibanaAlertNoActionsV77 + `
+data "elasticsearch_kibana_alert" "test" {
+  name = elasticsearch_kibana_alert.test.name
+}
+`
+
+func testAccElasticsearchDataSourceKibanaAlertSpace(spaceID string) string {
+	return testAccElasticsearchKibanaAlertSpace(spaceID) + fmt.Sprintf(`
+data "elasticsearch_kibana_alert" "test" {
+  name     = elasticsearch_kibana_alert.test.name
+  space_id = "%s"
+}
+`, spaceID)
+}
+
+var testAccElasticsearchDataSourceKibanaAlertDefaultSpace = `
+data "elasticsearch_kibana_alert" "default_space" {
+  name = elasticsearch_kibana_alert.test.name
+}
+`
@@ -0,0 +1,121 @@
+package es
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+func dataSourceElasticsearchKibanaConnectorTypes() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_kibana_connector_types` can be used to retrieve the types of Kibana connectors, e.g. to check that a connector type is enabled and allowed by the license before creating connectors of this type.",
+		Read:        dataSourceElasticsearchKibanaConnectorTypesRead,
+		Schema: map[string]*schema.Schema{
+			"space_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ID of the Kibana space, the default space when not set.",
+			},
+			"connector_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The connector types, sorted by ID.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the connector type, e.g. `.slack`.",
+						},
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The display name of the connector type.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether connectors of this type can be used, i.e. the type is enabled in the Kibana configuration and by the license.",
+						},
+						"enabled_in_config": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the type is enabled in the Kibana configuration, see `xpack.actions.enabledActionTypes`.",
+						},
+						"enabled_in_license": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the type is allowed by the license of the cluster.",
+						},
+						"minimum_license_required": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The minimum license required to use connectors of this type, e.g. `gold`.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchKibanaConnectorTypesRead(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaConnectorCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	spaceID := d.Get("space_id").(string)
+
+	var connectorTypes []kibana.ConnectorType
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		connectorTypes, err = kibanaListConnectorTypes(client, spaceID, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana connector types endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+
+	if err != nil {
+		return fmt.Errorf("error listing the connector types%s: %+v", kibanaSpaceDescription(spaceID), err)
+	}
+
+	spaceIDOrDefault := spaceID
+	if spaceIDOrDefault == "" {
+		spaceIDOrDefault = "default"
+	}
+	d.SetId(fmt.Sprintf("connector_types/%s", spaceIDOrDefault))
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("connector_types", flattenKibanaConnectorTypes(connectorTypes))
+	return ds.err
+}
+
+func flattenKibanaConnectorTypes(connectorTypes []kibana.ConnectorType) []interface{} {
+	sort.Slice(connectorTypes, func(i, j int) bool {
+		return connectorTypes[i].ID < connectorTypes[j].ID
+	})
+
+	flattened := make([]interface{}, 0, len(connectorTypes))
+	for _, connectorType := range connectorTypes {
+		flattened = append(flattened, map[string]interface{}{
+			"id":                       connectorType.ID,
+			"name":                     connectorType.Name,
+			"enabled":                  connectorType.Enabled,
+			"enabled_in_config":        connectorType.EnabledInConfig,
+			"enabled_in_license":       connectorType.EnabledInLicense,
+			"minimum_license_required": connectorType.MinimumLicenseRequired,
+		})
+	}
+	return flattened
+}
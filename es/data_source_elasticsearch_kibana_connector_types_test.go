@@ -0,0 +1,83 @@
+package es
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+func TestAccElasticsearchDataSourceKibanaConnectorTypes(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if elasticVersion.LessThan(minimalKibanaVersion) {
+				t.Skip("Kibana connector types only supported on Kibana >= 7.7")
+			}
+		},
+		Providers: testAccKibanaProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceKibanaConnectorTypes,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_kibana_connector_types.test", "id", "connector_types/default"),
+					resource.TestCheckTypeSetElemNestedAttrs("data.elasticsearch_kibana_connector_types.test", "connector_types.*", map[string]string{
+						"id":                       ".index",
+						"enabled_in_config":        "true",
+						"minimum_license_required": "basic",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestFlattenKibanaConnectorTypes(t *testing.T) {
+	connectorTypes := flattenKibanaConnectorTypes([]kibana.ConnectorType{
+		{ID: ".slack", Name: "Slack", MinimumLicenseRequired: "gold", EnabledInConfig: true},
+		{ID: ".index", Name: "Index", MinimumLicenseRequired: "basic", Enabled: true, EnabledInConfig: true, EnabledInLicense: true},
+	})
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"id":                       ".index",
+			"name":                     "Index",
+			"enabled":                  true,
+			"enabled_in_config":        true,
+			"enabled_in_license":       true,
+			"minimum_license_required": "basic",
+		},
+		map[string]interface{}{
+			"id":                       ".slack",
+			"name":                     "Slack",
+			"enabled":                  false,
+			"enabled_in_config":        true,
+			"enabled_in_license":       false,
+			"minimum_license_required": "gold",
+		},
+	}
+	if !reflect.DeepEqual(connectorTypes, expected) {
+		t.Errorf("expected the connector types %v, got %v", expected, connectorTypes)
+	}
+}
+
+var testAccElasticsearchDataSourceKibanaConnectorTypes = `
+data "elasticsearch_kibana_connector_types" "test" {
+}
+`
@@ -0,0 +1,136 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+func dataSourceElasticsearchKibanaExport() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_kibana_export` exports Kibana saved objects, e.g. dashboards or alerting rules, as NDJSON. Together with `elasticsearch_kibana_import` it migrates saved objects between Kibana instances or spaces.",
+		Read:        dataSourceElasticsearchKibanaExportRead,
+		Schema: map[string]*schema.Schema{
+			"space_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "The ID of the Kibana space to export the saved objects from, the default space when not set.",
+			},
+			"types": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				ExactlyOneOf: []string{"types", "objects"},
+				Description:  "The types of the saved objects to export, all the objects of these types are exported, e.g. `dashboard` or `alert`.",
+			},
+			"objects": {
+				Type:         schema.TypeList,
+				Optional:     true,
+				ExactlyOneOf: []string{"types", "objects"},
+				Description:  "The saved objects to export.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The type of the saved object.",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "The ID of the saved object.",
+						},
+					},
+				},
+			},
+			"include_references_deep": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Also export the objects referenced by the exported objects, recursively, so that the import doesn't fail on missing references.",
+			},
+			"ndjson": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The exported saved objects, one JSON object per line, without the export details.",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchKibanaExportRead(d *schema.ResourceData, meta interface{}) error {
+	_, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	spaceID := d.Get("space_id").(string)
+	export := kibana.SavedObjectsExport{
+		Types:                 expandStringList(d.Get("types").(*schema.Set).List()),
+		IncludeReferencesDeep: d.Get("include_references_deep").(bool),
+		// the details line isn't a saved object, it would be imported as one
+		ExcludeExportDetails: true,
+	}
+	for _, raw := range d.Get("objects").([]interface{}) {
+		object := raw.(map[string]interface{})
+		export.Objects = append(export.Objects, kibana.SavedObjectReference{
+			Type: object["type"].(string),
+			ID:   object["id"].(string),
+		})
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	var ndjson string
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		ndjson, err = kibanaExportSavedObjects(client, spaceID, export)
+	default:
+		err = fmt.Errorf("Kibana saved objects export endpoint only available from ElasticSearch >= 7.0, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	spaceIDOrDefault := spaceID
+	if spaceIDOrDefault == "" {
+		spaceIDOrDefault = "default"
+	}
+	d.SetId(fmt.Sprintf("export/%s", spaceIDOrDefault))
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("ndjson", ndjson)
+	return ds.err
+}
+
+func kibanaExportSavedObjects(client *elastic7.Client, spaceID string, export kibana.SavedObjectsExport) (string, error) {
+	path, err := kibanaSpacePath(spaceID, "/api/saved_objects/_export", map[string]string{})
+	if err != nil {
+		return "", fmt.Errorf("error building URL path for saved objects export: %+v", err)
+	}
+
+	body, err := json.Marshal(export)
+	if err != nil {
+		return "", fmt.Errorf("Body Error: %s", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   string(body),
+	})
+	if err != nil {
+		return "", fmt.Errorf("error exporting saved objects%s: %+v", kibanaSpaceDescription(spaceID), err)
+	}
+
+	return string(res.Body), nil
+}
@@ -0,0 +1,64 @@
+package es
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchDataSourceKibanaExport(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana saved objects export only supported on ES >= 7.0")
+			}
+		},
+		Providers: testAccKibanaProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceKibanaExport,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_kibana_export.test", "id", "export/default"),
+					resource.TestMatchResourceAttr("data.elasticsearch_kibana_export.test", "ndjson", regexp.MustCompile(`"id":"terraform-test-import"`)),
+					// the export details aren't exported
+					resource.TestMatchResourceAttr("data.elasticsearch_kibana_export.test", "ndjson", regexp.MustCompile(`\A[^\n]*\n?\z`)),
+				),
+			},
+		},
+	})
+}
+
+var testAccElasticsearchDataSourceKibanaExport = testAccElasticsearchKibanaImport + `
+data "elasticsearch_kibana_export" "test" {
+  objects {
+    type = elasticsearch_kibana_import.test.imported_objects[0].type
+    id   = elasticsearch_kibana_import.test.imported_objects[0].destination_id
+  }
+}
+`
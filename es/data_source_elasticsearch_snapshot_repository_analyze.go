@@ -0,0 +1,173 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var repositoryAnalyzeMinimalVersion, _ = version.NewVersion("7.12.0")
+
+func dataSourceElasticsearchSnapshotRepositoryAnalyze() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_snapshot_repository_analyze` runs the repository analysis API to check that the object store of a snapshot repository behaves correctly, e.g. when registering a new repository. The analysis writes and reads blobs in the repository and can take a long time, it runs each time the data source is read.",
+		Read:        dataSourceElasticsearchSnapshotRepositoryAnalyzeRead,
+		Timeouts: &schema.ResourceTimeout{
+			Read: schema.DefaultTimeout(30 * time.Second),
+		},
+		Schema: map[string]*schema.Schema{
+			"repository": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the snapshot repository to analyze.",
+			},
+			"blob_count": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The total number of blobs to write to the repository, defaults to `100`. Realistic analyses need at least `2000`.",
+			},
+			"concurrency": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The number of write operations to perform concurrently, defaults to `10`.",
+			},
+			"max_blob_size": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10mb",
+				ValidateFunc: validation.StringMatch(byteValueRegexp, "must be a byte value, e.g. `10mb`"),
+				Description:  "The maximum size of a blob to create during the analysis, defaults to `10mb`. Realistic analyses need at least `2gb`.",
+			},
+			"coordinating_node": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Name of the node which coordinated the analysis.",
+			},
+			"write_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of write operations performed during the analysis.",
+			},
+			"write_total_size_bytes": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total size of the blobs written during the analysis, in bytes.",
+			},
+			"read_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of read operations performed during the analysis.",
+			},
+			"read_total_size_bytes": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The total size of the blobs read during the analysis, in bytes.",
+			},
+			"summary": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The JSON response of the analysis.",
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchSnapshotRepositoryAnalyzeRead(d *schema.ResourceData, meta interface{}) error {
+	repository := d.Get("repository").(string)
+	timeout := d.Timeout(schema.TimeoutRead)
+
+	path, err := uritemplates.Expand("/_snapshot/{repository}/_analyze", map[string]string{
+		"repository": repository,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for repository analysis: %+v", err)
+	}
+
+	params := url.Values{}
+	params.Set("blob_count", strconv.Itoa(d.Get("blob_count").(int)))
+	params.Set("concurrency", strconv.Itoa(d.Get("concurrency").(int)))
+	params.Set("max_blob_size", d.Get("max_blob_size").(string))
+	params.Set("timeout", fmt.Sprintf("%ds", int(timeout.Seconds())))
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	var body json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var elasticVersion *version.Version
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		if elasticVersion.LessThan(repositoryAnalyzeMinimalVersion) {
+			return fmt.Errorf("repository analysis endpoint only available from ElasticSearch >= 7.12, got version %s", elasticVersion.String())
+		}
+
+		// the analysis stops on its own at the timeout, leave it a bit of
+		// time to report
+		ctx, cancel := context.WithTimeout(context.Background(), timeout+10*time.Second)
+		defer cancel()
+
+		var res *elastic7.Response
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+			Params: params,
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = fmt.Errorf("repository analysis endpoint only available from ElasticSearch >= 7.12, got version < 7.0.0")
+	}
+	if err != nil {
+		return fmt.Errorf("error analyzing repository %s: %+v", repository, err)
+	}
+
+	var response struct {
+		CoordinatingNode struct {
+			Name string `json:"name"`
+		} `json:"coordinating_node"`
+		Summary struct {
+			Write struct {
+				Count          int `json:"count"`
+				TotalSizeBytes int `json:"total_size_bytes"`
+			} `json:"write"`
+			Read struct {
+				Count          int `json:"count"`
+				TotalSizeBytes int `json:"total_size_bytes"`
+			} `json:"read"`
+		} `json:"summary"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling repository analysis body: %+v: %+v", err, body)
+	}
+
+	d.SetId(repository)
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("coordinating_node", response.CoordinatingNode.Name)
+	ds.set("write_count", response.Summary.Write.Count)
+	ds.set("write_total_size_bytes", response.Summary.Write.TotalSizeBytes)
+	ds.set("read_count", response.Summary.Read.Count)
+	ds.set("read_total_size_bytes", response.Summary.Read.TotalSizeBytes)
+	ds.set("summary", string(body))
+	return ds.err
+}
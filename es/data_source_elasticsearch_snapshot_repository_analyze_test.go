@@ -0,0 +1,60 @@
+package es
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchDataSourceSnapshotRepositoryAnalyze(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(repositoryAnalyzeMinimalVersion) {
+				t.Skip("repository analysis only supported on ES >= 7.12")
+			}
+		},
+		Providers: testAccProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceSnapshotRepositoryAnalyze,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.elasticsearch_snapshot_repository_analyze.test", "write_count"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_snapshot_repository_analyze.test", "coordinating_node"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_snapshot_repository_analyze.test", "summary"),
+				),
+			},
+		},
+	})
+}
+
+var testAccElasticsearchDataSourceSnapshotRepositoryAnalyze = `
+resource "elasticsearch_snapshot_repository" "test" {
+  name = "terraform-test-analyze"
+  type = "fs"
+
+  settings = {
+    location = "/tmp/elasticsearch"
+  }
+}
+
+data "elasticsearch_snapshot_repository_analyze" "test" {
+  repository    = elasticsearch_snapshot_repository.test.name
+  blob_count    = 10
+  concurrency   = 2
+  max_blob_size = "1mb"
+}
+`
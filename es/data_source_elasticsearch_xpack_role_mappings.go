@@ -0,0 +1,171 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func dataSourceElasticsearchXpackRoleMappings() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_xpack_role_mappings` lists the XPack role mappings of the cluster, e.g. to audit which roles are granted or to generate the import blocks of existing mappings.",
+		Read:        dataSourceElasticsearchXpackRoleMappingsRead,
+		Schema: map[string]*schema.Schema{
+			"role": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list the role mappings which grant this role.",
+			},
+			"exclude_reserved": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the reserved role mappings, which have the `_reserved` metadata, are left out. Defaults to `false`.",
+			},
+			"names": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The sorted names of the role mappings.",
+			},
+			"role_mappings": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The role mappings, sorted by name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the role mapping.",
+						},
+						"enabled": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the role mapping is enabled.",
+						},
+						"roles": {
+							Type:        schema.TypeSet,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The roles granted by the role mapping.",
+						},
+						"rules": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The JSON rules of the role mapping.",
+						},
+						"metadata": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The JSON metadata of the role mapping.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchXpackRoleMappingsRead(d *schema.ResourceData, m interface{}) error {
+	body, err := xpackGetRoleMappings(m)
+	if err != nil {
+		return err
+	}
+
+	var response map[string]struct {
+		Enabled  bool                   `json:"enabled"`
+		Roles    []string               `json:"roles"`
+		Rules    json.RawMessage        `json:"rules"`
+		Metadata map[string]interface{} `json:"metadata"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &response); err != nil {
+			return fmt.Errorf("error unmarshalling role mappings body: %+v: %+v", err, body)
+		}
+	}
+
+	role := d.Get("role").(string)
+	excludeReserved := d.Get("exclude_reserved").(bool)
+
+	names := make([]string, 0, len(response))
+	for name, roleMapping := range response {
+		if reserved, ok := roleMapping.Metadata["_reserved"].(bool); excludeReserved && ok && reserved {
+			continue
+		}
+		if role != "" {
+			sort.Strings(roleMapping.Roles)
+			if i := sort.SearchStrings(roleMapping.Roles, role); i == len(roleMapping.Roles) || roleMapping.Roles[i] != role {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	roleMappings := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		roleMapping := response[name]
+		metadata, err := json.Marshal(roleMapping.Metadata)
+		if err != nil {
+			return err
+		}
+		roleMappings = append(roleMappings, map[string]interface{}{
+			"name":     name,
+			"enabled":  roleMapping.Enabled,
+			"roles":    roleMapping.Roles,
+			"rules":    string(roleMapping.Rules),
+			"metadata": string(metadata),
+		})
+	}
+
+	d.SetId(fmt.Sprintf("role_mappings/%s", role))
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("names", names)
+	ds.set("role_mappings", roleMappings)
+	return ds.err
+}
+
+// xpackGetRoleMappings returns the body of all the role mappings, it is empty
+// if there are none
+func xpackGetRoleMappings(m interface{}) (json.RawMessage, error) {
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		res, err := client.PerformRequest(context.Background(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_security/role_mapping",
+		})
+		if elastic7.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return res.Body, nil
+	case *elastic6.Client:
+		res, err := client.PerformRequest(context.Background(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_xpack/security/role_mapping",
+		})
+		if elastic6.IsNotFound(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return res.Body, nil
+	default:
+		return nil, errors.New("unhandled client type")
+	}
+}
@@ -0,0 +1,57 @@
+package es
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccElasticsearchDataSourceXpackRoleMappings_basic(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers: testAccXPackProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceXpackRoleMappings(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_xpack_role_mappings.test", "names.#", "1"),
+					resource.TestCheckResourceAttr("data.elasticsearch_xpack_role_mappings.test", "names.0", randomName),
+					resource.TestCheckResourceAttr("data.elasticsearch_xpack_role_mappings.test", "role_mappings.0.name", randomName),
+					resource.TestCheckResourceAttr("data.elasticsearch_xpack_role_mappings.test", "role_mappings.0.enabled", "true"),
+					resource.TestCheckResourceAttr("data.elasticsearch_xpack_role_mappings.test", "role_mappings.0.roles.#", "2"),
+				),
+			},
+		},
+	})
+}
+
+func testAccElasticsearchDataSourceXpackRoleMappings(resourceName string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_xpack_role_mapping" "test" {
+  role_mapping_name = "%s"
+  roles = [
+    "terraform-test-role-mappings",
+    "user",
+  ]
+  rules = jsonencode({
+    field = {
+      username = "esadmin"
+    }
+  })
+  enabled = true
+}
+
+data "elasticsearch_xpack_role_mappings" "test" {
+  role             = "terraform-test-role-mappings"
+  exclude_reserved = true
+
+  depends_on = [elasticsearch_xpack_role_mapping.test]
+}
+`, resourceName)
+}
@@ -0,0 +1,204 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// the history indices are named .watcher-history-<template version>-<date>,
+// or are the backing indices of the .watcher-history-<template version> data
+// stream from ElasticSearch 7.9, and are hidden from ElasticSearch 7.7
+const watchHistoryIndexPattern = ".watcher-history-*"
+
+func dataSourceElasticsearchXpackWatchHistory() *schema.Resource {
+	return &schema.Resource{
+		Description: "`elasticsearch_xpack_watch_history` returns the recent executions of a watch from the watch history, e.g. to debug a watch whose condition or actions fail.",
+		Read:        dataSourceElasticsearchXpackWatchHistoryRead,
+		Schema: map[string]*schema.Schema{
+			"watch_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The ID of the watch.",
+			},
+			"size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntBetween(1, 1000),
+				Description:  "The maximum number of executions to return, the most recent first. Defaults to `10`.",
+			},
+			"from": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the executions from this date, as a date or a date math expression, e.g. `now-1d`.",
+			},
+			"to": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only return the executions until this date, as a date or a date math expression.",
+			},
+			"executions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The executions of the watch, the most recent first.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the watch record.",
+						},
+						"state": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The state of the execution, e.g. `executed`, `execution_not_needed` or `failed`.",
+						},
+						"execution_time": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The date of the execution.",
+						},
+						"condition_met": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the condition of the watch was met.",
+						},
+						"actions": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The JSON results of the actions of the execution.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceElasticsearchXpackWatchHistoryRead(d *schema.ResourceData, meta interface{}) error {
+	watchID := d.Get("watch_id").(string)
+
+	filters := []interface{}{
+		map[string]interface{}{
+			"term": map[string]interface{}{"watch_id": watchID},
+		},
+	}
+	executionTime := make(map[string]interface{})
+	if from, ok := d.GetOk("from"); ok {
+		executionTime["gte"] = from
+	}
+	if to, ok := d.GetOk("to"); ok {
+		executionTime["lte"] = to
+	}
+	if len(executionTime) > 0 {
+		filters = append(filters, map[string]interface{}{
+			"range": map[string]interface{}{"result.execution_time": executionTime},
+		})
+	}
+	query := map[string]interface{}{
+		"size": d.Get("size").(int),
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{"filter": filters},
+		},
+		"sort": []interface{}{
+			map[string]interface{}{"result.execution_time": "desc"},
+		},
+	}
+
+	body, err := xpackSearchWatchHistory(query, meta)
+	if err != nil {
+		return fmt.Errorf("error searching the history of watch %s: %+v", watchID, err)
+	}
+
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				ID     string `json:"_id"`
+				Source struct {
+					State  string `json:"state"`
+					Result struct {
+						ExecutionTime string `json:"execution_time"`
+						Condition     struct {
+							Met bool `json:"met"`
+						} `json:"condition"`
+						Actions json.RawMessage `json:"actions"`
+					} `json:"result"`
+				} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling watch history body: %+v: %+v", err, body)
+	}
+
+	executions := make([]map[string]interface{}, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		actions := "[]"
+		if len(hit.Source.Result.Actions) > 0 {
+			actions = string(hit.Source.Result.Actions)
+		}
+		executions = append(executions, map[string]interface{}{
+			"id":             hit.ID,
+			"state":          hit.Source.State,
+			"execution_time": hit.Source.Result.ExecutionTime,
+			"condition_met":  hit.Source.Result.Condition.Met,
+			"actions":        actions,
+		})
+	}
+
+	d.SetId(watchID)
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("executions", executions)
+	return ds.err
+}
+
+func xpackSearchWatchHistory(query map[string]interface{}, meta interface{}) (json.RawMessage, error) {
+	path := "/" + watchHistoryIndexPattern + "/_search"
+	// match the hidden history indices, there are none before the first
+	// execution of a watch
+	params := url.Values{
+		"expand_wildcards":   []string{"all"},
+		"ignore_unavailable": []string{"true"},
+		"allow_no_indices":   []string{"true"},
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		res, err := client.PerformRequest(context.Background(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+			Params: params,
+			Body:   query,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Body, nil
+	case *elastic6.Client:
+		res, err := client.PerformRequest(context.Background(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+			Params: params,
+			Body:   query,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return res.Body, nil
+	default:
+		return nil, errors.New("Elasticsearch version not supported")
+	}
+}
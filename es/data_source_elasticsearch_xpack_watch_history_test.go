@@ -0,0 +1,34 @@
+package es
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccElasticsearchDataSourceXpackWatchHistory_basic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testCheckElasticsearchWatchDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataSourceXpackWatchHistory,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.elasticsearch_xpack_watch_history.test", "id", "my_watch"),
+					resource.TestCheckResourceAttrSet("data.elasticsearch_xpack_watch_history.test", "executions.#"),
+				),
+			},
+		},
+	})
+}
+
+var testAccElasticsearchDataSourceXpackWatchHistory = testAccElasticsearchWatch + `
+data "elasticsearch_xpack_watch_history" "test" {
+  watch_id = elasticsearch_xpack_watch.test_watch.id
+  size     = 5
+  from     = "now-1d"
+}
+`
@@ -3,6 +3,7 @@ package es
 import (
 	"encoding/json"
 	"reflect"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -122,6 +123,68 @@ func suppressEquivalentJson(k, old, new string, d *schema.ResourceData) bool {
 	return reflect.DeepEqual(oldObj, newObj)
 }
 
+// diffSuppressIndexSimilarity compares similarities, which are returned as
+// strings by the index settings API
+func diffSuppressIndexSimilarity(k, old, new string, d *schema.ResourceData) bool {
+	var oo, no map[string]interface{}
+	if err := json.Unmarshal([]byte(old), &oo); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &no); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedIndexSimilarity(oo), normalizedIndexSimilarity(no))
+}
+
+// diffSuppressIndexRefreshInterval compares refresh intervals, an unset one
+// is the default interval, which is not returned by the index settings API
+func diffSuppressIndexRefreshInterval(k, old, new string, d *schema.ResourceData) bool {
+	if old == "" {
+		old = indexDefaultRefreshInterval
+	}
+	if new == "" {
+		new = indexDefaultRefreshInterval
+	}
+	return old == new
+}
+
+func suppressEquivalentDuration(k, old, new string, d *schema.ResourceData) bool {
+	oldDuration, err := time.ParseDuration(old)
+	if err != nil {
+		return false
+	}
+	newDuration, err := time.ParseDuration(new)
+	if err != nil {
+		return false
+	}
+	return oldDuration == newDuration
+}
+
+func suppressEquivalentKibanaDuration(k, old, new string, d *schema.ResourceData) bool {
+	oldDuration, ok := parseKibanaDuration(old)
+	if !ok {
+		return false
+	}
+	newDuration, ok := parseKibanaDuration(new)
+	if !ok {
+		return false
+	}
+	return oldDuration == newDuration
+}
+
+func suppressEquivalentRFC3339(k, old, new string, d *schema.ResourceData) bool {
+	oldTime, err := time.Parse(time.RFC3339, old)
+	if err != nil {
+		return false
+	}
+	newTime, err := time.Parse(time.RFC3339, new)
+	if err != nil {
+		return false
+	}
+	return oldTime.Equal(newTime)
+}
+
 func diffSuppressIndexLifecyclePolicy(k, old, new string, d *schema.ResourceData) bool {
 	var oo, no interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
@@ -163,7 +226,7 @@ func diffSuppressSnapshotLifecyclePolicy(k, old, new string, d *schema.ResourceD
 }
 
 func diffSuppressIngestPipeline(k, old, new string, d *schema.ResourceData) bool {
-	var oo, no interface{}
+	var oo, no map[string]interface{}
 	if err := json.Unmarshal([]byte(old), &oo); err != nil {
 		return false
 	}
@@ -171,6 +234,11 @@ func diffSuppressIngestPipeline(k, old, new string, d *schema.ResourceData) bool
 		return false
 	}
 
+	// the version is managed by the version attribute when not in the body
+	if _, ok := no["version"]; !ok {
+		delete(oo, "version")
+	}
+
 	return reflect.DeepEqual(oo, no)
 }
 
@@ -1,12 +1,29 @@
 package es
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/rand"
 	"net/http"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
 )
 
+// the tokens of the token command are refreshed this long before they expire
+const tokenCommandExpiryMargin = 30 * time.Second
+
 type withHeader struct {
 	http.Header
 	hostOverride string
+	tokenCommand *tokenCommand
 	rt           http.RoundTripper
 }
 
@@ -18,6 +35,14 @@ func WithHeader(rt http.RoundTripper) withHeader {
 	return withHeader{Header: make(http.Header), rt: rt}
 }
 
+// setAPIKey sets the Authorization header of an Elasticsearch API key, the
+// base64 encoding of `id:api_key`
+func (h withHeader) setAPIKey(apiKey string) {
+	if apiKey != "" {
+		h.Set("Authorization", fmt.Sprintf("ApiKey %s", apiKey))
+	}
+}
+
 func (h withHeader) RoundTrip(req *http.Request) (*http.Response, error) {
 	for k, v := range h.Header {
 		req.Header[k] = v
@@ -25,6 +50,197 @@ func (h withHeader) RoundTrip(req *http.Request) (*http.Response, error) {
 	if h.hostOverride != "" {
 		req.Host = h.hostOverride
 	}
+	if h.tokenCommand != nil {
+		return h.tokenCommand.roundTrip(h.rt, req)
+	}
 
 	return h.rt.RoundTrip(req)
 }
+
+// tokenCommand runs an external command which prints a bearer token, e.g. a
+// short-lived OIDC token. The token is cached until it expires, when the
+// command prints it as JSON with `expires_in`, or until a request fails with
+// a 401 response. It is shared by all the clients of the provider.
+type tokenCommand struct {
+	command string
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+func newTokenCommand(command string) *tokenCommand {
+	if command == "" {
+		return nil
+	}
+	return &tokenCommand{command: command}
+}
+
+// roundTrip sends the request with the current token, and once more with a
+// fresh token when the current one is rejected
+func (c *tokenCommand) roundTrip(rt http.RoundTripper, req *http.Request) (*http.Response, error) {
+	token, err := c.get("")
+	if err != nil {
+		return nil, err
+	}
+	// the body is consumed by the first round trip, the clients don't set
+	// GetBody so it is buffered to be sent again after a refresh
+	if req.Body != nil && req.GetBody == nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	resp, err := rt.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	log.Printf("[INFO] Refreshing the token of the token command after a 401 response to %s %s", req.Method, req.URL.Path)
+	token, err = c.get(token)
+	if err != nil {
+		log.Printf("[WARN] Failed to refresh the token of the token command: %+v", err)
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+	}
+	retry.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	return rt.RoundTrip(retry)
+}
+
+// get returns the cached token, it runs the command when there is no valid
+// token or when the token is the rejected one, unless another request already
+// refreshed it
+func (c *tokenCommand) get(rejected string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && c.token != rejected && (c.expiry.IsZero() || time.Now().Before(c.expiry)) {
+		return c.token, nil
+	}
+
+	token, expiresIn, err := runTokenCommand(c.command)
+	if err != nil {
+		return "", err
+	}
+	c.token = token
+	c.expiry = time.Time{}
+	if expiresIn > 0 {
+		if expiresIn > 2*tokenCommandExpiryMargin {
+			expiresIn -= tokenCommandExpiryMargin
+		}
+		c.expiry = time.Now().Add(expiresIn)
+	}
+	return c.token, nil
+}
+
+// runTokenCommand runs the command with the shell and returns the token it
+// printed, either as is or in the `access_token` or `token` key of a JSON
+// object, with the `expires_in` seconds
+func runTokenCommand(command string) (string, time.Duration, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(shell, flag, command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", 0, fmt.Errorf("error running the token command: %+v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	output := strings.TrimSpace(stdout.String())
+	var response struct {
+		AccessToken string `json:"access_token"`
+		Token       string `json:"token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal([]byte(output), &response); err == nil {
+		token := response.AccessToken
+		if token == "" {
+			token = response.Token
+		}
+		if token == "" {
+			return "", 0, fmt.Errorf("the JSON output of the token command has neither an access_token nor a token")
+		}
+		return token, time.Duration(response.ExpiresIn) * time.Second, nil
+	}
+
+	if output == "" {
+		return "", 0, fmt.Errorf("the token command printed no token")
+	}
+	return output, 0, nil
+}
+
+// statusRetrier retries the requests which failed with one of the retry
+// status codes of the client, e.g. 429 when the cluster is overloaded, with an
+// exponential backoff and jitter. Only the idempotent requests are retried, a
+// POST may have been applied before failing, e.g. on a gateway timeout.
+type statusRetrier struct {
+	maxRetries int
+	backoff    time.Duration
+	// the maximum total wait of the retries of a request
+	timeout time.Duration
+}
+
+func (r statusRetrier) Retry(ctx context.Context, retry int, req *http.Request, resp *http.Response, err error) (time.Duration, bool, error) {
+	// the connection errors are not retried, as without a retrier
+	if err != nil || resp == nil || retry > r.maxRetries {
+		return 0, false, nil
+	}
+	if !statusRetrierMethods[req.Method] {
+		return 0, false, nil
+	}
+	// the retries wait at most backoff * (2^retry - 1) in total
+	if r.backoff*time.Duration(1<<uint(retry)-1) > r.timeout {
+		return 0, false, nil
+	}
+
+	wait := r.wait(retry)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+		return 0, false, nil
+	}
+	log.Printf("[INFO] Retrying %s %s in %s after a %d response", req.Method, req.URL.Path, wait, resp.StatusCode)
+
+	// wait here rather than in the client, to stop on the cancellation of
+	// the context
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return 0, false, nil
+	case <-timer.C:
+		return 0, true, nil
+	}
+}
+
+// the idempotent methods, which can be retried safely
+var statusRetrierMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodHead:   true,
+	http.MethodPut:    true,
+	http.MethodDelete: true,
+}
+
+// wait returns the backoff of the retry, doubled on each retry, with a random
+// half
+func (r statusRetrier) wait(retry int) time.Duration {
+	backoff := r.backoff << uint(retry-1)
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
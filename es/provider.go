@@ -4,12 +4,15 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -19,8 +22,10 @@ import (
 	awssigv4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	awssts "github.com/aws/aws-sdk-go/service/sts"
 	"github.com/deoxxa/aws_signing_client"
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -28,6 +33,9 @@ import (
 
 var awsUrlRegexp = regexp.MustCompile(`([a-z0-9-]+).es.amazonaws.com$`)
 
+var elastic6Version, _ = version.NewVersion("6.0.0")
+var elastic7Version, _ = version.NewVersion("7.0.0")
+
 type ProviderConf struct {
 	rawUrl             string
 	insecure           bool
@@ -38,6 +46,8 @@ type ProviderConf struct {
 	password           string
 	token              string
 	tokenName          string
+	tokenCommand       *tokenCommand
+	apiKey             string
 	parsedUrl          *url.URL
 	signAWSRequests    bool
 	esVersion          string
@@ -51,6 +61,18 @@ type ProviderConf struct {
 	keyPemPath         string
 	kibanaUrl          string
 	hostOverride       string
+	retryOnStatus      []int
+	maxRetries         int
+	retryBackoff       time.Duration
+	retryTimeout       time.Duration
+
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	idleConnTimeout     time.Duration
+	// the transport shared by the clients built from the configuration, see
+	// httpTransport
+	transportOnce sync.Once
+	transport     *http.Transport
 }
 
 func Provider() *schema.Provider {
@@ -72,7 +94,7 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_SNIFF", true),
-				Description: "Set the node sniffing option for the elastic client. Client won't work with sniffing if nodes are not routable.",
+				Description: "Set the node sniffing option for the elastic client. Client won't work with sniffing if nodes are not routable, disable it when the cluster is reached through a load balancer or a proxy.",
 			},
 			"healthcheck": {
 				Type:        schema.TypeBool,
@@ -104,6 +126,35 @@ func Provider() *schema.Provider {
 				Default:     "ApiKey",
 				Description: "The type of token, usually ApiKey or Bearer",
 			},
+			"token_command": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				DefaultFunc: schema.EnvDefaultFunc("ELASTICSEARCH_TOKEN_COMMAND", nil),
+				Description: "A command run with the shell which prints a bearer token, e.g. a short-lived OIDC token, sent in a `Bearer` Authorization header. The command is run again when a request fails with a 401 response, or before the token expires when the command prints a JSON object with an `access_token` and its `expires_in` seconds. It is ignored when `token` is set.",
+			},
+			"api_key": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("ELASTICSEARCH_API_KEY", nil),
+				Description:  "An Elasticsearch API key, the base64 encoding of `id:api_key` as returned in the `encoded` field of the create API key API, sent in an `ApiKey` Authorization header. It conflicts with `username`, `password` and `token`.",
+				ValidateFunc: validateElasticsearchAPIKey,
+			},
+			"api_key_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				DefaultFunc:  schema.EnvDefaultFunc("ELASTICSEARCH_API_KEY_ID", nil),
+				Description:  "The ID of an Elasticsearch API key, used with `api_key_secret` instead of the encoded `api_key`.",
+				RequiredWith: []string{"api_key_secret"},
+			},
+			"api_key_secret": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				DefaultFunc:  schema.EnvDefaultFunc("ELASTICSEARCH_API_KEY_SECRET", nil),
+				Description:  "The secret of an Elasticsearch API key, used with `api_key_id` instead of the encoded `api_key`.",
+				RequiredWith: []string{"api_key_id"},
+			},
 			"aws_assume_role_arn": {
 				Type:        schema.TypeString,
 				Optional:    true,
@@ -170,13 +221,14 @@ func Provider() *schema.Provider {
 				Type:        schema.TypeBool,
 				Optional:    true,
 				Default:     true,
-				Description: "Enable signing of AWS elasticsearch requests. The `url` must refer to AWS ES domain (`*.<region>.es.amazonaws.com`), or `aws_region` must be specified explicitly.",
+				Description: "Enable signing of AWS elasticsearch requests, with the `es` service of both Elasticsearch and OpenSearch domains. The `url` must refer to AWS ES domain (`*.<region>.es.amazonaws.com`), or `aws_region` must be specified explicitly.",
 			},
 			"elasticsearch_version": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Default:     "",
-				Description: "ElasticSearch Version",
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "",
+				Description:  "ElasticSearch Version, e.g. `7.10.2`. When set, the provider skips pinging the cluster to determine its version and uses this one to select the client and available features instead. Useful when `/` is not reachable.",
+				ValidateFunc: validateElasticsearchVersion,
 			},
 			"host_override": {
 				Type:        schema.TypeString,
@@ -184,17 +236,79 @@ func Provider() *schema.Provider {
 				Default:     "",
 				Description: "If provided, sets the 'Host' header of requests and the 'ServerName' for certificate validation to this value. See the documentation on connecting to Elasticsearch via an SSH tunnel.",
 			},
+			"retry_on_status": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeInt},
+				Description: "The HTTP status codes of the responses whose requests are retried, e.g. when the cluster is overloaded. Defaults to `[429, 503]`. Only the GET, HEAD, PUT and DELETE requests are retried. The retries need ElasticSearch >= 7.0, the ElasticSearch 6 client doesn't retry on status codes.",
+			},
+			"max_retries": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      3,
+				ValidateFunc: validation.IntBetween(0, 10),
+				Description:  "The maximum number of retries of a request which failed with one of the `retry_on_status` status codes, `0` disables the retries.",
+			},
+			"retry_backoff": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "500ms",
+				ValidateFunc: validatePositiveDuration,
+				Description:  "The wait before the first retry of a request, e.g. `500ms`. It is doubled on each retry, with a random jitter.",
+			},
+			"retry_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "1m",
+				ValidateFunc: validatePositiveDuration,
+				Description:  "The maximum total wait between the retries of a request, e.g. `1m`. The request fails when the next retry would exceed it.",
+			},
+			"max_idle_conns": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of idle connections kept open to all the hosts, shared by all the requests of the provider.",
+			},
+			"max_idle_conns_per_host": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      10,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "The maximum number of idle connections kept open to each host. Raise it when many resources are managed concurrently, e.g. with a high `-parallelism`.",
+			},
+			"idle_conn_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "90s",
+				ValidateFunc: validatePositiveDuration,
+				Description:  "How long an idle connection is kept open before it is closed, e.g. `90s`.",
+			},
 		},
 
 		ResourcesMap: map[string]*schema.Resource{
+			"elasticsearch_api_key":                         resourceElasticsearchApiKey(),
+			"elasticsearch_autoscaling_policy":              resourceElasticsearchAutoscalingPolicy(),
+			"elasticsearch_cluster_settings":                resourceElasticsearchClusterSettings(),
+			"elasticsearch_enrich_policy":                   resourceElasticsearchEnrichPolicy(),
 			"elasticsearch_index":                           resourceElasticsearchIndex(),
+			"elasticsearch_index_alias":                     resourceElasticsearchIndexAlias(),
+			"elasticsearch_index_lifecycle_move_to_step":    resourceElasticsearchIndexLifecycleMoveToStep(),
+			"elasticsearch_index_reload_search_analyzers":   resourceElasticsearchIndexReloadSearchAnalyzers(),
 			"elasticsearch_index_template":                  resourceElasticsearchIndexTemplate(),
 			"elasticsearch_composable_index_template":       resourceElasticsearchComposableIndexTemplate(),
 			"elasticsearch_component_template":              resourceElasticsearchComponentTemplate(),
+			"elasticsearch_data_stream":                     resourceElasticsearchDataStream(),
 			"elasticsearch_ingest_pipeline":                 resourceElasticsearchIngestPipeline(),
 			"elasticsearch_kibana_alert":                    resourceElasticsearchKibanaAlert(),
+			"elasticsearch_kibana_alert_bulk_enable":        resourceElasticsearchKibanaAlertBulkEnable(),
+			"elasticsearch_kibana_connector":                resourceElasticsearchKibanaConnector(),
+			"elasticsearch_kibana_import":                   resourceElasticsearchKibanaImport(),
 			"elasticsearch_kibana_object":                   resourceElasticsearchKibanaObject(),
+			"elasticsearch_script":                          resourceElasticsearchScript(),
+			"elasticsearch_search_template":                 resourceElasticsearchSearchTemplate(),
 			"elasticsearch_snapshot_repository":             resourceElasticsearchSnapshotRepository(),
+			"elasticsearch_transform":                       resourceElasticsearchTransform(),
 			"elasticsearch_opendistro_destination":          resourceElasticsearchOpenDistroDestination(),
 			"elasticsearch_opendistro_ism_policy":           resourceElasticsearchOpenDistroISMPolicy(),
 			"elasticsearch_opendistro_ism_policy_mapping":   resourceElasticsearchOpenDistroISMPolicyMapping(),
@@ -213,8 +327,16 @@ func Provider() *schema.Provider {
 		},
 
 		DataSourcesMap: map[string]*schema.Resource{
-			"elasticsearch_host":                   dataSourceElasticsearchHost(),
-			"elasticsearch_opendistro_destination": dataSourceElasticsearchOpenDistroDestination(),
+			"elasticsearch_host":                        dataSourceElasticsearchHost(),
+			"elasticsearch_index_stats":                 dataSourceElasticsearchIndexStats(),
+			"elasticsearch_kibana_alert":                dataSourceElasticsearchKibanaAlert(),
+			"elasticsearch_kibana_alert_status":         dataSourceElasticsearchKibanaAlertStatus(),
+			"elasticsearch_kibana_connector_types":      dataSourceElasticsearchKibanaConnectorTypes(),
+			"elasticsearch_kibana_export":               dataSourceElasticsearchKibanaExport(),
+			"elasticsearch_opendistro_destination":      dataSourceElasticsearchOpenDistroDestination(),
+			"elasticsearch_snapshot_repository_analyze": dataSourceElasticsearchSnapshotRepositoryAnalyze(),
+			"elasticsearch_xpack_role_mappings":         dataSourceElasticsearchXpackRoleMappings(),
+			"elasticsearch_xpack_watch_history":         dataSourceElasticsearchXpackWatchHistory(),
 		},
 
 		ConfigureContextFunc: providerConfigure,
@@ -228,6 +350,38 @@ func providerConfigure(c context.Context, d *schema.ResourceData) (interface{},
 		return nil, diag.FromErr(err)
 	}
 
+	username := d.Get("username").(string)
+	password := d.Get("password").(string)
+
+	// the API key can be given encoded or as its ID and secret
+	apiKey := d.Get("api_key").(string)
+	if id, secret := d.Get("api_key_id").(string), d.Get("api_key_secret").(string); id != "" || secret != "" {
+		if apiKey != "" {
+			return nil, diag.Errorf("api_key conflicts with api_key_id and api_key_secret, set either the encoded API key or its ID and secret")
+		}
+		apiKey = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", id, secret)))
+	}
+	if apiKey != "" && (username != "" || password != "" || d.Get("token").(string) != "" || d.Get("token_command").(string) != "") {
+		return nil, diag.Errorf("the API key conflicts with username, password, token and token_command, set only one of them")
+	}
+
+	// the static token wins over the token command
+	token := d.Get("token").(string)
+	tokenCommand := d.Get("token_command").(string)
+	if token != "" && tokenCommand != "" {
+		log.Printf("[WARN] Both token and token_command are set, token_command is ignored")
+		tokenCommand = ""
+	}
+
+	retryOnStatus := []int{http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	if v, ok := d.GetOk("retry_on_status"); ok {
+		retryOnStatus = expandIntList(v.(*schema.Set).List())
+	}
+	// the durations are validated
+	retryBackoff, _ := time.ParseDuration(d.Get("retry_backoff").(string))
+	retryTimeout, _ := time.ParseDuration(d.Get("retry_timeout").(string))
+	idleConnTimeout, _ := time.ParseDuration(d.Get("idle_conn_timeout").(string))
+
 	return &ProviderConf{
 		rawUrl:          rawUrl,
 		kibanaUrl:       d.Get("kibana_url").(string),
@@ -235,10 +389,12 @@ func providerConfigure(c context.Context, d *schema.ResourceData) (interface{},
 		sniffing:        d.Get("sniff").(bool),
 		healthchecking:  d.Get("healthcheck").(bool),
 		cacertFile:      d.Get("cacert_file").(string),
-		username:        d.Get("username").(string),
-		password:        d.Get("password").(string),
-		token:           d.Get("token").(string),
+		username:        username,
+		password:        password,
+		token:           token,
 		tokenName:       d.Get("token_name").(string),
+		tokenCommand:    newTokenCommand(tokenCommand),
+		apiKey:          apiKey,
 		parsedUrl:       parsedUrl,
 		signAWSRequests: d.Get("sign_aws_requests").(bool),
 		esVersion:       d.Get("elasticsearch_version").(string),
@@ -252,9 +408,76 @@ func providerConfigure(c context.Context, d *schema.ResourceData) (interface{},
 		certPemPath:        d.Get("client_cert_path").(string),
 		keyPemPath:         d.Get("client_key_path").(string),
 		hostOverride:       d.Get("host_override").(string),
+		retryOnStatus:      retryOnStatus,
+		maxRetries:         d.Get("max_retries").(int),
+		retryBackoff:       retryBackoff,
+		retryTimeout:       retryTimeout,
+
+		maxIdleConns:        d.Get("max_idle_conns").(int),
+		maxIdleConnsPerHost: d.Get("max_idle_conns_per_host").(int),
+		idleConnTimeout:     idleConnTimeout,
 	}, nil
 }
 
+// httpTransport returns the transport of the HTTP clients, it is built once so
+// that the clients of all the resources reuse the same pool of connections.
+// The clients only wrap it with their headers and authentication
+func (conf *ProviderConf) httpTransport() *http.Transport {
+	conf.transportOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = providerTLSConfig(conf)
+		if conf.maxIdleConns > 0 {
+			transport.MaxIdleConns = conf.maxIdleConns
+		}
+		if conf.maxIdleConnsPerHost > 0 {
+			transport.MaxIdleConnsPerHost = conf.maxIdleConnsPerHost
+		}
+		if conf.idleConnTimeout > 0 {
+			transport.IdleConnTimeout = conf.idleConnTimeout
+		}
+		conf.transport = transport
+	})
+	return conf.transport
+}
+
+func providerTLSConfig(conf *ProviderConf) *tls.Config {
+	// Configure TLS/SSL
+	tlsConfig := &tls.Config{}
+	if conf.certPemPath != "" && conf.keyPemPath != "" {
+		certPem, _, err := readPathOrContent(conf.certPemPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		keyPem, _, err := readPathOrContent(conf.keyPemPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
+		if err != nil {
+			log.Fatal(err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	// If a cacertFile has been specified, use that for cert validation
+	if conf.cacertFile != "" {
+		caCert, _, _ := readPathOrContent(conf.cacertFile)
+
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM([]byte(caCert))
+		tlsConfig.RootCAs = caCertPool
+	}
+
+	// If configured as insecure, turn off SSL verification
+	if conf.insecure {
+		tlsConfig.InsecureSkipVerify = true
+	} else if conf.hostOverride != "" {
+		tlsConfig.ServerName = conf.hostOverride
+	}
+
+	return tlsConfig
+}
+
 func getClient(conf *ProviderConf) (interface{}, error) {
 	opts := []elastic7.ClientOptionFunc{
 		elastic7.SetURL(conf.rawUrl),
@@ -281,9 +504,12 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 		opts = append(opts, elastic7.SetHttpClient(tlsHttpClient(conf, map[string]string{})), elastic7.SetSniff(false))
 	} else if conf.token != "" {
 		opts = append(opts, elastic7.SetHttpClient(tokenHttpClient(conf, map[string]string{})), elastic7.SetSniff(false))
+	} else if conf.tokenCommand != nil {
+		opts = append(opts, elastic7.SetHttpClient(tokenCommandHttpClient(conf, map[string]string{})), elastic7.SetSniff(false))
 	} else {
 		opts = append(opts, elastic7.SetHttpClient(defaultHttpClient(conf, map[string]string{})))
 	}
+	opts = append(opts, elastic7RetryOptions(conf)...)
 
 	var relevantClient interface{}
 	client, err := elastic7.NewClient(opts...)
@@ -302,7 +528,12 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 		conf.esVersion = info.Version.Number
 	}
 
-	if conf.esVersion < "7.0.0" && conf.esVersion >= "6.0.0" {
+	esVersion, err := version.NewVersion(conf.esVersion)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse ElasticSearch version %q: %+v", conf.esVersion, err)
+	}
+
+	if esVersion.LessThan(elastic7Version) && esVersion.GreaterThanOrEqual(elastic6Version) {
 		log.Printf("[INFO] Using ES 6")
 		opts := []elastic6.ClientOptionFunc{
 			elastic6.SetURL(conf.rawUrl),
@@ -329,6 +560,8 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 			opts = append(opts, elastic6.SetHttpClient(tlsHttpClient(conf, map[string]string{})), elastic6.SetSniff(false))
 		} else if conf.token != "" {
 			opts = append(opts, elastic6.SetHttpClient(tokenHttpClient(conf, map[string]string{})), elastic6.SetSniff(false))
+		} else if conf.tokenCommand != nil {
+			opts = append(opts, elastic6.SetHttpClient(tokenCommandHttpClient(conf, map[string]string{})), elastic6.SetSniff(false))
 		} else {
 			opts = append(opts, elastic6.SetHttpClient(defaultHttpClient(conf, map[string]string{})))
 		}
@@ -337,13 +570,72 @@ func getClient(conf *ProviderConf) (interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
-	} else if conf.esVersion < "6.0.0" {
+	} else if esVersion.LessThan(elastic6Version) {
 		return nil, errors.New("ElasticSearch older than 6.0.0 is not supported.")
 	}
 
 	return relevantClient, nil
 }
 
+// elastic7RetryOptions retries the requests which failed with the retry
+// status codes, the elastic6 client has no retries by status code
+func elastic7RetryOptions(conf *ProviderConf) []elastic7.ClientOptionFunc {
+	if conf.maxRetries <= 0 || len(conf.retryOnStatus) == 0 {
+		return nil
+	}
+
+	retrier := statusRetrier{
+		maxRetries: conf.maxRetries,
+		backoff:    conf.retryBackoff,
+		timeout:    conf.retryTimeout,
+	}
+	return []elastic7.ClientOptionFunc{
+		elastic7.SetRetrier(retrier),
+		elastic7.SetRetryStatusCodes(conf.retryOnStatus...),
+	}
+}
+
+func validatePositiveDuration(v interface{}, k string) (ws []string, errors []error) {
+	duration, err := time.ParseDuration(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a duration, e.g. `500ms`: %+v", k, err))
+	} else if duration <= 0 {
+		errors = append(errors, fmt.Errorf("%q must be a positive duration, got %s", k, v.(string)))
+	}
+	return
+}
+
+func validateElasticsearchVersion(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+
+	if _, err := version.NewVersion(value); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a semantic version, e.g. 7.10.2: %+v", k, err))
+	}
+	return
+}
+
+// validateElasticsearchAPIKey checks that the API key is the base64 encoding
+// of `id:api_key`, the encoded field of the create API key API response
+func validateElasticsearchAPIKey(v interface{}, k string) (ws []string, errors []error) {
+	value := v.(string)
+	if value == "" {
+		return
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q must be base64 encoded: %+v", k, err))
+		return
+	}
+	if parts := strings.SplitN(string(decoded), ":", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		errors = append(errors, fmt.Errorf("%q must be the base64 encoding of `id:api_key`, e.g. the `encoded` field of the create API key API", k))
+	}
+	return
+}
+
 func getKibanaClient(conf *ProviderConf) (interface{}, error) {
 	// use either the provided version of elasticsearch or the version of
 	// elasticsearch determined by pinging the cluster. Base AWS or other auth
@@ -383,9 +675,12 @@ func getKibanaClient(conf *ProviderConf) (interface{}, error) {
 			opts = append(opts, elastic7.SetHttpClient(tlsHttpClient(conf, headers)))
 		} else if conf.token != "" {
 			opts = append(opts, elastic7.SetHttpClient(tokenHttpClient(conf, headers)), elastic7.SetSniff(false))
+		} else if conf.tokenCommand != nil {
+			opts = append(opts, elastic7.SetHttpClient(tokenCommandHttpClient(conf, headers)), elastic7.SetSniff(false))
 		} else {
 			opts = append(opts, elastic7.SetHttpClient(defaultHttpClient(conf, headers)))
 		}
+		opts = append(opts, elastic7RetryOptions(conf)...)
 
 		return elastic7.NewClient(opts...)
 	case *elastic6.Client:
@@ -464,7 +759,7 @@ func awsSession(region string, conf *ProviderConf) *awssession.Session {
 func awsHttpClient(region string, conf *ProviderConf, headers map[string]string) *http.Client {
 	session := awsSession(region, conf)
 	signer := awssigv4.NewSigner(session.Config.Credentials)
-	client, err := aws_signing_client.New(signer, session.Config.HTTPClient, "es", region)
+	client, err := aws_signing_client.New(signer, &http.Client{Transport: conf.httpTransport()}, "es", region)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -480,87 +775,47 @@ func awsHttpClient(region string, conf *ProviderConf, headers map[string]string)
 }
 
 func tokenHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
-	client := http.DefaultClient
-
-	rt := WithHeader(client.Transport)
+	rt := WithHeader(conf.httpTransport())
 	rt.hostOverride = conf.hostOverride
 	rt.Set("Authorization", fmt.Sprintf("%s %s", conf.tokenName, conf.token))
 	for k, v := range headers {
 		rt.Set(k, v)
 	}
-	client.Transport = rt
+	rt.setAPIKey(conf.apiKey)
 
-	if conf.insecure {
-		client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify = true
-	} else if conf.hostOverride != "" {
-		client.Transport.(*http.Transport).TLSClientConfig.ServerName = conf.hostOverride
-	}
-
-	return client
+	return &http.Client{Transport: rt}
 }
 
-func tlsHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
-	// Configure TLS/SSL
-	tlsConfig := &tls.Config{}
-	if conf.certPemPath != "" && conf.keyPemPath != "" {
-		certPem, _, err := readPathOrContent(conf.certPemPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		keyPem, _, err := readPathOrContent(conf.keyPemPath)
-		if err != nil {
-			log.Fatal(err)
-		}
-		cert, err := tls.X509KeyPair([]byte(certPem), []byte(keyPem))
-		if err != nil {
-			log.Fatal(err)
-		}
-		tlsConfig.Certificates = []tls.Certificate{cert}
-	}
-
-	// If a cacertFile has been specified, use that for cert validation
-	if conf.cacertFile != "" {
-		caCert, _, _ := readPathOrContent(conf.cacertFile)
-
-		caCertPool := x509.NewCertPool()
-		caCertPool.AppendCertsFromPEM([]byte(caCert))
-		tlsConfig.RootCAs = caCertPool
-	}
-
-	// If configured as insecure, turn off SSL verification
-	if conf.insecure {
-		tlsConfig.InsecureSkipVerify = true
-	} else if conf.hostOverride != "" {
-		tlsConfig.ServerName = conf.hostOverride
+func tokenCommandHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
+	rt := WithHeader(conf.httpTransport())
+	rt.hostOverride = conf.hostOverride
+	for k, v := range headers {
+		rt.Set(k, v)
 	}
+	rt.tokenCommand = conf.tokenCommand
 
-	transport := &http.Transport{TLSClientConfig: tlsConfig}
+	return &http.Client{Transport: rt}
+}
 
-	rt := WithHeader(transport)
+func tlsHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
+	rt := WithHeader(conf.httpTransport())
 	rt.hostOverride = conf.hostOverride
 	for k, v := range headers {
 		rt.Set(k, v)
 	}
+	rt.setAPIKey(conf.apiKey)
+	rt.tokenCommand = conf.tokenCommand
 
-	client := &http.Client{Transport: rt}
-
-	return client
+	return &http.Client{Transport: rt}
 }
 
 func defaultHttpClient(conf *ProviderConf, headers map[string]string) *http.Client {
-	// Gets the default HTTP client
-	client := http.DefaultClient
-	rt := WithHeader(client.Transport)
+	rt := WithHeader(conf.httpTransport())
 	for k, v := range headers {
 		rt.Set(k, v)
 	}
+	rt.setAPIKey(conf.apiKey)
 	rt.hostOverride = conf.hostOverride
-	client.Transport = rt
 
-	if conf.insecure {
-		client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify = true
-	} else if conf.hostOverride != "" {
-		client.Transport.(*http.Transport).TLSClientConfig.ServerName = conf.hostOverride
-	}
-	return client
+	return &http.Client{Transport: rt}
 }
@@ -2,12 +2,25 @@ package es
 
 import (
 	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
 var testAccProviders map[string]*schema.Provider
@@ -100,6 +113,69 @@ func testAccPreCheck(t *testing.T) {
 	}
 }
 
+func TestValidateElasticsearchAPIKey(t *testing.T) {
+	for _, apiKey := range []string{"", "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw=="} {
+		if _, errs := validateElasticsearchAPIKey(apiKey, "api_key"); len(errs) > 0 {
+			t.Errorf("expected %q to be valid, got %v", apiKey, errs)
+		}
+	}
+	// not base64, only the ID, no secret
+	for _, apiKey := range []string{"not base64!", "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g=", "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6"} {
+		if _, errs := validateElasticsearchAPIKey(apiKey, "api_key"); len(errs) == 0 {
+			t.Errorf("expected %q to be invalid", apiKey)
+		}
+	}
+}
+
+func TestProviderConfigureAPIKey(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":            "http://127.0.0.1:9200",
+		"api_key_id":     "VuaCfGcBCdbkQm-e5aOx",
+		"api_key_secret": "ui2lp2axTNmsyakw9tvNnw",
+	})
+	conf, diags := providerConfigure(context.Background(), d)
+	if diags.HasError() {
+		t.Fatalf("unexpected error: %v", diags)
+	}
+	if apiKey := conf.(*ProviderConf).apiKey; apiKey != "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==" {
+		t.Errorf("expected the API key to be the encoded ID and secret, got %q", apiKey)
+	}
+
+	d = schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+		"url":      "http://127.0.0.1:9200",
+		"api_key":  "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==",
+		"username": "elastic",
+		"password": "elastic",
+	})
+	if _, diags := providerConfigure(context.Background(), d); !diags.HasError() {
+		t.Errorf("expected the API key to conflict with the basic auth")
+	}
+}
+
+func TestAPIKeyHttpClient(t *testing.T) {
+	var authorization string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	conf := &ProviderConf{
+		apiKey: "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==",
+	}
+	// the Kibana client sets its own headers too
+	client := tlsHttpClient(conf, map[string]string{"kbn-xsrf": "true"})
+
+	res, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+
+	if expected := "ApiKey " + conf.apiKey; authorization != expected {
+		t.Errorf("expected the Authorization header %q, got %q", expected, authorization)
+	}
+}
+
 // Given:
 // 1. AWS credentials are specified via environment variables
 // 2. aws access key and secret access key are specified via the provider configuration
@@ -129,6 +205,41 @@ func TestAWSCredsManualKey(t *testing.T) {
 	}
 }
 
+// this tests that: the requests of the AWS client are SigV4 signed for the es
+// service, which is used by both Elasticsearch and OpenSearch domains
+func TestAWSHttpClientSignsRequests(t *testing.T) {
+	var authorization, date string
+	// the signing client always sends the requests over https
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		date = r.Header.Get("X-Amz-Date")
+	}))
+	defer ts.Close()
+
+	conf := &ProviderConf{
+		awsAccessKeyId:     "MANUAL_ACCESS_KEY",
+		awsSecretAccessKey: "MANUAL_SECRET_KEY",
+		insecure:           true,
+	}
+	client := awsHttpClient("eu-west-1", conf, map[string]string{})
+
+	res, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	res.Body.Close()
+
+	if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=MANUAL_ACCESS_KEY/") {
+		t.Errorf("request should have been signed with the static credentials, got Authorization %q", authorization)
+	}
+	if !strings.Contains(authorization, "/eu-west-1/es/aws4_request") {
+		t.Errorf("request should have been signed for the es service in eu-west-1, got Authorization %q", authorization)
+	}
+	if date == "" {
+		t.Errorf("request should have an X-Amz-Date header")
+	}
+}
+
 // Given:
 // 1. AWS credentials are specified via environment variables
 // 2. a named profile is specified via the provider config
@@ -230,6 +341,167 @@ func TestAWSCredsAssumeRole(t *testing.T) {
 	}
 }
 
+// Given:
+// 1. A cluster that rejects every request
+// 2. The elasticsearch_version is declared in the provider configuration
+//
+// This tests that: the version probe is skipped and the declared version is
+// used to select the client.
+func TestElasticsearchVersionOverride(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for esVersion, expected := range map[string]string{"7.10.2": "*elastic7.Client", "6.8.13": "*elastic6.Client"} {
+		conf := &ProviderConf{
+			rawUrl:    ts.URL,
+			parsedUrl: parsedUrl,
+			esVersion: esVersion,
+		}
+
+		client, err := getClient(conf)
+		if err != nil {
+			t.Fatalf("getClient returned an error: %+v", err)
+		}
+
+		var got string
+		switch client.(type) {
+		case *elastic7.Client:
+			got = "*elastic7.Client"
+		case *elastic6.Client:
+			got = "*elastic6.Client"
+		}
+		if got != expected {
+			t.Errorf("expected %s for version %s, got %T", expected, esVersion, client)
+		}
+	}
+}
+
+func TestValidateElasticsearchVersion(t *testing.T) {
+	if _, errs := validateElasticsearchVersion("7.10.2", "elasticsearch_version"); len(errs) > 0 {
+		t.Errorf("expected 7.10.2 to be valid, got %+v", errs)
+	}
+	if _, errs := validateElasticsearchVersion("", "elasticsearch_version"); len(errs) > 0 {
+		t.Errorf("expected an empty version to be valid, got %+v", errs)
+	}
+	if _, errs := validateElasticsearchVersion("seven", "elasticsearch_version"); len(errs) == 0 {
+		t.Errorf("expected seven to be invalid")
+	}
+}
+
+// Given:
+// 1. A cluster that answers 429 to the first two requests
+// 2. The retries are configured in the provider
+//
+// This tests that: the requests of both the Elasticsearch and the Kibana
+// clients are retried until they succeed, or fail once the retries run out.
+func TestRetryOnStatus(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		maxRetries int
+		getClient  func(*ProviderConf) (interface{}, error)
+		expected   int
+	}{
+		{maxRetries: 3, getClient: getClient, expected: 3},
+		{maxRetries: 3, getClient: getKibanaClient, expected: 3},
+		{maxRetries: 1, getClient: getClient, expected: 2},
+	} {
+		requests = 0
+		conf := &ProviderConf{
+			rawUrl:        ts.URL,
+			kibanaUrl:     ts.URL,
+			parsedUrl:     parsedUrl,
+			esVersion:     "7.10.2",
+			retryOnStatus: []int{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+			maxRetries:    test.maxRetries,
+			retryBackoff:  time.Millisecond,
+			retryTimeout:  time.Second,
+		}
+
+		client, err := test.getClient(conf)
+		if err != nil {
+			t.Fatalf("getClient returned an error: %+v", err)
+		}
+		_, err = client.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_cluster/health",
+		})
+		if test.maxRetries >= 2 && err != nil {
+			t.Errorf("expected the request to succeed after the retries, got %+v", err)
+		}
+		if test.maxRetries < 2 && !elastic7.IsStatusCode(err, http.StatusTooManyRequests) {
+			t.Errorf("expected the request to fail with a 429 once the retries run out, got %+v", err)
+		}
+		if requests != test.expected {
+			t.Errorf("expected %d requests with %d retries, got %d", test.expected, test.maxRetries, requests)
+		}
+	}
+}
+
+func TestStatusRetrierTimeout(t *testing.T) {
+	retrier := statusRetrier{maxRetries: 10, backoff: time.Millisecond, timeout: 3 * time.Millisecond}
+	req := httptest.NewRequest("GET", "/", nil)
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	// the first two retries wait at most 1ms and 2ms
+	for retry := 1; retry <= 2; retry++ {
+		if _, ok, _ := retrier.Retry(context.TODO(), retry, req, resp, nil); !ok {
+			t.Errorf("expected the retry %d to be within the timeout", retry)
+		}
+	}
+	if _, ok, _ := retrier.Retry(context.TODO(), 3, req, resp, nil); ok {
+		t.Error("expected the third retry to exceed the timeout")
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, ok, _ := retrier.Retry(ctx, 1, req, resp, nil); ok {
+		t.Error("expected no retry once the context is canceled")
+	}
+}
+
+func TestStatusRetrierMethods(t *testing.T) {
+	retrier := statusRetrier{maxRetries: 3, backoff: time.Millisecond, timeout: time.Second}
+	resp := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	for method, expected := range map[string]bool{
+		"GET":    true,
+		"HEAD":   true,
+		"PUT":    true,
+		"DELETE": true,
+		"POST":   false,
+		"PATCH":  false,
+	} {
+		req := httptest.NewRequest(method, "/", nil)
+		if _, ok, _ := retrier.Retry(context.TODO(), 1, req, resp, nil); ok != expected {
+			t.Errorf("expected the retry of a %s request to be %t, got %t", method, expected, ok)
+		}
+	}
+}
+
 func getCreds(t *testing.T, region string, config map[string]interface{}) credentials.Value {
 	awsAccessKey := ""
 	awsSecretKey := ""
@@ -259,3 +531,175 @@ func getCreds(t *testing.T, region string, config map[string]interface{}) creden
 	}
 	return creds
 }
+
+func TestTokenCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake token command is a shell script")
+	}
+
+	// the command prints a new token on each run, the server only accepts the
+	// second one
+	dir := t.TempDir()
+	script := filepath.Join(dir, "token.sh")
+	err := ioutil.WriteFile(script, []byte(`#!/bin/sh
+count=$(cat "$(dirname "$0")/count" 2>/dev/null || echo 0)
+count=$((count + 1))
+echo "$count" > "$(dirname "$0")/count"
+echo "{\"access_token\": \"token-$count\", \"expires_in\": 900}"
+`), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var authorizations []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &ProviderConf{
+		rawUrl:       ts.URL,
+		parsedUrl:    parsedUrl,
+		esVersion:    "7.10.2",
+		tokenCommand: newTokenCommand(script),
+	}
+
+	client, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("getClient returned an error: %+v", err)
+	}
+	for i := 0; i < 2; i++ {
+		_, err = client.(*elastic7.Client).PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "PUT",
+			Path:   "/_cluster/settings",
+			Body:   `{"persistent": {}}`,
+		})
+		if err != nil {
+			t.Fatalf("expected the request to succeed with the refreshed token, got %+v", err)
+		}
+	}
+
+	// the rejected token is refreshed once, the refreshed one is cached
+	expected := []string{"Bearer token-1", "Bearer token-2", "Bearer token-2"}
+	if !reflect.DeepEqual(authorizations, expected) {
+		t.Errorf("expected the authorizations %v, got %v", expected, authorizations)
+	}
+}
+
+func TestRunTokenCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake token commands are shell commands")
+	}
+
+	for command, expected := range map[string]struct {
+		token     string
+		expiresIn time.Duration
+	}{
+		`echo plain-token`:                                  {"plain-token", 0},
+		`echo '{"token": "json-token"}'`:                    {"json-token", 0},
+		`echo '{"access_token": "oidc", "expires_in": 60}'`: {"oidc", time.Minute},
+	} {
+		token, expiresIn, err := runTokenCommand(command)
+		if err != nil {
+			t.Errorf("unexpected error running %q: %+v", command, err)
+			continue
+		}
+		if token != expected.token || expiresIn != expected.expiresIn {
+			t.Errorf("expected the token %q expiring in %s from %q, got %q expiring in %s", expected.token, expected.expiresIn, command, token, expiresIn)
+		}
+	}
+
+	for _, command := range []string{`true`, `echo failed >&2; exit 1`, `echo '{"expires_in": 60}'`} {
+		if _, _, err := runTokenCommand(command); err == nil {
+			t.Errorf("expected an error running %q", command)
+		}
+	}
+}
+
+func TestProviderSniff(t *testing.T) {
+	var sniffed int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_nodes/http" {
+			sniffed++
+		}
+		// the nodes publish an address which isn't reachable, as behind a
+		// load balancer
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"nodes": {"node-1": {"name": "node-1", "http": {"publish_address": "10.0.0.1:9200"}}}}`)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, esVersion := range []string{"7.10.2", "6.8.13"} {
+		for _, sniffing := range []bool{false, true} {
+			sniffed = 0
+			conf := &ProviderConf{
+				rawUrl:    ts.URL,
+				parsedUrl: parsedUrl,
+				esVersion: esVersion,
+				sniffing:  sniffing,
+			}
+
+			_, err := getClient(conf)
+			if !sniffing && err != nil {
+				t.Fatalf("getClient returned an error for version %s: %+v", esVersion, err)
+			}
+			if sniffing != (sniffed > 0) {
+				t.Errorf("expected the nodes to be sniffed %t for version %s, got %d requests", sniffing, esVersion, sniffed)
+			}
+		}
+	}
+}
+
+func TestProviderSharedTransport(t *testing.T) {
+	conf := &ProviderConf{
+		hostOverride:        "elasticsearch.example.com",
+		maxIdleConns:        50,
+		maxIdleConnsPerHost: 20,
+		idleConnTimeout:     time.Minute,
+	}
+
+	transport := conf.httpTransport()
+	if transport.MaxIdleConns != 50 || transport.MaxIdleConnsPerHost != 20 || transport.IdleConnTimeout != time.Minute {
+		t.Errorf("expected the connection limits of the configuration, got %d, %d and %s", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, transport.IdleConnTimeout)
+	}
+	if transport.TLSClientConfig.ServerName != "elasticsearch.example.com" {
+		t.Errorf("expected the TLS server name to be the host override, got %q", transport.TLSClientConfig.ServerName)
+	}
+	if transport == http.DefaultTransport {
+		t.Errorf("expected the default transport not to be modified")
+	}
+
+	// the clients only wrap the transport with their headers
+	clients := map[string]*http.Client{
+		"default":       defaultHttpClient(conf, map[string]string{}),
+		"kibana":        defaultHttpClient(conf, map[string]string{"kbn-xsrf": "true"}),
+		"tls":           tlsHttpClient(conf, map[string]string{}),
+		"token":         tokenHttpClient(conf, map[string]string{}),
+		"token command": tokenCommandHttpClient(conf, map[string]string{}),
+	}
+	for name, client := range clients {
+		rt, ok := client.Transport.(withHeader)
+		if !ok {
+			t.Errorf("expected the %s client to wrap the transport with its headers, got %T", name, client.Transport)
+			continue
+		}
+		if rt.rt != transport {
+			t.Errorf("expected the %s client to reuse the shared transport", name)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package es
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var apiKeyInvalidateIdsMinimalVersion, _ = version.NewVersion("7.10.0")
+var apiKeyUpdateMinimalVersion, _ = version.NewVersion("8.4.0")
+
+func resourceElasticsearchApiKey() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Provides an Elasticsearch API key resource, to give scoped access to the cluster to other applications. The key is only returned on creation, so the resource can't be imported, and destroying the resource invalidates the key. The resource is removed from the state when the key is invalidated or expired. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api-create-api-key.html) for more details.",
+		Create:        resourceElasticsearchApiKeyCreate,
+		Read:          resourceElasticsearchApiKeyRead,
+		Update:        resourceElasticsearchApiKeyUpdate,
+		Delete:        resourceElasticsearchApiKeyDelete,
+		CustomizeDiff: resourceElasticsearchApiKeyForceNewOnRoleDescriptors,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the API key.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"role_descriptors": {
+				Type:             schema.TypeString,
+				Description:      "A JSON string of the role descriptors of the API key, by role name, they limit the privileges of the key to a subset of the privileges of the user who creates it. The key has the privileges of the user when it is empty. Updated in place from ElasticSearch >= 8.4, the key is replaced on the older versions.",
+				Optional:         true,
+				Default:          "{}",
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+			},
+			"expiration": {
+				Type:        schema.TypeString,
+				Description: "The lifetime of the API key, e.g. `30d`. The key doesn't expire when it is not set.",
+				ForceNew:    true,
+				Optional:    true,
+			},
+			"id": {
+				Type:        schema.TypeString,
+				Description: "The ID of the API key.",
+				Computed:    true,
+			},
+			"api_key": {
+				Type:        schema.TypeString,
+				Description: "The secret of the API key.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+			"encoded": {
+				Type:        schema.TypeString,
+				Description: "The base64 encoding of `id:api_key`, the value of the `ApiKey` Authorization header.",
+				Computed:    true,
+				Sensitive:   true,
+			},
+		},
+	}
+}
+
+// resourceElasticsearchApiKeyForceNewOnRoleDescriptors replaces the key when
+// its role descriptors change on the versions which can't update API keys
+func resourceElasticsearchApiKeyForceNewOnRoleDescriptors(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" || !d.HasChange("role_descriptors") || meta == nil {
+		return nil
+	}
+
+	elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(apiKeyUpdateMinimalVersion) {
+		return d.ForceNew("role_descriptors")
+	}
+	return nil
+}
+
+func resourceElasticsearchApiKeyCreate(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchApiKeyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	apiKey := ApiKey{
+		Name:            d.Get("name").(string),
+		RoleDescriptors: optionalInterfaceJson(d.Get("role_descriptors").(string)),
+		Expiration:      d.Get("expiration").(string),
+	}
+
+	response, err := elastic7CreateApiKey(esClient, apiKey)
+	if err != nil {
+		return err
+	}
+	d.SetId(response.ID)
+
+	// the encoded key is only returned from ElasticSearch 7.16
+	encoded := response.Encoded
+	if encoded == "" {
+		encoded = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", response.ID, response.ApiKey)))
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("api_key", response.ApiKey)
+	ds.set("encoded", encoded)
+	if ds.err != nil {
+		return ds.err
+	}
+
+	return resourceElasticsearchApiKeyRead(d, meta)
+}
+
+func resourceElasticsearchApiKeyRead(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchApiKeyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	info, err := elastic7GetApiKey(esClient, d.Id())
+	if err != nil && !elastic7.IsNotFound(err) {
+		return err
+	}
+	if info == nil || info.Invalidated || apiKeyExpired(info, time.Now()) {
+		log.Printf("[WARN] API key (%s) not found, invalidated or expired, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	// the role descriptors are only returned from ElasticSearch 8.5, with
+	// their defaults, the configured ones are kept
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", info.Name)
+	return ds.err
+}
+
+func resourceElasticsearchApiKeyUpdate(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchApiKeyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	err = elastic7UpdateApiKey(esClient, d.Id(), optionalInterfaceJson(d.Get("role_descriptors").(string)))
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchApiKeyRead(d, meta)
+}
+
+func resourceElasticsearchApiKeyDelete(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchApiKeyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	// the ids parameter replaces the single id from ElasticSearch 7.10
+	body := map[string]interface{}{"id": d.Id()}
+	if elasticVersion.GreaterThanOrEqual(apiKeyInvalidateIdsMinimalVersion) {
+		body = map[string]interface{}{"ids": []string{d.Id()}}
+	}
+
+	_, err = esClient.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   "/_security/api_key",
+		Body:   body,
+	})
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] API key (%s) not found, removing from state", d.Id())
+			return nil
+		}
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceElasticsearchApiKeyClient(meta interface{}) (*elastic7.Client, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		return client, nil
+	default:
+		return nil, fmt.Errorf("API key endpoint only available from ElasticSearch >= 7.0, got version < 7.0.0")
+	}
+}
+
+func elastic7CreateApiKey(client *elastic7.Client, apiKey ApiKey) (*ApiKeyCreateResponse, error) {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   "/_security/api_key",
+		Body:   apiKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response ApiKeyCreateResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling API key body: %+v: %+v", err, res.Body)
+	}
+	return &response, nil
+}
+
+// elastic7GetApiKey returns the information of the key, nil when it doesn't
+// exist
+func elastic7GetApiKey(client *elastic7.Client, id string) (*ApiKeyInfo, error) {
+	params := url.Values{}
+	params.Set("id", id)
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_security/api_key",
+		Params: params,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response ApiKeysResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling API key body: %+v: %+v", err, res.Body)
+	}
+	if len(response.ApiKeys) == 0 {
+		return nil, nil
+	}
+	return &response.ApiKeys[0], nil
+}
+
+// apiKeyExpired returns whether the key has expired, the expiration is in
+// milliseconds since the epoch and missing for the keys which don't expire
+func apiKeyExpired(info *ApiKeyInfo, now time.Time) bool {
+	return info.Expiration != 0 && info.Expiration <= now.UnixNano()/int64(time.Millisecond)
+}
+
+func elastic7UpdateApiKey(client *elastic7.Client, id string, roleDescriptors interface{}) error {
+	path, err := uritemplates.Expand("/_security/api_key/{id}", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for API key: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   map[string]interface{}{"role_descriptors": roleDescriptors},
+	})
+	return err
+}
+
+type ApiKey struct {
+	Name            string      `json:"name"`
+	RoleDescriptors interface{} `json:"role_descriptors,omitempty"`
+	Expiration      string      `json:"expiration,omitempty"`
+}
+
+type ApiKeyCreateResponse struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Expiration int64  `json:"expiration,omitempty"`
+	ApiKey     string `json:"api_key"`
+	Encoded    string `json:"encoded,omitempty"`
+}
+
+type ApiKeysResponse struct {
+	ApiKeys []ApiKeyInfo `json:"api_keys"`
+}
+
+type ApiKeyInfo struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Expiration  int64  `json:"expiration,omitempty"`
+	Invalidated bool   `json:"invalidated"`
+}
@@ -0,0 +1,133 @@
+package es
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchApiKey(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testCheckElasticsearchApiKeyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchApiKey(randomName, "read"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchApiKeyExists("elasticsearch_api_key.test"),
+					resource.TestCheckResourceAttr("elasticsearch_api_key.test", "name", randomName),
+					resource.TestCheckResourceAttrSet("elasticsearch_api_key.test", "api_key"),
+					resource.TestCheckResourceAttrSet("elasticsearch_api_key.test", "encoded"),
+				),
+			},
+			{
+				// updated in place or replaced, depending on the version
+				Config: testAccElasticsearchApiKey(randomName, "all"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchApiKeyExists("elasticsearch_api_key.test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_api_key.test", "encoded"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchApiKeyExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No API key ID is set")
+		}
+
+		client, err := resourceElasticsearchApiKeyClient(testAccXPackProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		info, err := elastic7GetApiKey(client, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if info == nil || info.Invalidated {
+			return fmt.Errorf("API key %s not found or invalidated", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchApiKeyDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_api_key" {
+			continue
+		}
+
+		client, err := resourceElasticsearchApiKeyClient(testAccXPackProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		// the invalidated keys are still returned until they are removed
+		info, err := elastic7GetApiKey(client, rs.Primary.ID)
+		if err != nil {
+			return nil
+		}
+		if info != nil && !info.Invalidated {
+			return fmt.Errorf("API key %s still valid", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func testAccElasticsearchApiKey(name string, privilege string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_api_key" "test" {
+  name       = "%s"
+  expiration = "1d"
+
+  role_descriptors = jsonencode({
+    "terraform-test" = {
+      cluster = []
+      indices = [{
+        names      = ["terraform-test-*"]
+        privileges = ["%s"]
+      }]
+    }
+  })
+}
+`, name, privilege)
+}
+
+func TestApiKeyExpired(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	tests := []struct {
+		name       string
+		expiration int64
+		expected   bool
+	}{
+		{"no expiration", 0, false},
+		{"not expired", now.Add(time.Hour).UnixNano() / int64(time.Millisecond), false},
+		{"expired", now.Add(-time.Hour).UnixNano() / int64(time.Millisecond), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if actual := apiKeyExpired(&ApiKeyInfo{Expiration: tt.expiration}, now); actual != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, actual)
+			}
+		})
+	}
+}
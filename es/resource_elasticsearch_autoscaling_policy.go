@@ -0,0 +1,224 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var autoscalingPolicyMinimalVersion, _ = version.NewVersion("7.11.0")
+
+func resourceElasticsearchAutoscalingPolicy() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch autoscaling policy resource, which defines the deciders of the required capacity of the nodes with the given roles. Autoscaling is only available on Elastic Cloud, ECE and ECK. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/autoscaling-put-autoscaling-policy.html) for more details.",
+		Create:      resourceElasticsearchAutoscalingPolicyCreate,
+		Read:        resourceElasticsearchAutoscalingPolicyRead,
+		Update:      resourceElasticsearchAutoscalingPolicyUpdate,
+		Delete:      resourceElasticsearchAutoscalingPolicyDelete,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:         schema.TypeString,
+				Description:  "Name of the autoscaling policy.",
+				ForceNew:     true,
+				Required:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+			},
+			"roles": {
+				Type:        schema.TypeSet,
+				Description: "The node roles the policy applies to, e.g. `data_hot`. The roles of the policies of a cluster can't overlap.",
+				Required:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"deciders": {
+				Type:             schema.TypeString,
+				Description:      "A JSON string of the deciders of the policy and their settings, keyed by decider name, e.g. `{\"fixed\": {}}`. The default deciders of the roles are used when not set, removing the setting resets them.",
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceElasticsearchAutoscalingPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	err := resourceElasticsearchPutAutoscalingPolicy(name, d, meta)
+	if err != nil {
+		return err
+	}
+	d.SetId(name)
+
+	return resourceElasticsearchAutoscalingPolicyRead(d, meta)
+}
+
+func resourceElasticsearchAutoscalingPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	esClient, err := resourceElasticsearchAutoscalingPolicyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	policy, err := elastic7GetAutoscalingPolicy(esClient, id)
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Autoscaling policy (%s) not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	deciders := ""
+	if len(policy.Deciders) > 0 {
+		deciders, err = structure.NormalizeJsonString(string(policy.Deciders))
+		if err != nil {
+			return fmt.Errorf("error normalizing the deciders of the autoscaling policy %s: %+v", id, err)
+		}
+		// an empty object is the default deciders
+		if deciders == "{}" && d.Get("deciders").(string) == "" {
+			deciders = ""
+		}
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", id)
+	ds.set("roles", policy.Roles)
+	ds.set("deciders", deciders)
+	return ds.err
+}
+
+func resourceElasticsearchAutoscalingPolicyUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchPutAutoscalingPolicy(d.Id(), d, meta)
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchAutoscalingPolicyRead(d, meta)
+}
+
+func resourceElasticsearchAutoscalingPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchAutoscalingPolicyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	path, err := uritemplates.Expand("/_autoscaling/policy/{name}", map[string]string{
+		"name": d.Id(),
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for autoscaling policy: %+v", err)
+	}
+
+	_, err = esClient.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Autoscaling policy (%s) not found, removing from state", d.Id())
+			return nil
+		}
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceElasticsearchAutoscalingPolicyClient(meta interface{}) (*elastic7.Client, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return nil, err
+		}
+		if elasticVersion.LessThan(autoscalingPolicyMinimalVersion) {
+			return nil, fmt.Errorf("autoscaling policy endpoint only available from ElasticSearch >= 7.11, got version %s", elasticVersion.String())
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("autoscaling policy endpoint only available from ElasticSearch >= 7.11, got version < 7.0.0")
+	}
+}
+
+func resourceElasticsearchPutAutoscalingPolicy(name string, d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchAutoscalingPolicyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	policy := AutoscalingPolicy{
+		Roles: expandStringList(d.Get("roles").(*schema.Set).List()),
+	}
+	if deciders, ok := d.GetOk("deciders"); ok {
+		policy.Deciders = json.RawMessage(deciders.(string))
+	} else if d.HasChange("deciders") {
+		// the current deciders are kept when they are omitted, an empty
+		// object resets them to the default deciders of the roles
+		policy.Deciders = json.RawMessage("{}")
+	}
+
+	path, err := uritemplates.Expand("/_autoscaling/policy/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for autoscaling policy: %+v", err)
+	}
+
+	_, err = esClient.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   policy,
+	})
+	return err
+}
+
+func elastic7GetAutoscalingPolicy(client *elastic7.Client, name string) (*AutoscalingPolicy, error) {
+	path, err := uritemplates.Expand("/_autoscaling/policy/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building URL path for autoscaling policy: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var policy AutoscalingPolicy
+	if err := json.Unmarshal(res.Body, &policy); err != nil {
+		return nil, fmt.Errorf("error unmarshalling autoscaling policy body: %+v: %+v", err, res.Body)
+	}
+	return &policy, nil
+}
+
+type AutoscalingPolicy struct {
+	Roles    []string        `json:"roles"`
+	Deciders json.RawMessage `json:"deciders,omitempty"`
+}
@@ -0,0 +1,134 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+func TestAccElasticsearchAutoscalingPolicy(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	var allowed bool
+	if _, err := resourceElasticsearchAutoscalingPolicyClient(meta); err == nil {
+		allowed = true
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Autoscaling policies only supported on ES >= 7.11")
+			}
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testCheckElasticsearchAutoscalingPolicyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchAutoscalingPolicy("1gb"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchAutoscalingPolicyExists("elasticsearch_autoscaling_policy.test"),
+					resource.TestCheckResourceAttr("elasticsearch_autoscaling_policy.test", "roles.#", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_autoscaling_policy.test", "deciders", `{"fixed":{"memory":"1gb","nodes":"1","storage":"1gb"}}`),
+				),
+			},
+			{
+				Config: testAccElasticsearchAutoscalingPolicy("2gb"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchAutoscalingPolicyExists("elasticsearch_autoscaling_policy.test"),
+					resource.TestCheckResourceAttr("elasticsearch_autoscaling_policy.test", "deciders", `{"fixed":{"memory":"2gb","nodes":"1","storage":"1gb"}}`),
+				),
+			},
+			{
+				// removing the deciders resets them to the defaults
+				Config: testAccElasticsearchAutoscalingPolicyDefaultDeciders,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchAutoscalingPolicyExists("elasticsearch_autoscaling_policy.test"),
+					resource.TestCheckResourceAttr("elasticsearch_autoscaling_policy.test", "deciders", ""),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_autoscaling_policy.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchAutoscalingPolicyExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No autoscaling policy ID is set")
+		}
+
+		client, err := resourceElasticsearchAutoscalingPolicyClient(testAccXPackProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		_, err = elastic7GetAutoscalingPolicy(client, rs.Primary.ID)
+		return err
+	}
+}
+
+func testCheckElasticsearchAutoscalingPolicyDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_autoscaling_policy" {
+			continue
+		}
+
+		client, err := resourceElasticsearchAutoscalingPolicyClient(testAccXPackProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		_, err = elastic7GetAutoscalingPolicy(client, rs.Primary.ID)
+		if err != nil {
+			if elastic7.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+
+		return fmt.Errorf("Autoscaling policy %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccElasticsearchAutoscalingPolicy(memory string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_autoscaling_policy" "test" {
+  name  = "terraform-test"
+  roles = ["data_hot"]
+  deciders = jsonencode({
+    fixed = {
+      storage = "1gb"
+      memory  = "%s"
+      nodes   = "1"
+    }
+  })
+}
+`, memory)
+}
+
+var testAccElasticsearchAutoscalingPolicyDefaultDeciders = `
+resource "elasticsearch_autoscaling_policy" "test" {
+  name  = "terraform-test"
+  roles = ["data_hot"]
+}
+`
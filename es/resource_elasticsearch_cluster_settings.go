@@ -0,0 +1,1072 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+// The cluster settings are a singleton, there is only one per cluster
+const clusterSettingsID = "settings"
+
+// the forced awareness values are set by attribute, e.g.
+// cluster.routing.allocation.awareness.force.zone.values
+const clusterSettingsAwarenessForcePrefix = "cluster.routing.allocation.awareness.force."
+
+var (
+	clusterSettingsKeys = []string{
+		"cluster.routing.allocation.enable",
+		"xpack.watcher.history.cleaner_service.enabled",
+		"cluster.routing.allocation.disk.watermark.low",
+		"cluster.routing.allocation.disk.watermark.high",
+		"cluster.routing.allocation.disk.watermark.flood_stage",
+		"cluster.routing.allocation.awareness.attributes",
+		"indices.recovery.max_bytes_per_sec",
+		"indices.recovery.max_concurrent_file_chunks",
+		"cluster.routing.allocation.node_concurrent_recoveries",
+	}
+	// the settings which would leave the cluster unable to allocate shards or
+	// to index documents if a destroy failed before resetting them, with
+	// their documented defaults
+	clusterSettingsSafeDefaults = map[string]interface{}{
+		"cluster.routing.allocation.enable":     "all",
+		"cluster.routing.rebalance.enable":      "all",
+		"cluster.blocks.read_only":              false,
+		"cluster.blocks.read_only_allow_delete": false,
+	}
+	// the disk watermarks from the lowest to the highest disk usage
+	diskWatermarkKeys = []string{
+		"cluster.routing.allocation.disk.watermark.low",
+		"cluster.routing.allocation.disk.watermark.high",
+		"cluster.routing.allocation.disk.watermark.flood_stage",
+	}
+
+	diskWatermarkPercentRegexp = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?)%$`)
+	diskWatermarkRatioRegexp   = regexp.MustCompile(`^(0(\.[0-9]+)?|1(\.0+)?|\.[0-9]+)$`)
+	byteValueRegexp            = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?)(b|kb|mb|gb|tb|pb)$`)
+	// a byte rate per second, `0` disables the throttling
+	byteRateRegexp         = regexp.MustCompile(`^(0|[0-9]+(\.[0-9]+)?(b|kb|mb|gb|tb|pb))$`)
+	diskWatermarkByteUnits = map[string]float64{
+		"b":  1,
+		"kb": 1 << 10,
+		"mb": 1 << 20,
+		"gb": 1 << 30,
+		"tb": 1 << 40,
+		"pb": 1 << 50,
+	}
+)
+
+func resourceElasticsearchClusterSettings() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Manages the persistent and transient cluster-wide settings of an Elasticsearch cluster. Only the settings declared in the resource are managed, they are reset to their defaults when removed or when the resource is destroyed.",
+		CreateContext: resourceElasticsearchClusterSettingsCreateContext,
+		Read:          resourceElasticsearchClusterSettingsRead,
+		UpdateContext: resourceElasticsearchClusterSettingsUpdateContext,
+		Delete:        resourceElasticsearchClusterSettingsDelete,
+		CustomizeDiff: customdiff.All(
+			resourceElasticsearchClusterSettingsValidateDiskWatermarks,
+			resourceElasticsearchClusterSettingsValidateAwarenessForce,
+			resourceElasticsearchClusterSettingsValidatePersistent,
+		),
+		Schema: map[string]*schema.Schema{
+			"cluster_routing_allocation_enable": {
+				Type:         schema.TypeString,
+				Description:  "Enable or disable allocation for specific kinds of shards: `all`, `primaries`, `new_primaries` or `none`. Removing the setting or destroying the resource resets it to the default, `all`.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"all", "primaries", "new_primaries", "none"}, false),
+			},
+			// a string, as a boolean removed from the configuration can't be
+			// told apart from false to reset the setting
+			"xpack_watcher_history_cleaner_service_enabled": {
+				Type:         schema.TypeString,
+				Description:  "Whether the cleaner service deletes the watch history indices older than `xpack.monitoring.history.duration` (ElasticSearch < 8.0): `true` or `false`. From ElasticSearch 7.7 the retention of the watch history is managed by the `watch-history-ilm-policy` index lifecycle policy instead. Removing the setting or destroying the resource resets it to the default.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"true", "false"}, false),
+			},
+			"cluster_routing_allocation_disk_watermark_low": {
+				Type:         schema.TypeString,
+				Description:  "The disk usage above which no shard is allocated to a node, as a percentage (`85%`), a ratio (`0.85`) or a minimum free space (`500mb`). It must be lower than the high watermark. Removing the setting or destroying the resource resets it to the default, `85%`.",
+				Optional:     true,
+				ValidateFunc: validateDiskWatermark,
+			},
+			"cluster_routing_allocation_disk_watermark_high": {
+				Type:         schema.TypeString,
+				Description:  "The disk usage above which the shards are relocated away from a node, as a percentage (`90%`), a ratio (`0.9`) or a minimum free space (`200mb`). It must be between the low and the flood stage watermarks. Removing the setting or destroying the resource resets it to the default, `90%`.",
+				Optional:     true,
+				ValidateFunc: validateDiskWatermark,
+			},
+			"cluster_routing_allocation_disk_watermark_flood_stage": {
+				Type:         schema.TypeString,
+				Description:  "The disk usage above which a read-only block is applied to the indices having a shard on a node, as a percentage (`95%`), a ratio (`0.95`) or a minimum free space (`100mb`). It must be higher than the high watermark. Removing the setting or destroying the resource resets it to the default, `95%`.",
+				Optional:     true,
+				ValidateFunc: validateDiskWatermark,
+			},
+			"cluster_routing_allocation_awareness_attributes": {
+				Type:        schema.TypeString,
+				Description: "A comma separated list of the node attributes used for the shard allocation awareness, e.g. `zone,rack`. Removing the setting or destroying the resource disables the allocation awareness.",
+				Optional:    true,
+			},
+			"cluster_routing_allocation_awareness_force": {
+				Type:        schema.TypeSet,
+				Description: "The forced awareness of an attribute of `cluster_routing_allocation_awareness_attributes`: the replicas of a shard are never allocated to the nodes of the same value of the attribute, even when the nodes of the other values are missing, e.g. during a zone outage. Removing the block or destroying the resource disables the forced awareness of the attribute, the attributes forced outside of Terraform are left as they are.",
+				Optional:    true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"attribute": {
+							Type:        schema.TypeString,
+							Description: "The awareness attribute, e.g. `zone`.",
+							Required:    true,
+						},
+						"values": {
+							Type:        schema.TypeList,
+							Description: "All the values of the attribute, e.g. `[\"zone-a\", \"zone-b\"]`. They can't be empty or contain commas, the values are sent as a comma separated list.",
+							Required:    true,
+							MinItems:    1,
+							Elem: &schema.Schema{
+								Type:         schema.TypeString,
+								ValidateFunc: validation.All(validation.StringIsNotWhiteSpace, validation.StringDoesNotContainAny(",")),
+							},
+						},
+					},
+				},
+			},
+			"validate_awareness_attributes": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates that the `cluster_routing_allocation_awareness_attributes` are checked, before applying them, against the attributes of the nodes from the nodes info API. A warning is shown for the attributes which are set on no node, e.g. typos. Defaults to `false`.",
+				Default:     false,
+				Optional:    true,
+			},
+			"indices_recovery_max_bytes_per_sec": {
+				Type:         schema.TypeString,
+				Description:  "The maximum total inbound and outbound recovery traffic of each node, as a byte value per second (`100mb`) or `0` to disable the throttling. Removing the setting or destroying the resource resets it to the default, `40mb` on most nodes.",
+				Optional:     true,
+				ValidateFunc: validation.StringMatch(byteRateRegexp, "must be a byte value, e.g. `100mb`, or `0`"),
+			},
+			"indices_recovery_max_concurrent_file_chunks": {
+				Type:         schema.TypeInt,
+				Description:  "The number of file chunks sent in parallel for each recovery. Removing the setting or destroying the resource resets it to the default, `2`.",
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 8),
+			},
+			"cluster_routing_allocation_node_concurrent_recoveries": {
+				Type:         schema.TypeInt,
+				Description:  "The number of concurrent incoming and outgoing shard recoveries allowed on a node. Removing the setting or destroying the resource resets it to the default, `2`.",
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"persistent": {
+				Type:         schema.TypeString,
+				Description:  "A JSON object of arbitrary persistent cluster settings, nested or with flat keys, e.g. `{\"cluster.max_shards_per_node\": 2000}`. Only the declared keys are managed: an out-of-band change of one of them is shown in the plan, and they are reset to their defaults when removed or when the resource is destroyed. The settings having a dedicated argument can't be set here.",
+				Optional:     true,
+				ValidateFunc: validateClusterSettingsJSON,
+				StateFunc:    normalizeClusterSettingsJSON,
+			},
+			"transient": {
+				Type:         schema.TypeString,
+				Description:  "A JSON object of arbitrary transient cluster settings, like `persistent`. The transient settings are lost on a full cluster restart and take precedence over the persistent ones, they are deprecated from ElasticSearch 7.16.",
+				Optional:     true,
+				ValidateFunc: validateClusterSettingsJSON,
+				StateFunc:    normalizeClusterSettingsJSON,
+			},
+			"watcher_state": {
+				Type:         schema.TypeString,
+				Description:  "Start or stop the Watcher service of the cluster: `started` or `stopped`. The `xpack.watcher.enabled` node setting is static and requires a restart of the nodes, this uses the Watcher start and stop APIs instead. The state is left as is when the resource is destroyed.",
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"started", "stopped"}, false),
+			},
+			"safe_destroy": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates that, when the resource is destroyed, the managed settings which are risky to leave set, e.g. `cluster.routing.allocation.enable`, are first set explicitly to their safe defaults, e.g. `all`, in a request of their own, before the other settings are reset. A destroy failing mid-way then never leaves the shard allocation disabled or the cluster read-only, but these settings stay set to their defaults. Defaults to `false`.",
+				Default:     false,
+				Optional:    true,
+			},
+			"wait_for_green": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates to wait for the cluster health to be green after shard allocation is (re-)enabled for all shards, e.g. at the end of a rolling restart. Defaults to `false`.",
+				Default:     false,
+				Optional:    true,
+			},
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+			Update: schema.DefaultTimeout(5 * time.Minute),
+			Delete: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func validateDiskWatermark(i interface{}, k string) (warnings []string, errors []error) {
+	v, ok := i.(string)
+	if !ok {
+		return nil, []error{fmt.Errorf("expected type of %s to be string", k)}
+	}
+	if _, _, err := parseDiskWatermark(v); err != nil {
+		return nil, []error{fmt.Errorf("%s: %s", k, err)}
+	}
+	return nil, nil
+}
+
+// parseDiskWatermark returns the disk usage percentage of a percentage or
+// ratio watermark, or the free space in bytes of a byte value watermark
+func parseDiskWatermark(watermark string) (float64, bool, error) {
+	if m := diskWatermarkPercentRegexp.FindStringSubmatch(watermark); m != nil {
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil || value > 100 {
+			return 0, false, fmt.Errorf("%q is not a valid percentage", watermark)
+		}
+		return value, false, nil
+	}
+	if diskWatermarkRatioRegexp.MatchString(watermark) {
+		value, err := strconv.ParseFloat(watermark, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%q is not a valid ratio", watermark)
+		}
+		return value * 100, false, nil
+	}
+	if m := byteValueRegexp.FindStringSubmatch(watermark); m != nil {
+		value, err := strconv.ParseFloat(m[1], 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("%q is not a valid byte value", watermark)
+		}
+		return value * diskWatermarkByteUnits[m[3]], true, nil
+	}
+
+	return 0, false, fmt.Errorf("%q must be a percentage (e.g. `85%%`), a ratio (e.g. `0.85`) or a byte value (e.g. `500mb`)", watermark)
+}
+
+// resourceElasticsearchClusterSettingsValidateDiskWatermarks checks the
+// ordering of the configured watermarks, which Elasticsearch otherwise only
+// rejects when applying them
+func resourceElasticsearchClusterSettingsValidateDiskWatermarks(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	var (
+		previousKey   string
+		previousRaw   string
+		previousValue float64
+		previousBytes bool
+	)
+	for _, key := range diskWatermarkKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		if !d.NewValueKnown(schemaName) {
+			return nil
+		}
+		raw, ok := d.GetOk(schemaName)
+		if !ok {
+			continue
+		}
+		value, bytes, err := parseDiskWatermark(raw.(string))
+		if err != nil {
+			return err
+		}
+
+		if previousKey != "" {
+			if bytes != previousBytes {
+				return fmt.Errorf("%s and %s must both be percentages or ratios, or both be byte values", previousKey, key)
+			}
+			// byte values are the free space, they decrease with the disk usage
+			if (!bytes && value < previousValue) || (bytes && value > previousValue) {
+				return fmt.Errorf("%s [%s] must be at a disk usage at least as high as %s [%s]", key, raw.(string), previousKey, previousRaw)
+			}
+		}
+		previousKey, previousRaw, previousValue, previousBytes = key, raw.(string), value, bytes
+	}
+
+	return nil
+}
+
+// resourceElasticsearchClusterSettingsValidateAwarenessForce checks that the
+// forced attributes are awareness attributes, Elasticsearch ignores the others
+func resourceElasticsearchClusterSettingsValidateAwarenessForce(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("cluster_routing_allocation_awareness_attributes") || !d.NewValueKnown("cluster_routing_allocation_awareness_force") {
+		return nil
+	}
+
+	attributes := make(map[string]bool)
+	for _, attribute := range strings.Split(d.Get("cluster_routing_allocation_awareness_attributes").(string), ",") {
+		attributes[strings.TrimSpace(attribute)] = true
+	}
+
+	forced := make(map[string]bool)
+	for _, raw := range d.Get("cluster_routing_allocation_awareness_force").(*schema.Set).List() {
+		attribute := raw.(map[string]interface{})["attribute"].(string)
+		if forced[attribute] {
+			return fmt.Errorf("the forced awareness of the attribute %q is declared more than once", attribute)
+		}
+		forced[attribute] = true
+		if !attributes[attribute] {
+			return fmt.Errorf("the forced awareness attribute %q must be one of the cluster_routing_allocation_awareness_attributes", attribute)
+		}
+	}
+
+	return nil
+}
+
+// resourceElasticsearchClusterSettingsValidatePersistent checks that the
+// settings having a dedicated argument are not managed twice
+func resourceElasticsearchClusterSettingsValidatePersistent(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("persistent") {
+		return nil
+	}
+	persistent, err := expandClusterSettingsJSON(d.Get("persistent").(string))
+	if err != nil {
+		return err
+	}
+
+	for key := range persistent {
+		for _, managedKey := range clusterSettingsKeys {
+			if key == managedKey {
+				return fmt.Errorf("the persistent setting %s must be set with its argument %s", key, strings.Replace(key, ".", "_", -1))
+			}
+		}
+		if strings.HasPrefix(key, clusterSettingsAwarenessForcePrefix) {
+			return fmt.Errorf("the persistent setting %s must be set with the cluster_routing_allocation_awareness_force blocks", key)
+		}
+	}
+
+	return nil
+}
+
+// the awareness attributes are checked before applying the settings, warnings
+// can only be returned from the context functions
+func resourceElasticsearchClusterSettingsCreateContext(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	diags := resourceElasticsearchClusterSettingsAwarenessWarnings(ctx, d, meta)
+	if err := resourceElasticsearchClusterSettingsCreate(d, meta); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+func resourceElasticsearchClusterSettingsUpdateContext(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if d.HasChanges("cluster_routing_allocation_awareness_attributes", "validate_awareness_attributes") {
+		diags = resourceElasticsearchClusterSettingsAwarenessWarnings(ctx, d, meta)
+	}
+	if err := resourceElasticsearchClusterSettingsUpdate(d, meta); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+// resourceElasticsearchClusterSettingsAwarenessWarnings warns about the
+// awareness attributes which are set on no node, the nodes with the attribute
+// may not have joined the cluster yet so this is not an error
+func resourceElasticsearchClusterSettingsAwarenessWarnings(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	attributes, ok := d.GetOk("cluster_routing_allocation_awareness_attributes")
+	if !ok || !d.Get("validate_awareness_attributes").(bool) {
+		return nil
+	}
+
+	nodesAttributes, err := resourceElasticsearchGetNodesAttributes(ctx, meta)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	for _, attribute := range missingAwarenessAttributes(attributes.(string), nodesAttributes) {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Warning,
+			Summary:       fmt.Sprintf("Awareness attribute %q is set on no node", attribute),
+			Detail:        fmt.Sprintf("None of the %d nodes of the cluster has the node.attr.%s attribute, the shards allocation ignores it until nodes with the attribute join the cluster.", len(nodesAttributes), attribute),
+			AttributePath: cty.GetAttrPath("cluster_routing_allocation_awareness_attributes"),
+		})
+	}
+	return diags
+}
+
+// missingAwarenessAttributes returns the attributes of the comma separated
+// list which are set on no node
+func missingAwarenessAttributes(attributes string, nodesAttributes []map[string]string) []string {
+	var missing []string
+	for _, attribute := range strings.Split(attributes, ",") {
+		attribute = strings.TrimSpace(attribute)
+		if attribute == "" {
+			continue
+		}
+		found := false
+		for _, nodeAttributes := range nodesAttributes {
+			if _, ok := nodeAttributes[attribute]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, attribute)
+		}
+	}
+	return missing
+}
+
+func resourceElasticsearchGetNodesAttributes(ctx context.Context, meta interface{}) ([]map[string]string, error) {
+	var nodesAttributes []map[string]string
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		res, err := client.NodesInfo().Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range res.Nodes {
+			nodesAttributes = append(nodesAttributes, node.Attributes)
+		}
+	case *elastic6.Client:
+		res, err := client.NodesInfo().Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range res.Nodes {
+			nodesAttributes = append(nodesAttributes, node.Attributes)
+		}
+	default:
+		return nil, errors.New("Elasticsearch version not supported")
+	}
+
+	return nodesAttributes, nil
+}
+
+func resourceElasticsearchClusterSettingsCreate(d *schema.ResourceData, meta interface{}) error {
+	settings, err := clusterSettingsFromResourceData(d)
+	if err != nil {
+		return err
+	}
+	transient, err := expandClusterSettingsJSON(d.Get("transient").(string))
+	if err != nil {
+		return err
+	}
+
+	err = resourceElasticsearchPutClusterSettings(settings, transient, meta)
+	if err != nil {
+		return err
+	}
+	d.SetId(clusterSettingsID)
+
+	if state, ok := d.GetOk("watcher_state"); ok {
+		err = resourceElasticsearchSetWatcherState(state.(string), meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = resourceElasticsearchClusterSettingsWaitForGreen(d, meta, d.Timeout(schema.TimeoutCreate))
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchClusterSettingsRead(d, meta)
+}
+
+func resourceElasticsearchClusterSettingsRead(d *schema.ResourceData, meta interface{}) error {
+	var settings, transient map[string]interface{}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		settings, transient, err = elastic7GetClusterSettings(client)
+	case *elastic6.Client:
+		settings, transient, err = elastic6GetClusterSettings(client)
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] resourceElasticsearchClusterSettingsRead: %+v, transient: %+v", settings, transient)
+
+	clusterSettingsSchema := resourceElasticsearchClusterSettings().Schema
+	ds := &resourceDataSetter{d: d}
+	for _, key := range clusterSettingsKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		value, ok := settings[key]
+		if !ok {
+			ds.set(schemaName, nil)
+			continue
+		}
+		// the settings values are returned as strings
+		if s, isString := value.(string); isString && clusterSettingsSchema[schemaName].Type == schema.TypeInt {
+			value, err = strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("error parsing cluster setting %s: %+v", key, err)
+			}
+		}
+		ds.set(schemaName, value)
+	}
+	// like the persistent settings, only the forced attributes of the state or
+	// of the config are tracked, the others are left to the cluster
+	trackedAttributes := make(map[string]bool)
+	for _, raw := range d.Get("cluster_routing_allocation_awareness_force").(*schema.Set).List() {
+		trackedAttributes[raw.(map[string]interface{})["attribute"].(string)] = true
+	}
+	ds.set("cluster_routing_allocation_awareness_force", flattenClusterSettingsAwarenessForce(settings, trackedAttributes))
+
+	// only the declared keys are tracked, the others are left to the cluster
+	for name, clusterSettings := range map[string]map[string]interface{}{
+		"persistent": settings,
+		"transient":  transient,
+	} {
+		raw := d.Get(name).(string)
+		if raw == "" {
+			continue
+		}
+		declared, err := expandClusterSettingsJSON(raw)
+		if err != nil {
+			return err
+		}
+		managed, err := flattenClusterSettingsJSON(declared, clusterSettings)
+		if err != nil {
+			return err
+		}
+		ds.set(name, managed)
+	}
+
+	// only read the Watcher state when managed, it fails if Watcher isn't
+	// available
+	if _, ok := d.GetOk("watcher_state"); ok {
+		state, err := resourceElasticsearchGetWatcherState(meta)
+		if err != nil {
+			return err
+		}
+		ds.set("watcher_state", state)
+	}
+
+	return ds.err
+}
+
+func resourceElasticsearchClusterSettingsUpdate(d *schema.ResourceData, meta interface{}) error {
+	settings := make(map[string]interface{})
+	for _, key := range clusterSettingsKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		if d.HasChange(schemaName) {
+			settings[key] = clusterSettingValue(d.Get(schemaName))
+		}
+	}
+	transient := make(map[string]interface{})
+	for name, changed := range map[string]map[string]interface{}{
+		"persistent": settings,
+		"transient":  transient,
+	} {
+		if !d.HasChange(name) {
+			continue
+		}
+		o, n := d.GetChange(name)
+		err := clusterSettingsJSONChanges(o.(string), n.(string), changed)
+		if err != nil {
+			return err
+		}
+	}
+	if d.HasChange("cluster_routing_allocation_awareness_force") {
+		o, n := d.GetChange("cluster_routing_allocation_awareness_force")
+		// the attributes no longer forced are reset
+		for key := range clusterSettingsAwarenessForce(o.(*schema.Set)) {
+			settings[key] = nil
+		}
+		for key, values := range clusterSettingsAwarenessForce(n.(*schema.Set)) {
+			settings[key] = values
+		}
+	}
+
+	if len(settings) > 0 || len(transient) > 0 {
+		err := resourceElasticsearchPutClusterSettings(settings, transient, meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if state, ok := d.GetOk("watcher_state"); ok && d.HasChange("watcher_state") {
+		err := resourceElasticsearchSetWatcherState(state.(string), meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := resourceElasticsearchClusterSettingsWaitForGreen(d, meta, d.Timeout(schema.TimeoutUpdate))
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchClusterSettingsRead(d, meta)
+}
+
+func resourceElasticsearchClusterSettingsDelete(d *schema.ResourceData, meta interface{}) error {
+	// reset all the managed settings to their defaults
+	settings := make(map[string]interface{})
+	for _, key := range clusterSettingsKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		// GetOk ignores boolean settings set to false
+		if _, ok := d.GetOkExists(schemaName); ok {
+			settings[key] = nil
+		}
+	}
+	for key := range clusterSettingsAwarenessForce(d.Get("cluster_routing_allocation_awareness_force").(*schema.Set)) {
+		settings[key] = nil
+	}
+	transient := make(map[string]interface{})
+	for name, reset := range map[string]map[string]interface{}{
+		"persistent": settings,
+		"transient":  transient,
+	} {
+		err := clusterSettingsJSONChanges(d.Get(name).(string), "", reset)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Get("safe_destroy").(bool) {
+		safeSettings := clusterSettingsSafeResets(settings)
+		safeTransient := clusterSettingsSafeResets(transient)
+		if len(safeSettings) > 0 || len(safeTransient) > 0 {
+			err := resourceElasticsearchPutClusterSettings(safeSettings, safeTransient, meta)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(settings) > 0 || len(transient) > 0 {
+		for key := range settings {
+			log.Printf("[INFO] Resetting the persistent cluster setting %s to its default", key)
+		}
+		for key := range transient {
+			log.Printf("[INFO] Resetting the transient cluster setting %s to its default", key)
+		}
+		err := resourceElasticsearchPutClusterSettings(settings, transient, meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.Get("wait_for_green").(bool) {
+		err := resourceElasticsearchClusterHealthWaitForGreen(meta, d.Timeout(schema.TimeoutDelete))
+		if err != nil {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// clusterSettingsSafeResets moves the resets of the settings having a safe
+// default out of the resets, they are set to their safe default instead
+func clusterSettingsSafeResets(resets map[string]interface{}) map[string]interface{} {
+	safe := make(map[string]interface{})
+	for key := range resets {
+		value, ok := clusterSettingsSafeDefaults[key]
+		if !ok {
+			continue
+		}
+		log.Printf("[INFO] Resetting the cluster setting %s to its safe default %v", key, value)
+		safe[key] = value
+		delete(resets, key)
+	}
+	return safe
+}
+
+func clusterSettingsFromResourceData(d *schema.ResourceData) (map[string]interface{}, error) {
+	settings, err := expandClusterSettingsJSON(d.Get("persistent").(string))
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range clusterSettingsKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		// GetOk ignores boolean settings set to false
+		if raw, ok := d.GetOkExists(schemaName); ok {
+			settings[key] = raw
+		}
+	}
+	for key, values := range clusterSettingsAwarenessForce(d.Get("cluster_routing_allocation_awareness_force").(*schema.Set)) {
+		settings[key] = values
+	}
+	return settings, nil
+}
+
+// expandClusterSettingsJSON returns the settings of a JSON object by flat key,
+// the values are compared as strings as the cluster returns them as such
+func expandClusterSettingsJSON(raw string) (map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	if raw == "" {
+		return settings, nil
+	}
+
+	var nested map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&nested); err != nil {
+		return nil, fmt.Errorf("error unmarshalling cluster settings: %+v", err)
+	}
+
+	for key, value := range flattenMap(nested) {
+		switch v := value.(type) {
+		case nil:
+			// a null value only resets the setting, it is not managed
+		case []interface{}:
+			values := make([]interface{}, len(v))
+			for i, value := range v {
+				values[i] = fmt.Sprint(value)
+			}
+			settings[key] = values
+		default:
+			settings[key] = fmt.Sprint(v)
+		}
+	}
+	return settings, nil
+}
+
+// flattenClusterSettingsJSON returns the JSON object of the current values of
+// the declared settings, a setting missing from the cluster is left out so its
+// drift is shown in the plan
+func flattenClusterSettingsJSON(declared map[string]interface{}, settings map[string]interface{}) (string, error) {
+	managed := make(map[string]interface{})
+	for key := range declared {
+		if value, ok := settings[key]; ok {
+			managed[key] = value
+		}
+	}
+
+	body, err := json.Marshal(managed)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// clusterSettingsJSONChanges adds to the changes the settings of the new JSON
+// object and resets the settings removed from the old one
+func clusterSettingsJSONChanges(o string, n string, changes map[string]interface{}) error {
+	oldSettings, err := expandClusterSettingsJSON(o)
+	if err != nil {
+		return err
+	}
+	newSettings, err := expandClusterSettingsJSON(n)
+	if err != nil {
+		return err
+	}
+
+	for key := range oldSettings {
+		if _, ok := newSettings[key]; !ok {
+			changes[key] = nil
+		}
+	}
+	for key, value := range newSettings {
+		changes[key] = value
+	}
+	return nil
+}
+
+func validateClusterSettingsJSON(i interface{}, k string) (warnings []string, errors []error) {
+	if _, err := expandClusterSettingsJSON(i.(string)); err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a JSON object: %+v", k, err))
+	}
+	return
+}
+
+// normalizeClusterSettingsJSON stores the settings with flat keys and string
+// values, as read from the cluster
+func normalizeClusterSettingsJSON(i interface{}) string {
+	settings, err := expandClusterSettingsJSON(i.(string))
+	if err != nil || i.(string) == "" {
+		return i.(string)
+	}
+
+	body, err := json.Marshal(settings)
+	if err != nil {
+		return i.(string)
+	}
+	return string(body)
+}
+
+// clusterSettingsAwarenessForce returns the forced awareness values of the
+// blocks by setting key
+func clusterSettingsAwarenessForce(forces *schema.Set) map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, raw := range forces.List() {
+		force := raw.(map[string]interface{})
+		key := clusterSettingsAwarenessForcePrefix + force["attribute"].(string) + ".values"
+		settings[key] = expandStringList(force["values"].([]interface{}))
+	}
+	return settings
+}
+
+// flattenClusterSettingsAwarenessForce returns the blocks of the forced
+// awareness settings of the given attributes, or of all of them when nil, the
+// values are returned as a list or, when set as such, as a comma separated
+// string
+func flattenClusterSettingsAwarenessForce(settings map[string]interface{}, attributes map[string]bool) []interface{} {
+	var forces []interface{}
+	for key, value := range settings {
+		if !strings.HasPrefix(key, clusterSettingsAwarenessForcePrefix) || !strings.HasSuffix(key, ".values") {
+			continue
+		}
+		attribute := strings.TrimSuffix(strings.TrimPrefix(key, clusterSettingsAwarenessForcePrefix), ".values")
+		if attributes != nil && !attributes[attribute] {
+			continue
+		}
+
+		var values []string
+		switch v := value.(type) {
+		case string:
+			for _, value := range strings.Split(v, ",") {
+				values = append(values, strings.TrimSpace(value))
+			}
+		case []interface{}:
+			for _, value := range v {
+				values = append(values, fmt.Sprint(value))
+			}
+		}
+		forces = append(forces, map[string]interface{}{
+			"attribute": attribute,
+			"values":    values,
+		})
+	}
+	return forces
+}
+
+// clusterSettingValue converts an unset value to null, which resets the
+// setting to its default, none of the integer settings accepts 0
+func clusterSettingValue(value interface{}) interface{} {
+	if s, ok := value.(string); ok && s == "" {
+		return nil
+	}
+	if i, ok := value.(int); ok && i == 0 {
+		return nil
+	}
+	return value
+}
+
+func resourceElasticsearchClusterSettingsWaitForGreen(d *schema.ResourceData, meta interface{}, timeout time.Duration) error {
+	if !d.Get("wait_for_green").(bool) {
+		return nil
+	}
+
+	// only wait once allocation is possible for all shards
+	if enable, ok := d.GetOk("cluster_routing_allocation_enable"); ok && enable.(string) != "all" {
+		return nil
+	}
+
+	return resourceElasticsearchClusterHealthWaitForGreen(meta, timeout)
+}
+
+func resourceElasticsearchClusterHealthWaitForGreen(meta interface{}, timeout time.Duration) error {
+	var (
+		ctx       = context.Background()
+		esTimeout = fmt.Sprintf("%ds", int(timeout.Seconds()))
+		timedOut  bool
+		status    string
+	)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.ClusterHealthResponse
+		res, err = client.ClusterHealth().WaitForGreenStatus().Timeout(esTimeout).Do(ctx)
+		if err == nil {
+			timedOut, status = res.TimedOut, res.Status
+		}
+	case *elastic6.Client:
+		var res *elastic6.ClusterHealthResponse
+		res, err = client.ClusterHealth().WaitForGreenStatus().Timeout(esTimeout).Do(ctx)
+		if err == nil {
+			timedOut, status = res.TimedOut, res.Status
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+
+	if err != nil {
+		return err
+	}
+	if timedOut {
+		return fmt.Errorf("timed out after %s waiting for the cluster health to be green, got %s", timeout, status)
+	}
+
+	return nil
+}
+
+func resourceElasticsearchSetWatcherState(state string, meta interface{}) error {
+	action := "_start"
+	if state == "stopped" {
+		action = "_stop"
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_watcher/" + action,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_xpack/watcher/" + action,
+		})
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+
+	return err
+}
+
+// resourceElasticsearchGetWatcherState returns started if Watcher is started
+// or starting on any node, stopped otherwise
+func resourceElasticsearchGetWatcherState(meta interface{}) (string, error) {
+	var body json.RawMessage
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return "", err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_watcher/stats",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_xpack/watcher/stats",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var response struct {
+		Stats []struct {
+			WatcherState string `json:"watcher_state"`
+		} `json:"stats"`
+		// ElasticSearch 6 returns the state of the master node only
+		WatcherState string `json:"watcher_state"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling watcher stats body: %+v: %+v", err, body)
+	}
+
+	states := []string{response.WatcherState}
+	for _, stats := range response.Stats {
+		states = append(states, stats.WatcherState)
+	}
+	for _, state := range states {
+		if state == "started" || state == "starting" {
+			return "started", nil
+		}
+	}
+	return "stopped", nil
+}
+
+func resourceElasticsearchPutClusterSettings(settings map[string]interface{}, transient map[string]interface{}, meta interface{}) error {
+	request := map[string]interface{}{
+		"persistent": settings,
+	}
+	if len(transient) > 0 {
+		request["transient"] = transient
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] resourceElasticsearchPutClusterSettings: %s", body)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "PUT",
+			Path:   "/_cluster/settings",
+			Body:   string(body),
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "PUT",
+			Path:   "/_cluster/settings",
+			Body:   string(body),
+		})
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+
+	return err
+}
+
+func elastic7GetClusterSettings(client *elastic7.Client) (map[string]interface{}, map[string]interface{}, error) {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cluster/settings?flat_settings=true",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return clusterSettingsFromResponse(res.Body)
+}
+
+func elastic6GetClusterSettings(client *elastic6.Client) (map[string]interface{}, map[string]interface{}, error) {
+	res, err := client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_cluster/settings?flat_settings=true",
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return clusterSettingsFromResponse(res.Body)
+}
+
+// clusterSettingsFromResponse returns the persistent and the transient flat
+// settings, the defaults are not included
+func clusterSettingsFromResponse(body json.RawMessage) (map[string]interface{}, map[string]interface{}, error) {
+	var response struct {
+		Persistent map[string]interface{} `json:"persistent"`
+		Transient  map[string]interface{} `json:"transient"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, nil, fmt.Errorf("error unmarshalling cluster settings body: %+v: %+v", err, body)
+	}
+
+	return response.Persistent, response.Transient, nil
+}
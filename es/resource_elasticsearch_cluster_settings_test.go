@@ -0,0 +1,617 @@
+package es
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchClusterSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchClusterSettingsAllocationDisabled,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.enable", "primaries"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "cluster_routing_allocation_enable", "primaries"),
+				),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsAllocationEnabled,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.enable", "all"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "cluster_routing_allocation_enable", "all"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchClusterSettings_watcher(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccXPackProviders,
+		CheckDestroy: func(s *terraform.State) error {
+			return testCheckElasticsearchClusterSettingsDestroyWithMeta(s, testAccXPackProvider.Meta())
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchClusterSettingsWatcherStopped,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "watcher_state", "stopped"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "xpack_watcher_history_cleaner_service_enabled", "false"),
+				),
+			},
+			{
+				// leave Watcher started for the other tests
+				Config: testAccElasticsearchClusterSettingsWatcherStarted,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "watcher_state", "started"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "xpack_watcher_history_cleaner_service_enabled", "true"),
+				),
+			},
+			{
+				// removing the setting resets it
+				Config: testAccElasticsearchClusterSettingsWatcherStartedDefaults,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsMissingWithProvider("xpack.watcher.history.cleaner_service.enabled", testAccXPackProvider),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "xpack_watcher_history_cleaner_service_enabled", ""),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchClusterSettings_diskWatermarks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchClusterSettingsDiskWatermarks("90%", "85%", "95%"),
+				ExpectError: regexp.MustCompile("cluster.routing.allocation.disk.watermark.high \\[85%\\] must be at a disk usage at least as high as cluster.routing.allocation.disk.watermark.low \\[90%\\]"),
+			},
+			{
+				Config:      testAccElasticsearchClusterSettingsDiskWatermarks("80%", "10gb", "5gb"),
+				ExpectError: regexp.MustCompile("must both be percentages or ratios, or both be byte values"),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsDiskWatermarks("80%", "0.88", "93%"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.disk.watermark.low", "80%"),
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.disk.watermark.high", "0.88"),
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.disk.watermark.flood_stage", "93%"),
+				),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsDiskWatermarks("20gb", "10gb", "5gb"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.disk.watermark.low", "20gb"),
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.disk.watermark.flood_stage", "5gb"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchClusterSettings_awarenessAttributes(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the attribute is set on no node, it is only a warning
+				Config: testAccElasticsearchClusterSettingsAwarenessAttributes,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.awareness.attributes", "terraform_test_zone"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "cluster_routing_allocation_awareness_attributes", "terraform_test_zone"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchClusterSettings_awarenessForce(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchClusterSettingsAwarenessForce("terraform_test_rack"),
+				ExpectError: regexp.MustCompile(`the forced awareness attribute "terraform_test_rack" must be one of the cluster_routing_allocation_awareness_attributes`),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsAwarenessForce("terraform_test_zone"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.awareness.force.terraform_test_zone.values", "[zone-a zone-b]"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "cluster_routing_allocation_awareness_force.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("elasticsearch_cluster_settings.test", "cluster_routing_allocation_awareness_force.*", map[string]string{
+						"attribute": "terraform_test_zone",
+						"values.#":  "2",
+					}),
+				),
+			},
+			{
+				// the forced awareness is disabled when the block is removed
+				Config: testAccElasticsearchClusterSettingsAwarenessAttributes,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsMissing("cluster.routing.allocation.awareness.force.terraform_test_zone.values"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "cluster_routing_allocation_awareness_force.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchClusterSettings_recovery(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchClusterSettingsRecovery("100 MB/s", 4),
+				ExpectError: regexp.MustCompile("must be a byte value"),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsRecovery("100mb", 4),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("indices.recovery.max_bytes_per_sec", "100mb"),
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.node_concurrent_recoveries", "4"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "cluster_routing_allocation_node_concurrent_recoveries", "4"),
+				),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsRecovery("0", 2),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("indices.recovery.max_bytes_per_sec", "0"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "indices_recovery_max_bytes_per_sec", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchClusterSettings_json(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchClusterSettingsJSON(`{"cluster.routing.allocation.enable": "none"}`),
+				ExpectError: regexp.MustCompile("must be set with its argument cluster_routing_allocation_enable"),
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsJSON(`{"cluster": {"max_shards_per_node": 2000}, "action.auto_create_index": "true"}`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.max_shards_per_node", "2000"),
+					testCheckElasticsearchClusterSettingsExists("action.auto_create_index", "true"),
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.cluster_concurrent_rebalance", "2"),
+					resource.TestCheckResourceAttr("elasticsearch_cluster_settings.test", "persistent", `{"action.auto_create_index":"true","cluster.max_shards_per_node":"2000"}`),
+				),
+			},
+			{
+				// an out-of-band change of a managed key is shown in the plan
+				PreConfig: func() {
+					err := resourceElasticsearchPutClusterSettings(map[string]interface{}{
+						"cluster.max_shards_per_node": 3000,
+					}, nil, testAccProvider.Meta())
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccElasticsearchClusterSettingsJSON(`{"cluster": {"max_shards_per_node": 2000}, "action.auto_create_index": "true"}`),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccElasticsearchClusterSettingsJSON(`{"cluster.max_shards_per_node": 2000}`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.max_shards_per_node", "2000"),
+					testCheckElasticsearchClusterSettingsMissing("action.auto_create_index"),
+				),
+			},
+		},
+	})
+}
+
+func TestClusterSettingsJSONChanges(t *testing.T) {
+	changes := make(map[string]interface{})
+	err := clusterSettingsJSONChanges(
+		`{"cluster": {"max_shards_per_node": 2000}, "action.auto_create_index": true}`,
+		`{"cluster.max_shards_per_node": 3000, "cluster.routing.allocation.exclude._ip": ["10.0.0.1"]}`,
+		changes,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"action.auto_create_index":               nil,
+		"cluster.max_shards_per_node":            "3000",
+		"cluster.routing.allocation.exclude._ip": []interface{}{"10.0.0.1"},
+	}
+	if fmt.Sprint(changes) != fmt.Sprint(expected) {
+		t.Errorf("expected the changes %v, got %v", expected, changes)
+	}
+
+	if normalized := normalizeClusterSettingsJSON(`{"cluster": {"max_shards_per_node": 2000}}`); normalized != `{"cluster.max_shards_per_node":"2000"}` {
+		t.Errorf("expected the flat settings, got %s", normalized)
+	}
+	if _, errs := validateClusterSettingsJSON(`["cluster"]`, "persistent"); len(errs) != 1 {
+		t.Errorf("expected a JSON array to be invalid, got %v", errs)
+	}
+}
+
+func TestAccElasticsearchClusterSettings_safeDestroy(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsSafeDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchClusterSettingsSafeDestroy,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.allocation.enable", "primaries"),
+					testCheckElasticsearchClusterSettingsExists("cluster.routing.rebalance.enable", "none"),
+					testCheckElasticsearchClusterSettingsExists("cluster.max_shards_per_node", "2000"),
+				),
+			},
+		},
+	})
+}
+
+func TestClusterSettingsSafeResets(t *testing.T) {
+	resets := map[string]interface{}{
+		"cluster.routing.allocation.enable": nil,
+		"cluster.max_shards_per_node":       nil,
+	}
+
+	safe := clusterSettingsSafeResets(resets)
+	if fmt.Sprint(safe) != "map[cluster.routing.allocation.enable:all]" {
+		t.Errorf("expected the allocation to be enabled, got %v", safe)
+	}
+	if fmt.Sprint(resets) != "map[cluster.max_shards_per_node:<nil>]" {
+		t.Errorf("expected only the other settings to be reset, got %v", resets)
+	}
+}
+
+func TestMissingAwarenessAttributes(t *testing.T) {
+	nodesAttributes := []map[string]string{
+		{"zone": "zone-a", "xpack.installed": "true"},
+		{"zone": "zone-b", "rack": "rack-1"},
+	}
+
+	missing := missingAwarenessAttributes("zone, rack,zones", nodesAttributes)
+	if fmt.Sprint(missing) != "[zones]" {
+		t.Errorf("expected the missing attributes [zones], got %v", missing)
+	}
+	if missing := missingAwarenessAttributes("zone", nil); fmt.Sprint(missing) != "[zone]" {
+		t.Errorf("expected the missing attributes [zone] without nodes, got %v", missing)
+	}
+}
+
+func TestFlattenClusterSettingsAwarenessForce(t *testing.T) {
+	forces := flattenClusterSettingsAwarenessForce(map[string]interface{}{
+		"cluster.routing.allocation.awareness.attributes":        "zone,rack",
+		"cluster.routing.allocation.awareness.force.zone.values": []interface{}{"zone-a", "zone-b"},
+		"cluster.routing.allocation.awareness.force.rack.values": "rack-1, rack-2",
+	}, nil)
+	if len(forces) != 2 {
+		t.Fatalf("expected 2 forced attributes, got %v", forces)
+	}
+	for _, raw := range forces {
+		force := raw.(map[string]interface{})
+		expected := map[string]string{
+			"zone": "[zone-a zone-b]",
+			"rack": "[rack-1 rack-2]",
+		}[force["attribute"].(string)]
+		if fmt.Sprint(force["values"]) != expected {
+			t.Errorf("expected the values %s for the attribute %s, got %v", expected, force["attribute"], force["values"])
+		}
+	}
+
+	// the attributes forced outside of Terraform aren't tracked
+	forces = flattenClusterSettingsAwarenessForce(map[string]interface{}{
+		"cluster.routing.allocation.awareness.force.zone.values": []interface{}{"zone-a", "zone-b"},
+		"cluster.routing.allocation.awareness.force.rack.values": "rack-1, rack-2",
+	}, map[string]bool{"zone": true})
+	if len(forces) != 1 || forces[0].(map[string]interface{})["attribute"] != "zone" {
+		t.Errorf("expected only the forced zone attribute, got %v", forces)
+	}
+}
+
+func TestParseDiskWatermark(t *testing.T) {
+	for watermark, expected := range map[string]struct {
+		value float64
+		bytes bool
+	}{
+		"85%":    {85, false},
+		"92.5%":  {92.5, false},
+		"0.85":   {85, false},
+		"1":      {100, false},
+		"500mb":  {500 << 20, true},
+		"1.5gb":  {1.5 * (1 << 30), true},
+		"1024kb": {1 << 20, true},
+	} {
+		value, bytes, err := parseDiskWatermark(watermark)
+		if err != nil {
+			t.Errorf("expected %q to be valid, got %s", watermark, err)
+			continue
+		}
+		if value != expected.value || bytes != expected.bytes {
+			t.Errorf("expected %q to be parsed as %v (bytes: %t), got %v (bytes: %t)", watermark, expected.value, expected.bytes, value, bytes)
+		}
+	}
+
+	for _, watermark := range []string{"", "85", "101%", "1.5", "500", "500mib", "-1gb", "%"} {
+		if _, _, err := parseDiskWatermark(watermark); err == nil {
+			t.Errorf("expected %q to be invalid", watermark)
+		}
+	}
+}
+
+func TestAccElasticsearchClusterSettings_importBasic(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchClusterSettingsDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchClusterSettingsAllocationDisabled,
+			},
+			{
+				ResourceName:      "elasticsearch_cluster_settings.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"wait_for_green",
+					"validate_awareness_attributes",
+					"safe_destroy",
+				},
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchClusterSettingsExists(key string, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		settings, err := testGetElasticsearchClusterSettings(testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if value, ok := settings[key]; !ok || fmt.Sprint(value) != expected {
+			return fmt.Errorf("expected cluster setting %s to be %q, got %v", key, expected, value)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchClusterSettingsMissing(key string) resource.TestCheckFunc {
+	return testCheckElasticsearchClusterSettingsMissingWithProvider(key, testAccProvider)
+}
+
+func testCheckElasticsearchClusterSettingsMissingWithProvider(key string, provider *schema.Provider) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		settings, err := testGetElasticsearchClusterSettings(provider.Meta())
+		if err != nil {
+			return err
+		}
+
+		if value, ok := settings[key]; ok {
+			return fmt.Errorf("expected cluster setting %s to be reset, got %v", key, value)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchClusterSettingsDestroy(s *terraform.State) error {
+	return testCheckElasticsearchClusterSettingsDestroyWithMeta(s, testAccProvider.Meta())
+}
+
+func testCheckElasticsearchClusterSettingsDestroyWithMeta(s *terraform.State, meta interface{}) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_cluster_settings" {
+			continue
+		}
+
+		settings, err := testGetElasticsearchClusterSettings(meta)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range clusterSettingsKeys {
+			if value, ok := settings[key]; ok {
+				return fmt.Errorf("cluster setting %s still set to %v", key, value)
+			}
+		}
+		if forces := flattenClusterSettingsAwarenessForce(settings, nil); len(forces) > 0 {
+			return fmt.Errorf("forced awareness still set to %v", forces)
+		}
+	}
+
+	return nil
+}
+
+// the safe defaults are left set, they are reset once checked so the other
+// tests find the cluster without managed settings
+func testCheckElasticsearchClusterSettingsSafeDestroy(s *terraform.State) error {
+	meta := testAccProvider.Meta()
+	settings, err := testGetElasticsearchClusterSettings(meta)
+	if err != nil {
+		return err
+	}
+
+	for key, expected := range map[string]string{
+		"cluster.routing.allocation.enable": "all",
+		"cluster.routing.rebalance.enable":  "all",
+	} {
+		if value, ok := settings[key]; !ok || fmt.Sprint(value) != expected {
+			return fmt.Errorf("expected cluster setting %s to be reset to %q, got %v", key, expected, value)
+		}
+	}
+	if value, ok := settings["cluster.max_shards_per_node"]; ok {
+		return fmt.Errorf("expected cluster setting cluster.max_shards_per_node to be reset, got %v", value)
+	}
+
+	return resourceElasticsearchPutClusterSettings(map[string]interface{}{
+		"cluster.routing.allocation.enable": nil,
+		"cluster.routing.rebalance.enable":  nil,
+	}, nil, meta)
+}
+
+func testGetElasticsearchClusterSettings(meta interface{}) (map[string]interface{}, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	var settings, transient map[string]interface{}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		settings, transient, err = elastic7GetClusterSettings(client)
+	case *elastic6.Client:
+		settings, transient, err = elastic6GetClusterSettings(client)
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// the checked keys are either persistent or transient
+	for key, value := range transient {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+var testAccElasticsearchClusterSettingsAllocationDisabled = `
+resource "elasticsearch_cluster_settings" "test" {
+  cluster_routing_allocation_enable = "primaries"
+}
+`
+
+var testAccElasticsearchClusterSettingsAllocationEnabled = `
+resource "elasticsearch_cluster_settings" "test" {
+  cluster_routing_allocation_enable = "all"
+  wait_for_green                    = true
+}
+`
+
+var testAccElasticsearchClusterSettingsWatcherStopped = `
+resource "elasticsearch_cluster_settings" "test" {
+  xpack_watcher_history_cleaner_service_enabled = false
+  watcher_state                                 = "stopped"
+}
+`
+
+var testAccElasticsearchClusterSettingsWatcherStarted = `
+resource "elasticsearch_cluster_settings" "test" {
+  xpack_watcher_history_cleaner_service_enabled = true
+  watcher_state                                 = "started"
+}
+`
+
+var testAccElasticsearchClusterSettingsWatcherStartedDefaults = `
+resource "elasticsearch_cluster_settings" "test" {
+  watcher_state = "started"
+}
+`
+
+var testAccElasticsearchClusterSettingsAwarenessAttributes = `
+resource "elasticsearch_cluster_settings" "test" {
+  cluster_routing_allocation_awareness_attributes = "terraform_test_zone"
+  validate_awareness_attributes                   = true
+}
+`
+
+func testAccElasticsearchClusterSettingsAwarenessForce(attribute string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_cluster_settings" "test" {
+  cluster_routing_allocation_awareness_attributes = "terraform_test_zone"
+
+  cluster_routing_allocation_awareness_force {
+    attribute = "%s"
+    values    = ["zone-a", "zone-b"]
+  }
+}
+`, attribute)
+}
+
+func testAccElasticsearchClusterSettingsDiskWatermarks(low string, high string, floodStage string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_cluster_settings" "test" {
+  cluster_routing_allocation_disk_watermark_low         = "%s"
+  cluster_routing_allocation_disk_watermark_high        = "%s"
+  cluster_routing_allocation_disk_watermark_flood_stage = "%s"
+}
+`, low, high, floodStage)
+}
+
+func testAccElasticsearchClusterSettingsRecovery(maxBytesPerSec string, concurrentRecoveries int) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_cluster_settings" "test" {
+  indices_recovery_max_bytes_per_sec                    = "%s"
+  cluster_routing_allocation_node_concurrent_recoveries = %d
+}
+`, maxBytesPerSec, concurrentRecoveries)
+}
+
+var testAccElasticsearchClusterSettingsSafeDestroy = `
+resource "elasticsearch_cluster_settings" "test" {
+  cluster_routing_allocation_enable = "primaries"
+  safe_destroy                      = true
+
+  persistent = jsonencode({
+    "cluster.routing.rebalance.enable" = "none"
+    "cluster.max_shards_per_node"      = 2000
+  })
+}
+`
+
+func testAccElasticsearchClusterSettingsJSON(persistent string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_cluster_settings" "test" {
+  persistent = <<EOF
+%s
+EOF
+
+  transient = jsonencode({
+    "cluster.routing.allocation.cluster_concurrent_rebalance" = 2
+  })
+}
+`, persistent)
+}
+
+func TestClusterSettingsAwarenessForceValuesValidation(t *testing.T) {
+	force := resourceElasticsearchClusterSettings().Schema["cluster_routing_allocation_awareness_force"].Elem.(*schema.Resource)
+	validate := force.Schema["values"].Elem.(*schema.Schema).ValidateFunc
+
+	for _, value := range []string{"", " ", "zone-a,zone-b"} {
+		if _, errs := validate(value, "values"); len(errs) == 0 {
+			t.Errorf("Expected the value %q to be rejected", value)
+		}
+	}
+	if _, errs := validate("zone-a", "values"); len(errs) != 0 {
+		t.Errorf("Expected the value to be valid, got %v", errs)
+	}
+}
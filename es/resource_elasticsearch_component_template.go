@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"sort"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
@@ -32,7 +35,7 @@ func resourceElasticsearchComponentTemplate() *schema.Resource {
 				Required:         true,
 				DiffSuppressFunc: diffSuppressComponentTemplate,
 				ValidateFunc:     validation.StringIsJSON,
-				Description:      "The JSON body of the template.",
+				Description:      "The JSON body of the template: its `template` with the `settings`, `mappings` and `aliases`, and the optional `_meta` and `version`.",
 			},
 		},
 		Importer: &schema.ResourceImporter{
@@ -64,7 +67,7 @@ func resourceElasticsearchComponentTemplateRead(d *schema.ResourceData, meta int
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = elastic7GetVersion(client)
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
 		if err == nil {
 			if elasticVersion.LessThan(componentTemplateMinimalVersion) {
 				err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
@@ -77,7 +80,7 @@ func resourceElasticsearchComponentTemplateRead(d *schema.ResourceData, meta int
 	}
 	if err != nil {
 		if elastic7.IsNotFound(err) {
-			log.Printf("[WARN] Index template (%s) not found, removing from state", id)
+			log.Printf("[WARN] Component template (%s) not found, removing from state", id)
 			d.SetId("")
 			return nil
 		}
@@ -91,20 +94,45 @@ func resourceElasticsearchComponentTemplateRead(d *schema.ResourceData, meta int
 	return ds.err
 }
 
+// elastic7GetComponentTemplate returns the normalized JSON of the component
+// template, the typed olivere response drops its `_meta`
 func elastic7GetComponentTemplate(client *elastic7.Client, id string) (string, error) {
-	res, err := client.IndexGetComponentTemplate(id).Do(context.TODO())
+	path, err := uritemplates.Expand("/_component_template/{name}", map[string]string{
+		"name": id,
+	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("error building URL path for component template: %+v", err)
 	}
 
-	// No more than 1 element is expected, if the index template is not found, previous call should
-	// return a 404 error
-	t := res.ComponentTemplates[0].ComponentTemplate
-	tj, err := json.Marshal(t)
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
 	if err != nil {
 		return "", err
 	}
-	return string(tj), nil
+
+	var response struct {
+		ComponentTemplates []struct {
+			Name              string                 `json:"name"`
+			ComponentTemplate map[string]interface{} `json:"component_template"`
+		} `json:"component_templates"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling component template body: %+v: %+v", err, res.Body)
+	}
+
+	for _, t := range response.ComponentTemplates {
+		if t.Name != id {
+			continue
+		}
+		tj, err := json.Marshal(t.ComponentTemplate)
+		if err != nil {
+			return "", err
+		}
+		return string(tj), nil
+	}
+	return "", &elastic7.Error{Status: http.StatusNotFound}
 }
 
 func resourceElasticsearchComponentTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -123,12 +151,12 @@ func resourceElasticsearchComponentTemplateDelete(d *schema.ResourceData, meta i
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = elastic7GetVersion(client)
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
 		if err == nil {
 			if elasticVersion.LessThan(componentTemplateMinimalVersion) {
 				err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
 			} else {
-				err = elastic7DeleteComponentTemplate(client, id)
+				err = elastic7DeleteComponentTemplateIfUnused(client, id)
 			}
 		}
 	default:
@@ -142,6 +170,58 @@ func resourceElasticsearchComponentTemplateDelete(d *schema.ResourceData, meta i
 	return nil
 }
 
+// elastic7DeleteComponentTemplateIfUnused names the index templates using the
+// component template, Elasticsearch refuses to delete it in that case so
+// there is no way to force the deletion
+func elastic7DeleteComponentTemplateIfUnused(client *elastic7.Client, id string) error {
+	templates, err := elastic7GetIndexTemplatesComposedOf(client, id)
+	if err != nil {
+		return err
+	}
+	if len(templates) > 0 {
+		return fmt.Errorf("Component template %s is still used by index templates %v, remove it from their composed_of before destroying it.", id, templates)
+	}
+
+	return elastic7DeleteComponentTemplate(client, id)
+}
+
+// elastic7GetIndexTemplatesComposedOf returns the sorted names of the
+// composable index templates which are composed of the component template
+func elastic7GetIndexTemplatesComposedOf(client *elastic7.Client, id string) ([]string, error) {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_index_template",
+	})
+	if err != nil {
+		// no index templates at all
+		if elastic7.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var response elastic7.IndicesGetIndexTemplateResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling index templates body: %+v: %+v", err, res.Body)
+	}
+
+	var templates []string
+	for _, template := range response.IndexTemplates {
+		if template.IndexTemplate == nil {
+			continue
+		}
+		for _, component := range template.IndexTemplate.ComposedOf {
+			if component == id {
+				templates = append(templates, template.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(templates)
+
+	return templates, nil
+}
+
 func elastic7DeleteComponentTemplate(client *elastic7.Client, id string) error {
 	_, err := client.IndexDeleteComponentTemplate(id).Do(context.TODO())
 	return err
@@ -160,7 +240,7 @@ func resourceElasticsearchPutComponentTemplate(d *schema.ResourceData, meta inte
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = elastic7GetVersion(client)
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
 		if err == nil {
 			if elasticVersion.LessThan(componentTemplateMinimalVersion) {
 				err = fmt.Errorf("component_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -94,6 +95,54 @@ func TestAccElasticsearchComponentTemplate_importBasic(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchComponentTemplate_usedByIndexTemplate(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("/_component_template endpoint only supported on ES >= 7.8")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComponentTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchComponentTemplateUsedByIndexTemplate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComponentTemplateExists("elasticsearch_component_template.test"),
+				),
+			},
+			{
+				Config:      testAccElasticsearchComponentTemplateIndexTemplateOnly,
+				ExpectError: regexp.MustCompile(`Component template terraform-test is still used by index templates \[terraform-test-composed\]`),
+			},
+			{
+				// restore the dependency so both are destroyed in order
+				Config: testAccElasticsearchComponentTemplateUsedByIndexTemplate,
+			},
+		},
+	})
+}
+
 func testCheckElasticsearchComponentTemplateExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -156,6 +205,27 @@ func testCheckElasticsearchComponentTemplateDestroy(s *terraform.State) error {
 	return nil
 }
 
+func TestDiffSuppressComponentTemplate(t *testing.T) {
+	old := `{"template":{"settings":{"index":{"number_of_shards":"1"}}},"_meta":{"owner":"terraform"},"version":3}`
+	for _, body := range []string{
+		`{"_meta":{"owner":"terraform"},"template":{"settings":{"number_of_shards":1}},"version":3}`,
+		`{"template":{"settings":{"index.number_of_shards":1}},"_meta":{"owner":"terraform"}}`,
+	} {
+		if !diffSuppressComponentTemplate("body", old, body, nil) {
+			t.Errorf("expected %s to be equivalent to %s", body, old)
+		}
+	}
+
+	for _, body := range []string{
+		`{"template":{"settings":{"number_of_shards":2}},"_meta":{"owner":"terraform"}}`,
+		`{"template":{"settings":{"number_of_shards":1}}}`,
+	} {
+		if diffSuppressComponentTemplate("body", old, body, nil) {
+			t.Errorf("expected %s to differ from %s", body, old)
+		}
+	}
+}
+
 var testAccElasticsearchComponentTemplate = `
 resource "elasticsearch_component_template" "test" {
   name = "terraform-test"
@@ -181,8 +251,52 @@ resource "elasticsearch_component_template" "test" {
     "aliases": {
       "mydata": { }
     }
+  },
+  "_meta": {
+    "owner": "terraform"
   }
 }
 EOF
 }
 `
+
+var testAccElasticsearchComponentTemplateUsedByIndexTemplate = `
+resource "elasticsearch_component_template" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "template": {
+    "settings": {
+      "index": {
+        "number_of_shards": 1
+      }
+    }
+  }
+}
+EOF
+}
+
+resource "elasticsearch_composable_index_template" "test" {
+  name = "terraform-test-composed"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-composed-*"],
+  "composed_of": ["terraform-test"]
+}
+EOF
+
+  depends_on = [elasticsearch_component_template.test]
+}
+`
+
+var testAccElasticsearchComponentTemplateIndexTemplateOnly = `
+resource "elasticsearch_composable_index_template" "test" {
+  name = "terraform-test-composed"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-composed-*"],
+  "composed_of": ["terraform-test"]
+}
+EOF
+}
+`
@@ -5,34 +5,43 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
 var minimalESComposableTemplateVersion, _ = version.NewVersion("7.8.0")
 
 func resourceElasticsearchComposableIndexTemplate() *schema.Resource {
+	templateSchema := map[string]*schema.Schema{
+		"name": {
+			Type:     schema.TypeString,
+			ForceNew: true,
+			Required: true,
+		},
+		"body": {
+			Type:             schema.TypeString,
+			Required:         true,
+			DiffSuppressFunc: diffSuppressComposableIndexTemplate,
+			ValidateFunc:     validation.StringIsJSON,
+		},
+	}
+	// the typed index settings are merged in the settings of the body
+	for name, s := range indexTemplateSettingsSchema() {
+		templateSchema[name] = s
+	}
+
 	return &schema.Resource{
 		Create: resourceElasticsearchComposableIndexTemplateCreate,
 		Read:   resourceElasticsearchComposableIndexTemplateRead,
 		Update: resourceElasticsearchComposableIndexTemplateUpdate,
 		Delete: resourceElasticsearchComposableIndexTemplateDelete,
-		Schema: map[string]*schema.Schema{
-			"name": {
-				Type:     schema.TypeString,
-				ForceNew: true,
-				Required: true,
-			},
-			"body": {
-				Type:             schema.TypeString,
-				Required:         true,
-				DiffSuppressFunc: diffSuppressComposableIndexTemplate,
-				ValidateFunc:     validation.StringIsJSON,
-			},
-		},
+		Schema: templateSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -61,7 +70,7 @@ func resourceElasticsearchComposableIndexTemplateRead(d *schema.ResourceData, me
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = elastic7GetVersion(client)
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
 		if err == nil {
 			if elasticVersion.LessThan(minimalESComposableTemplateVersion) {
 				err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
@@ -82,26 +91,66 @@ func resourceElasticsearchComposableIndexTemplateRead(d *schema.ResourceData, me
 		return err
 	}
 
+	// only the settings managed by attributes are removed from the body
+	var managedKeys []string
+	for key := range settingsFromIndexResourceData(d, indexTemplateSettingsKeys) {
+		managedKeys = append(managedKeys, key)
+	}
+	result, settings, err := composableIndexTemplateBodyWithoutSettings(result, managedKeys)
+	if err != nil {
+		return err
+	}
+
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
-	return ds.err
+	if ds.err != nil {
+		return ds.err
+	}
+	indexResourceDataFromSettings(settings, d, managedKeys)
+	return nil
 }
 
+// elastic7GetIndexTemplate returns the normalized JSON of the index template,
+// the typed olivere response drops e.g. `data_stream`, `_meta` and
+// `allow_auto_create`
 func elastic7GetIndexTemplate(client *elastic7.Client, id string) (string, error) {
-	res, err := client.IndexGetIndexTemplate(id).Do(context.TODO())
+	path, err := uritemplates.Expand("/_index_template/{name}", map[string]string{
+		"name": id,
+	})
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("error building URL path for index template: %+v", err)
 	}
 
-	// No more than 1 element is expected, if the index template is not found, previous call should
-	// return a 404 error
-	t := res.IndexTemplates[0].IndexTemplate
-	tj, err := json.Marshal(t)
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
 	if err != nil {
 		return "", err
 	}
-	return string(tj), nil
+
+	var response struct {
+		IndexTemplates []struct {
+			Name          string                 `json:"name"`
+			IndexTemplate map[string]interface{} `json:"index_template"`
+		} `json:"index_templates"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling index template body: %+v: %+v", err, res.Body)
+	}
+
+	for _, t := range response.IndexTemplates {
+		if t.Name != id {
+			continue
+		}
+		tj, err := json.Marshal(t.IndexTemplate)
+		if err != nil {
+			return "", err
+		}
+		return string(tj), nil
+	}
+	return "", &elastic7.Error{Status: http.StatusNotFound}
 }
 
 func resourceElasticsearchComposableIndexTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -120,7 +169,7 @@ func resourceElasticsearchComposableIndexTemplateDelete(d *schema.ResourceData,
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = elastic7GetVersion(client)
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
 		if err == nil {
 			if elasticVersion.LessThan(minimalESComposableTemplateVersion) {
 				err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
@@ -146,7 +195,17 @@ func elastic7DeleteIndexTemplate(client *elastic7.Client, id string) error {
 
 func resourceElasticsearchPutComposableIndexTemplate(d *schema.ResourceData, meta interface{}, create bool) error {
 	name := d.Get("name").(string)
-	body := d.Get("body").(string)
+	body, err := composableIndexTemplateBodyWithSettings(d.Get("body").(string), settingsFromIndexResourceData(d, indexTemplateSettingsKeys))
+	if err != nil {
+		return err
+	}
+
+	if mode, ok := d.GetOk("mode"); ok && mode.(string) != "standard" {
+		err = checkIndexMode(mode.(string), meta)
+		if err != nil {
+			return err
+		}
+	}
 
 	var elasticVersion *version.Version
 
@@ -157,7 +216,7 @@ func resourceElasticsearchPutComposableIndexTemplate(d *schema.ResourceData, met
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		elasticVersion, err = elastic7GetVersion(client)
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
 		if err == nil {
 			if elasticVersion.LessThan(minimalESComposableTemplateVersion) {
 				err = fmt.Errorf("index_template endpoint only available from ElasticSearch >= 7.8, got version %s", elasticVersion.String())
@@ -176,3 +235,96 @@ func elastic7PutIndexTemplate(client *elastic7.Client, name string, body string,
 	_, err := client.IndexPutIndexTemplate(name).BodyString(body).Create(create).Do(context.TODO())
 	return err
 }
+
+// composableIndexTemplateBodyWithSettings merges the typed index settings in
+// the settings of the template body
+func composableIndexTemplateBodyWithSettings(body string, settings map[string]interface{}) (string, error) {
+	if len(settings) == 0 {
+		return body, nil
+	}
+
+	var tpl map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &tpl); err != nil {
+		return "", fmt.Errorf("fail to unmarshal: %v", err)
+	}
+
+	innerTpl, ok := tpl["template"].(map[string]interface{})
+	if !ok {
+		innerTpl = make(map[string]interface{})
+		tpl["template"] = innerTpl
+	}
+
+	bodySettings := composableIndexTemplateFlatSettings(innerTpl)
+	for key, value := range settings {
+		if _, ok := bodySettings["index."+key]; ok {
+			return "", fmt.Errorf("index setting %s is set both in the body and as %s", key, strings.Replace(key, ".", "_", -1))
+		}
+		bodySettings["index."+key] = value
+	}
+	innerTpl["settings"] = unflattenMap(bodySettings)
+
+	b, err := json.Marshal(tpl)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// composableIndexTemplateBodyWithoutSettings is the inverse of
+// composableIndexTemplateBodyWithSettings, it returns the body without the
+// settings of the keys and these settings
+func composableIndexTemplateBodyWithoutSettings(body string, keys []string) (string, map[string]interface{}, error) {
+	settings := make(map[string]interface{})
+	if len(keys) == 0 {
+		return body, settings, nil
+	}
+
+	var tpl map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &tpl); err != nil {
+		return "", nil, fmt.Errorf("fail to unmarshal: %v", err)
+	}
+
+	innerTpl, ok := tpl["template"].(map[string]interface{})
+	if !ok {
+		return body, settings, nil
+	}
+
+	bodySettings := composableIndexTemplateFlatSettings(innerTpl)
+	for _, key := range keys {
+		if value, ok := bodySettings["index."+key]; ok {
+			settings[key] = value
+			delete(bodySettings, "index."+key)
+		}
+	}
+	if len(bodySettings) > 0 {
+		innerTpl["settings"] = unflattenMap(bodySettings)
+	} else {
+		delete(innerTpl, "settings")
+	}
+	if len(innerTpl) == 0 {
+		delete(tpl, "template")
+	}
+
+	b, err := json.Marshal(tpl)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(b), settings, nil
+}
+
+// composableIndexTemplateFlatSettings returns the flattened settings of the
+// template, prefixed with `index.`
+func composableIndexTemplateFlatSettings(innerTpl map[string]interface{}) map[string]interface{} {
+	flat := make(map[string]interface{})
+	settings, ok := innerTpl["settings"].(map[string]interface{})
+	if !ok {
+		return flat
+	}
+	for key, value := range flattenMap(settings) {
+		if !strings.HasPrefix(key, "index.") {
+			key = "index." + key
+		}
+		flat[key] = value
+	}
+	return flat
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -94,6 +96,191 @@ func TestAccElasticsearchComposableIndexTemplate_importBasic(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchComposableIndexTemplate_typedSettings(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("/_index_template endpoint only supported on ES >= 7.8")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComposableIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchComposableIndexTemplateTypedSettings(`"index": { "codec": "default" }`, "best_compression"),
+				ExpectError: regexp.MustCompile("index setting codec is set both in the body and as codec"),
+			},
+			{
+				Config: testAccElasticsearchComposableIndexTemplateTypedSettings(`"number_of_shards": 1`, "best_compression"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+					testCheckElasticsearchComposableIndexTemplateSetting("elasticsearch_composable_index_template.test", "codec", "best_compression"),
+					testCheckElasticsearchComposableIndexTemplateSetting("elasticsearch_composable_index_template.test", "sort.field", "[@timestamp]"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "codec", "best_compression"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "sort_order.0", "desc"),
+				),
+			},
+			{
+				// templates are updated in place
+				Config: testAccElasticsearchComposableIndexTemplateTypedSettings(`"number_of_shards": 1`, "default"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateSetting("elasticsearch_composable_index_template.test", "codec", "default"),
+					resource.TestCheckResourceAttr("elasticsearch_composable_index_template.test", "codec", "default"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchComposableIndexTemplate_dataStream(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(dataStreamMinimalVersion) {
+				t.Skip("Data streams only supported on ES >= 7.9")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchComposableIndexTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the data stream and the metadata are read back, without diff
+				Config: testAccElasticsearchComposableIndexTemplateDataStream,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchComposableIndexTemplateExists("elasticsearch_composable_index_template.test"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_composable_index_template.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestDiffSuppressComposableIndexTemplate(t *testing.T) {
+	old := `{"index_patterns":["logs-*"],"data_stream":{"hidden":false,"allow_custom_routing":false},"_meta":{"owner":"ops"},"priority":200}`
+	for _, body := range []string{
+		`{"priority":200,"_meta":{"owner":"ops"},"data_stream":{},"index_patterns":["logs-*"]}`,
+		`{"index_patterns":["logs-*"],"data_stream":{"hidden":false},"_meta":{"owner":"ops"},"priority":200,"version":2}`,
+	} {
+		if !diffSuppressComposableIndexTemplate("body", old, body, nil) {
+			t.Errorf("expected %s to be equivalent to %s", body, old)
+		}
+	}
+
+	for _, body := range []string{
+		`{"index_patterns":["logs-*"],"_meta":{"owner":"ops"},"priority":200}`,
+		`{"index_patterns":["logs-*"],"data_stream":{"hidden":true},"_meta":{"owner":"ops"},"priority":200}`,
+		`{"index_patterns":["logs-*"],"data_stream":{},"priority":200}`,
+	} {
+		if diffSuppressComposableIndexTemplate("body", old, body, nil) {
+			t.Errorf("expected %s to differ from %s", body, old)
+		}
+	}
+}
+
+func TestComposableIndexTemplateBodyWithSettings(t *testing.T) {
+	body := `{"index_patterns":["te*"],"template":{"settings":{"number_of_shards":1}}}`
+	settings := map[string]interface{}{
+		"codec":      "best_compression",
+		"sort.field": []interface{}{"@timestamp"},
+	}
+
+	merged, err := composableIndexTemplateBodyWithSettings(body, settings)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := `{"index_patterns":["te*"],"template":{"settings":{"index":{"codec":"best_compression","number_of_shards":1,"sort":{"field":["@timestamp"]}}}}}`
+	if merged != expected {
+		t.Errorf("expected body %s, got %s", expected, merged)
+	}
+
+	stripped, read, err := composableIndexTemplateBodyWithoutSettings(merged, []string{"codec", "sort.field"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected = `{"index_patterns":["te*"],"template":{"settings":{"index":{"number_of_shards":1}}}}`
+	if stripped != expected {
+		t.Errorf("expected body %s, got %s", expected, stripped)
+	}
+	if !reflect.DeepEqual(read, settings) {
+		t.Errorf("expected settings %v, got %v", settings, read)
+	}
+
+	if _, err := composableIndexTemplateBodyWithSettings(merged, map[string]interface{}{"codec": "default"}); err == nil {
+		t.Error("expected an error for a setting set twice")
+	}
+}
+
+func testCheckElasticsearchComposableIndexTemplateSetting(name string, key string, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		var settings map[string]interface{}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			res, err := client.IndexGetIndexTemplate(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			if tpl := res.IndexTemplates[0].IndexTemplate.Template; tpl != nil {
+				settings = tpl.Settings
+			}
+		default:
+			return errors.New("/_index_template endpoint only supported on ES >= 7.8")
+		}
+
+		if value := normalizedIndexSettings(settings)["index."+key]; value != expected {
+			return fmt.Errorf("expected index template setting %s to be %q, got %v", key, expected, value)
+		}
+
+		return nil
+	}
+}
+
 func testCheckElasticsearchComposableIndexTemplateExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -189,3 +376,56 @@ resource "elasticsearch_composable_index_template" "test" {
 EOF
 }
 `
+
+var testAccElasticsearchComposableIndexTemplateDataStream = `
+resource "elasticsearch_composable_index_template" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-ds-*"],
+  "data_stream": {},
+  "template": {
+    "mappings": {
+      "properties": {
+        "@timestamp": {
+          "type": "date"
+        }
+      }
+    }
+  },
+  "priority": 200,
+  "_meta": {
+    "owner": "terraform"
+  }
+}
+EOF
+}
+`
+
+func testAccElasticsearchComposableIndexTemplateTypedSettings(settings string, codec string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_composable_index_template" "test" {
+  name       = "terraform-test"
+  codec      = "%s"
+  sort_field = ["@timestamp"]
+  sort_order = ["desc"]
+  body = <<EOF
+{
+  "index_patterns": ["te*"],
+  "template": {
+    "settings": {
+      %s
+    },
+    "mappings": {
+      "properties": {
+        "@timestamp": {
+          "type": "date"
+        }
+      }
+    }
+  }
+}
+EOF
+}
+`, codec, settings)
+}
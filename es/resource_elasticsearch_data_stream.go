@@ -0,0 +1,324 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var dataStreamMinimalVersion, _ = version.NewVersion("7.9.0")
+
+func resourceElasticsearchDataStream() *schema.Resource {
+	return &schema.Resource{
+		Description: "A data stream stores append-only time series data across multiple hidden, auto-generated backing indices. A composable index template with `data_stream` enabled and an index pattern matching the name of the data stream must exist before the data stream is created.",
+		Create:      resourceElasticsearchDataStreamCreate,
+		Read:        resourceElasticsearchDataStreamRead,
+		Delete:      resourceElasticsearchDataStreamDelete,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Name of the data stream to create.",
+			},
+			"generation": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The current generation of the data stream, incremented by each rollover.",
+			},
+			"status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The health status of the data stream: `GREEN`, `YELLOW` or `RED`.",
+			},
+			"template": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The name of the index template used to create the backing indices of the data stream.",
+			},
+			"indices": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The names of the backing indices of the data stream, the last one is the write index.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceElasticsearchDataStreamCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var elasticVersion *version.Version
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
+		if err == nil {
+			if elasticVersion.LessThan(dataStreamMinimalVersion) {
+				err = fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
+			} else {
+				err = elastic7CheckDataStreamIndexTemplate(client, name)
+				if err == nil {
+					err = elastic7PutDataStream(client, name)
+				}
+			}
+		}
+	default:
+		err = fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	d.SetId(name)
+	return resourceElasticsearchDataStreamRead(d, meta)
+}
+
+func resourceElasticsearchDataStreamRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	var dataStream DataStream
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var elasticVersion *version.Version
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
+		if err == nil {
+			if elasticVersion.LessThan(dataStreamMinimalVersion) {
+				err = fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
+			} else {
+				dataStream, err = elastic7GetDataStream(client, id)
+			}
+		}
+	default:
+		err = fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+	}
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Data stream (%s) not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+
+		return err
+	}
+
+	indices := make([]string, 0, len(dataStream.Indices))
+	for _, index := range dataStream.Indices {
+		indices = append(indices, index.IndexName)
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", dataStream.Name)
+	ds.set("generation", dataStream.Generation)
+	ds.set("status", dataStream.Status)
+	ds.set("template", dataStream.Template)
+	ds.set("indices", indices)
+	return ds.err
+}
+
+func resourceElasticsearchDataStreamDelete(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var elasticVersion *version.Version
+		elasticVersion, err = esVersionFromConf(meta.(*ProviderConf))
+		if err == nil {
+			if elasticVersion.LessThan(dataStreamMinimalVersion) {
+				err = fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
+			} else {
+				err = elastic7DeleteDataStream(client, id)
+			}
+		}
+	default:
+		err = fmt.Errorf("data_stream endpoint only available from ElasticSearch >= 7.9, got version < 7.0.0")
+	}
+
+	if err != nil {
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+// elastic7CheckDataStreamIndexTemplate checks that the composable index
+// template Elasticsearch would pick for the data stream, the matching one with
+// the highest priority, enables data streams
+func elastic7CheckDataStreamIndexTemplate(client *elastic7.Client, name string) error {
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   "/_index_template",
+	})
+	if err != nil && !elastic7.IsNotFound(err) {
+		return err
+	}
+
+	var response dataStreamIndexTemplatesResponse
+	if err == nil {
+		if err := json.Unmarshal(res.Body, &response); err != nil {
+			return fmt.Errorf("error unmarshalling index templates body: %+v: %+v", err, res.Body)
+		}
+	}
+
+	var match *dataStreamIndexTemplate
+	for i, template := range response.IndexTemplates {
+		if !indexPatternsMatch(template.IndexTemplate.IndexPatterns, name) {
+			continue
+		}
+		if match == nil || template.IndexTemplate.Priority > match.IndexTemplate.Priority {
+			match = &response.IndexTemplates[i]
+		}
+	}
+
+	if match == nil {
+		return fmt.Errorf("no composable index template matches the data stream %s, create an index template with an index pattern matching it and `\"data_stream\": {}` in its body first", name)
+	}
+	if match.IndexTemplate.DataStream == nil {
+		return fmt.Errorf("the index template %s matching the data stream %s doesn't enable data streams, add `\"data_stream\": {}` to its body", match.Name, name)
+	}
+	return nil
+}
+
+// indexPatternsMatch returns whether the name matches one of the index
+// patterns, where `*` is the only wildcard
+func indexPatternsMatch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if indexPatternMatch(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func indexPatternMatch(pattern string, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+	return strings.HasSuffix(name, parts[len(parts)-1])
+}
+
+func elastic7PutDataStream(client *elastic7.Client, name string) error {
+	path, err := uritemplates.Expand("/_data_stream/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for data stream: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+	})
+	return err
+}
+
+func elastic7GetDataStream(client *elastic7.Client, name string) (DataStream, error) {
+	path, err := uritemplates.Expand("/_data_stream/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return DataStream{}, fmt.Errorf("error building URL path for data stream: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return DataStream{}, err
+	}
+
+	var response DataStreamsResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return DataStream{}, fmt.Errorf("error unmarshalling data stream body: %+v: %+v", err, res.Body)
+	}
+	if len(response.DataStreams) != 1 {
+		return DataStream{}, fmt.Errorf("1 data stream expected with name %q, found %d", name, len(response.DataStreams))
+	}
+
+	return response.DataStreams[0], nil
+}
+
+func elastic7DeleteDataStream(client *elastic7.Client, name string) error {
+	path, err := uritemplates.Expand("/_data_stream/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for data stream: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	return err
+}
+
+type DataStreamsResponse struct {
+	DataStreams []DataStream `json:"data_streams"`
+}
+
+type DataStream struct {
+	Name       string            `json:"name"`
+	Generation int               `json:"generation"`
+	Status     string            `json:"status"`
+	Template   string            `json:"template"`
+	Indices    []DataStreamIndex `json:"indices"`
+}
+
+type DataStreamIndex struct {
+	IndexName string `json:"index_name"`
+	IndexUUID string `json:"index_uuid"`
+}
+
+// the index templates of the client don't have the data_stream attribute
+type dataStreamIndexTemplatesResponse struct {
+	IndexTemplates []dataStreamIndexTemplate `json:"index_templates"`
+}
+
+type dataStreamIndexTemplate struct {
+	Name          string `json:"name"`
+	IndexTemplate struct {
+		IndexPatterns []string               `json:"index_patterns"`
+		Priority      int                    `json:"priority"`
+		DataStream    map[string]interface{} `json:"data_stream"`
+	} `json:"index_template"`
+}
@@ -0,0 +1,189 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchDataStream(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(dataStreamMinimalVersion) {
+				t.Skip("Data streams only supported on ES >= 7.9")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchDataStreamDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchDataStream,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchDataStreamExists("elasticsearch_data_stream.test"),
+					resource.TestCheckResourceAttr("elasticsearch_data_stream.test", "generation", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_data_stream.test", "template", "terraform-test-data-stream"),
+					resource.TestCheckResourceAttr("elasticsearch_data_stream.test", "indices.#", "1"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_data_stream.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchDataStream_noTemplate(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(dataStreamMinimalVersion) {
+				t.Skip("Data streams only supported on ES >= 7.9")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchDataStreamDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchDataStreamNoTemplate,
+				ExpectError: regexp.MustCompile(`no composable index template matches the data stream terraform-test-no-template`),
+			},
+		},
+	})
+}
+
+func TestIndexPatternMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"logs", "logs", true},
+		{"logs", "logs-app", false},
+		{"logs-*", "logs-app", true},
+		{"logs-*", "metrics-app", false},
+		{"*", "logs", true},
+		{"*-app-*", "logs-app-default", true},
+		{"logs-*-default", "logs-app-default", true},
+		{"logs-*-default", "logs-app-prod", false},
+	}
+	for _, c := range cases {
+		if match := indexPatternMatch(c.pattern, c.name); match != c.match {
+			t.Errorf("indexPatternMatch(%q, %q) = %t, expected %t", c.pattern, c.name, match, c.match)
+		}
+	}
+}
+
+func testCheckElasticsearchDataStreamExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No data stream ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = elastic7GetDataStream(client, rs.Primary.ID)
+		default:
+			err = errors.New("/_data_stream endpoint only supported on ES >= 7.9")
+		}
+
+		return err
+	}
+}
+
+func testCheckElasticsearchDataStreamDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_data_stream" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = elastic7GetDataStream(client, rs.Primary.ID)
+		default:
+			err = errors.New("/_data_stream endpoint only supported on ES >= 7.9")
+		}
+
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Data stream %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+var testAccElasticsearchDataStream = `
+resource "elasticsearch_composable_index_template" "test" {
+  name = "terraform-test-data-stream"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-stream-*"],
+  "data_stream": {},
+  "priority": 200
+}
+EOF
+}
+
+resource "elasticsearch_data_stream" "test" {
+  name = "terraform-test-stream-app"
+
+  depends_on = [elasticsearch_composable_index_template.test]
+}
+`
+
+var testAccElasticsearchDataStreamNoTemplate = `
+resource "elasticsearch_data_stream" "test" {
+  name = "terraform-test-no-template"
+}
+`
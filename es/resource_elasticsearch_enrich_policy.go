@@ -0,0 +1,335 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var enrichPolicyMinimalVersion, _ = version.NewVersion("7.5.0")
+var enrichPolicyRangeMinimalVersion, _ = version.NewVersion("7.16.0")
+
+func resourceElasticsearchEnrichPolicy() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch enrich policy resource, used by the enrich processor of ingest pipelines to add the data of the source indices to the incoming documents. Enrich policies can't be updated, any change replaces the policy. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/ingest-enriching-data.html) for more details.",
+		Create:      resourceElasticsearchEnrichPolicyCreate,
+		Read:        resourceElasticsearchEnrichPolicyRead,
+		Delete:      resourceElasticsearchEnrichPolicyDelete,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Name of the enrich policy.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"policy_type": {
+				Type:         schema.TypeString,
+				Description:  "The type of the policy: `match`, `geo_match` or `range`, available from ElasticSearch >= 7.16.",
+				ForceNew:     true,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice([]string{"match", "geo_match", "range"}, false),
+			},
+			"indices": {
+				Type:        schema.TypeList,
+				Description: "The source indices used to create the enrich index.",
+				ForceNew:    true,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"match_field": {
+				Type:        schema.TypeString,
+				Description: "The field of the source indices used to match the incoming documents.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"enrich_fields": {
+				Type:        schema.TypeList,
+				Description: "The fields of the source indices added to the matching incoming documents.",
+				ForceNew:    true,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"query": {
+				Type:             schema.TypeString,
+				Description:      "A JSON string of a query filtering the documents of the source indices used to create the enrich index.",
+				ForceNew:         true,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+			},
+			"force_execute": {
+				Type:        schema.TypeBool,
+				Description: "Whether to execute the policy after its creation, creating the enrich index used by the enrich processors. Otherwise the policy must be executed before it is used.",
+				ForceNew:    true,
+				Optional:    true,
+				Default:     false,
+			},
+			"executed": {
+				Type:        schema.TypeBool,
+				Description: "Whether the policy has been executed, i.e. an enrich index of the policy exists.",
+				Computed:    true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceElasticsearchEnrichPolicyCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+	policyType := d.Get("policy_type").(string)
+
+	esClient, err := resourceElasticsearchEnrichPolicyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	if policyType == "range" {
+		elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		if elasticVersion.LessThan(enrichPolicyRangeMinimalVersion) {
+			return fmt.Errorf("enrich policy type range only available from ElasticSearch >= 7.16, got version %s", elasticVersion.String())
+		}
+	}
+
+	policy := EnrichPolicy{
+		Indices:      expandStringList(d.Get("indices").([]interface{})),
+		MatchField:   d.Get("match_field").(string),
+		EnrichFields: expandStringList(d.Get("enrich_fields").([]interface{})),
+	}
+	if query, ok := d.GetOk("query"); ok {
+		policy.Query = optionalInterfaceJson(query.(string))
+	}
+
+	err = elastic7PutEnrichPolicy(esClient, name, policyType, policy)
+	if err != nil {
+		return err
+	}
+	d.SetId(name)
+
+	if d.Get("force_execute").(bool) {
+		err = elastic7ExecuteEnrichPolicy(esClient, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchEnrichPolicyRead(d, meta)
+}
+
+func resourceElasticsearchEnrichPolicyRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	esClient, err := resourceElasticsearchEnrichPolicyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	policyType, policy, err := elastic7GetEnrichPolicy(esClient, id)
+	if err != nil && !elastic7.IsNotFound(err) {
+		return err
+	}
+	if policy == nil {
+		log.Printf("[WARN] Enrich policy (%s) not found, removing from state", id)
+		d.SetId("")
+		return nil
+	}
+
+	executed, err := elastic7EnrichPolicyExecuted(esClient, id)
+	if err != nil {
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", policy.Name)
+	ds.set("policy_type", policyType)
+	ds.set("indices", policy.Indices)
+	ds.set("match_field", policy.MatchField)
+	ds.set("enrich_fields", policy.EnrichFields)
+	if policy.Query != nil {
+		query, err := json.Marshal(policy.Query)
+		if err != nil {
+			return err
+		}
+		ds.set("query", string(query))
+	} else {
+		ds.set("query", "")
+	}
+	ds.set("executed", executed)
+
+	return ds.err
+}
+
+func resourceElasticsearchEnrichPolicyDelete(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchEnrichPolicyClient(meta)
+	if err != nil {
+		return err
+	}
+
+	err = elastic7DeleteEnrichPolicy(esClient, d.Id())
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Enrich policy (%s) not found, removing from state", d.Id())
+			return nil
+		}
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceElasticsearchEnrichPolicyClient(meta interface{}) (*elastic7.Client, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return nil, err
+		}
+		if elasticVersion.LessThan(enrichPolicyMinimalVersion) {
+			return nil, fmt.Errorf("enrich policy endpoint only available from ElasticSearch >= 7.5, got version %s", elasticVersion.String())
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("enrich policy endpoint only available from ElasticSearch >= 7.5, got version < 7.0.0")
+	}
+}
+
+func elastic7PutEnrichPolicy(client *elastic7.Client, name string, policyType string, policy EnrichPolicy) error {
+	path, err := uritemplates.Expand("/_enrich/policy/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for enrich policy: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   map[string]EnrichPolicy{policyType: policy},
+	})
+	return err
+}
+
+// elastic7GetEnrichPolicy returns the type and the definition of the policy,
+// the definition is nil when the policy doesn't exist
+func elastic7GetEnrichPolicy(client *elastic7.Client, name string) (string, *EnrichPolicy, error) {
+	path, err := uritemplates.Expand("/_enrich/policy/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("error building URL path for enrich policy: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	var response EnrichPoliciesResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return "", nil, fmt.Errorf("error unmarshalling enrich policy body: %+v: %+v", err, res.Body)
+	}
+	// the versions before 8.0 return an empty list instead of a 404
+	if len(response.Policies) == 0 {
+		return "", nil, nil
+	}
+
+	for policyType, policy := range response.Policies[0].Config {
+		return policyType, &policy, nil
+	}
+	return "", nil, fmt.Errorf("enrich policy %q has no definition", name)
+}
+
+func elastic7ExecuteEnrichPolicy(client *elastic7.Client, name string) error {
+	path, err := uritemplates.Expand("/_enrich/policy/{name}/_execute", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for enrich policy: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+	})
+	return err
+}
+
+// elastic7EnrichPolicyExecuted checks whether an enrich index, created by
+// each execution of the policy, exists. These indices are hidden.
+func elastic7EnrichPolicyExecuted(client *elastic7.Client, name string) (bool, error) {
+	mappings, err := client.GetMapping().
+		Index(fmt.Sprintf(".enrich-%s-*", name)).
+		ExpandWildcards("all").
+		AllowNoIndices(true).
+		Do(context.TODO())
+	if err != nil {
+		return false, err
+	}
+	return enrichIndicesOfPolicy(mappings, name), nil
+}
+
+// enrichIndicesOfPolicy checks the enrich_policy_name meta of the enrich
+// indices, the pattern of the indices of a policy also matches the indices of
+// the policies whose names start with its name
+func enrichIndicesOfPolicy(mappings map[string]interface{}, name string) bool {
+	for _, index := range mappings {
+		indexMappings, _ := index.(map[string]interface{})
+		mapping, _ := indexMappings["mappings"].(map[string]interface{})
+		meta, _ := mapping["_meta"].(map[string]interface{})
+		if meta["enrich_policy_name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+func elastic7DeleteEnrichPolicy(client *elastic7.Client, name string) error {
+	path, err := uritemplates.Expand("/_enrich/policy/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for enrich policy: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	return err
+}
+
+type EnrichPoliciesResponse struct {
+	Policies []struct {
+		Config map[string]EnrichPolicy `json:"config"`
+	} `json:"policies"`
+}
+
+type EnrichPolicy struct {
+	Name         string      `json:"name,omitempty"`
+	Indices      []string    `json:"indices"`
+	MatchField   string      `json:"match_field"`
+	EnrichFields []string    `json:"enrich_fields"`
+	Query        interface{} `json:"query,omitempty"`
+}
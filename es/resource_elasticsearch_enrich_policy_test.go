@@ -0,0 +1,165 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchEnrichPolicy(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	var allowed bool
+	if _, err := resourceElasticsearchEnrichPolicyClient(meta); err == nil {
+		allowed = true
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Enrich policies only supported on ES >= 7.5")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchEnrichPolicyDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchEnrichPolicy(false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchEnrichPolicyExists("elasticsearch_enrich_policy.test"),
+					resource.TestCheckResourceAttr("elasticsearch_enrich_policy.test", "policy_type", "match"),
+					resource.TestCheckResourceAttr("elasticsearch_enrich_policy.test", "enrich_fields.#", "2"),
+					resource.TestCheckResourceAttr("elasticsearch_enrich_policy.test", "executed", "false"),
+				),
+			},
+			{
+				// the policy is replaced and executed
+				Config: testAccElasticsearchEnrichPolicy(true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchEnrichPolicyExists("elasticsearch_enrich_policy.test"),
+					resource.TestCheckResourceAttr("elasticsearch_enrich_policy.test", "executed", "true"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_enrich_policy.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"force_execute",
+				},
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchEnrichPolicyExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No enrich policy ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		client, err := resourceElasticsearchEnrichPolicyClient(meta)
+		if err != nil {
+			return err
+		}
+		_, policy, err := elastic7GetEnrichPolicy(client, rs.Primary.ID)
+		if err != nil {
+			return err
+		}
+		if policy == nil {
+			return fmt.Errorf("Enrich policy %q not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchEnrichPolicyDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_enrich_policy" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		client, err := resourceElasticsearchEnrichPolicyClient(meta)
+		if err != nil {
+			return err
+		}
+		_, policy, err := elastic7GetEnrichPolicy(client, rs.Primary.ID)
+		if err != nil || policy == nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Enrich policy %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccElasticsearchEnrichPolicy(forceExecute bool) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index" "users" {
+  name               = "terraform-test-enrich-users"
+  number_of_shards   = 1
+  number_of_replicas = 0
+  mappings = jsonencode({
+    properties = {
+      email      = { type = "keyword" }
+      first_name = { type = "keyword" }
+      last_name  = { type = "keyword" }
+    }
+  })
+}
+
+resource "elasticsearch_enrich_policy" "test" {
+  name          = "terraform-test-users"
+  policy_type   = "match"
+  indices       = [elasticsearch_index.users.name]
+  match_field   = "email"
+  enrich_fields = ["first_name", "last_name"]
+  query = jsonencode({
+    match_all = {}
+  })
+  force_execute = %t
+}
+`, forceExecute)
+}
+
+func TestEnrichIndicesOfPolicy(t *testing.T) {
+	mappings := map[string]interface{}{
+		".enrich-foo-bar-1600000000000": map[string]interface{}{
+			"mappings": map[string]interface{}{
+				"_meta": map[string]interface{}{
+					"enrich_policy_name": "foo-bar",
+				},
+			},
+		},
+	}
+
+	if enrichIndicesOfPolicy(mappings, "foo") {
+		t.Errorf("Expected the indices of foo-bar not to be the indices of foo")
+	}
+	if !enrichIndicesOfPolicy(mappings, "foo-bar") {
+		t.Errorf("Expected the indices of foo-bar to be found")
+	}
+	if enrichIndicesOfPolicy(map[string]interface{}{}, "foo") {
+		t.Errorf("Expected no indices to be found")
+	}
+}
@@ -6,11 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
@@ -22,6 +31,13 @@ var (
 		"routing_partition_size",
 		"load_fixed_bitset_filters_eagerly",
 		"shard.check_on_startup",
+		"mode",
+		"routing_path",
+		"time_series.start_time",
+		"sort.field",
+		"sort.order",
+		"soft_deletes.enabled",
+		"store.preload",
 	}
 	dynamicsSettingsKeys = []string{
 		"number_of_replicas",
@@ -45,6 +61,8 @@ var (
 		"highlight.max_analyzed_offset",
 		"max_terms_count",
 		"max_regex_length",
+		"mapping.depth.limit",
+		"mapping.nested_fields.limit",
 		"routing.allocation.enable",
 		"routing.rebalance.enable",
 		"gc_deletes",
@@ -64,8 +82,36 @@ var (
 		"indexing.slowlog.threshold.index.trace",
 		"indexing.slowlog.level",
 		"indexing.slowlog.source",
+		"time_series.end_time",
 	}
 	settingsKeys = append(staticSettingsKeys, dynamicsSettingsKeys...)
+	// typed index settings which are also available on index templates
+	indexTemplateSettingsKeys = []string{
+		"mode",
+		"codec",
+		"sort.field",
+		"sort.order",
+		"soft_deletes.enabled",
+	}
+)
+
+var runtimeFieldsMinimalVersion, _ = version.NewVersion("7.11.0")
+var syntheticSourceMinimalVersion, _ = version.NewVersion("8.4.0")
+var timeSeriesModeMinimalVersion, _ = version.NewVersion("8.1.0")
+var logsdbModeMinimalVersion, _ = version.NewVersion("8.15.0")
+var synonymsSetsMinimalVersion, _ = version.NewVersion("8.10.0")
+var hiddenIndexMinimalVersion, _ = version.NewVersion("7.7.0")
+
+// the refresh interval of the indices which don't set it
+const indexDefaultRefreshInterval = "1s"
+
+var (
+	// time units accepted by elasticsearch, `-1` disables the threshold
+	indexDurationRegexp   = regexp.MustCompile(`^(-1|0|[0-9]+(\.[0-9]+)?(d|h|m|s|ms|micros|nanos))$`)
+	validateIndexDuration = validation.StringMatch(indexDurationRegexp, "must be a time unit value, e.g. `30s`, `500ms` or `-1`")
+	validateSlowlogLevel  = validation.StringInSlice([]string{"warn", "info", "debug", "trace"}, false)
+	// stringified number settings which must be positive
+	validatePositiveInteger = validation.StringMatch(regexp.MustCompile(`^[1-9][0-9]*$`), "must be a positive integer, e.g. `20`")
 )
 
 var (
@@ -82,6 +128,18 @@ var (
 			Default:     false,
 			Optional:    true,
 		},
+		"adopt_existing": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates that a create failing because the index already exists, e.g. when the response of a previous attempt was lost and the create was retried, adopts the existing index when its settings, mappings and aliases match the configuration. The adopted index is deleted when the resource is destroyed. Defaults to `false`.",
+			Default:     false,
+			Optional:    true,
+		},
+		"wait_for_green": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates to wait on creation for all the shards of the index to be active and for the index health to be green, the creation and both waits share the create timeout. Defaults to `false`.",
+			Default:     false,
+			Optional:    true,
+		},
 		// Static settings that can only be set on creation
 		"number_of_shards": {
 			Type:        schema.TypeString,
@@ -114,26 +172,94 @@ var (
 			ForceNew:    true,
 			Optional:    true,
 		},
+		"mode": {
+			Type:         schema.TypeString,
+			Description:  "The index mode: `standard`, `time_series` (ElasticSearch >= 8.1) or `logsdb` (ElasticSearch >= 8.15). A `time_series` index requires `routing_path`. This can be set only on creation.",
+			ForceNew:     true,
+			Optional:     true,
+			ValidateFunc: validation.StringInSlice([]string{"standard", "time_series", "logsdb"}, false),
+		},
+		"routing_path": {
+			Type:        schema.TypeList,
+			Description: "The dimension fields used to route the documents of a `time_series` index to the shards. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"time_series_start_time": {
+			Type:             schema.TypeString,
+			Description:      "The earliest `@timestamp` accepted by a `time_series` index, as a RFC3339 date. This can be set only on creation.",
+			ForceNew:         true,
+			Optional:         true,
+			Computed:         true,
+			ValidateFunc:     validation.IsRFC3339Time,
+			DiffSuppressFunc: suppressEquivalentRFC3339,
+		},
+		"sort_field": {
+			Type:        schema.TypeList,
+			Description: "The fields used to sort the segments of the index. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+			Elem:        &schema.Schema{Type: schema.TypeString},
+		},
+		"store_preload": {
+			Type:        schema.TypeList,
+			Description: "The extensions of the files to preload in the filesystem cache when the index is opened with memory mapped storage, e.g. `nvd` and `dvd`, or `*` for all the files. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^(\*|[a-z0-9]+)$`), "must be a file extension without the dot, or `*`"),
+			},
+		},
+		"sort_order": {
+			Type:        schema.TypeList,
+			Description: "The sort order of each field of `sort_field`: `asc` or `desc`. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validation.StringInSlice([]string{"asc", "desc"}, false),
+			},
+		},
+		"soft_deletes_enabled": {
+			Type:        schema.TypeBool,
+			Description: "Indicates whether soft deletes are enabled on the index, they can't be disabled from ElasticSearch 8.0. This can be set only on creation.",
+			ForceNew:    true,
+			Optional:    true,
+		},
 		// Dynamic settings that can be changed at runtime
 		"number_of_replicas": {
 			Type:        schema.TypeString,
-			Description: "Number of shard replicas. A stringified number.",
+			Description: "Number of shard replicas. A stringified number. It conflicts with `auto_expand_replicas`.",
 			Optional:    true,
 		},
 		"auto_expand_replicas": {
-			Type:        schema.TypeString,
-			Description: "Set the number of replicas to the node count in the cluster. Set to a dash delimited lower and upper bound (e.g. 0-5) or use all for the upper bound (e.g. 0-all)",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the number of replicas to the node count in the cluster. Set to a dash delimited lower and upper bound (e.g. 0-5) or use all for the upper bound (e.g. 0-all). It conflicts with `number_of_replicas`, which is managed by Elasticsearch while the replicas auto expand.",
+			Optional:     true,
+			ValidateFunc: validation.StringMatch(regexp.MustCompile(`^([0-9]+-([0-9]+|all)|false)$`), "must be a dash delimited lower and upper bound, e.g. `0-1` or `0-all`, or `false`"),
 		},
 		"refresh_interval": {
-			Type:        schema.TypeString,
-			Description: "How often to perform a refresh operation, which makes recent changes to the index visible to search. Can be set to `-1` to disable refresh.",
-			Optional:    true,
+			Type:             schema.TypeString,
+			Description:      "How often to perform a refresh operation, which makes recent changes to the index visible to search. Can be set to `-1` to disable refresh. Removing it sets back the default `1s` interval.",
+			Optional:         true,
+			ValidateFunc:     validateIndexDuration,
+			DiffSuppressFunc: diffSuppressIndexRefreshInterval,
 		},
 		"search_idle_after": {
-			Type:        schema.TypeString,
-			Description: "How long a shard can not receive a search or get request until it’s considered search idle.",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "How long a shard can not receive a search or get request until it’s considered search idle.",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
+		},
+		"time_series_end_time": {
+			Type:             schema.TypeString,
+			Description:      "The latest `@timestamp` (exclusive) accepted by a `time_series` index, as a RFC3339 date. It can only be increased.",
+			Optional:         true,
+			Computed:         true,
+			ValidateFunc:     validation.IsRFC3339Time,
+			DiffSuppressFunc: suppressEquivalentRFC3339,
 		},
 		"max_result_window": {
 			Type:        schema.TypeString,
@@ -195,6 +321,18 @@ var (
 			Description: "The maximum length of regex that can be used in Regexp Query. A stringified number.",
 			Optional:    true,
 		},
+		"mapping_depth_limit": {
+			Type:         schema.TypeString,
+			Description:  "The maximum depth for a field, which is measured as the number of inner objects. A stringified number.",
+			Optional:     true,
+			ValidateFunc: validatePositiveInteger,
+		},
+		"mapping_nested_fields_limit": {
+			Type:         schema.TypeString,
+			Description:  "The maximum number of distinct `nested` mappings in the index. A stringified number.",
+			Optional:     true,
+			ValidateFunc: validatePositiveInteger,
+		},
 		"blocks_read_only": {
 			Type:        schema.TypeBool,
 			Description: "Set to `true` to make the index and index metadata read only, `false` to allow writes and metadata changes.",
@@ -212,7 +350,7 @@ var (
 		},
 		"blocks_write": {
 			Type:        schema.TypeBool,
-			Description: "Set to `true` to disable data write operations against the index. This setting does not affect metadata.",
+			Description: "Set to `true` to disable data write operations against the index, e.g. to freeze the source index of a reindex before swapping an alias, and back to `false` to allow them again, the block is toggled in place. This setting does not affect metadata.",
 			Optional:    true,
 		},
 		"blocks_metadata": {
@@ -240,75 +378,95 @@ var (
 			Description: "The default ingest node pipeline for this index. Index requests will fail if the default pipeline is set and the pipeline does not exist.",
 			Optional:    true,
 		},
-		"search_slowlog_threshold_query_warn": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `10s`",
+		"validate_pipeline": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates that the `default_pipeline` should be checked for existence when planning. Defaults to `false`.",
+			Default:     false,
 			Optional:    true,
 		},
+		"search_slowlog_threshold_query_warn": {
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `10s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
+		},
 		"search_slowlog_threshold_query_info": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `5s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `5s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_threshold_query_debug": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `2s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `2s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_threshold_query_trace": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `500ms`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the query phase, in time units, e.g. `500ms`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_threshold_fetch_warn": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `10s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `10s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_threshold_fetch_info": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `5s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `5s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_threshold_fetch_debug": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `2s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `2s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_threshold_fetch_trace": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `500ms`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches in the fetch phase, in time units, e.g. `500ms`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"search_slowlog_level": {
-			Type:        schema.TypeString,
-			Description: "Set which logging level to use for the search slow log, can be: `warn`, `info`, `debug`, `trace`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set which logging level to use for the search slow log, can be: `warn`, `info`, `debug`, `trace`",
+			Optional:     true,
+			ValidateFunc: validateSlowlogLevel,
 		},
 		"indexing_slowlog_threshold_index_warn": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `10s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `10s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"indexing_slowlog_threshold_index_info": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `5s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `5s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"indexing_slowlog_threshold_index_debug": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `2s`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `2s`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"indexing_slowlog_threshold_index_trace": {
-			Type:        schema.TypeString,
-			Description: "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `500ms`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set the cutoff for shard level slow search logging of slow searches for indexing queries, in time units, e.g. `500ms`",
+			Optional:     true,
+			ValidateFunc: validateIndexDuration,
 		},
 		"indexing_slowlog_level": {
-			Type:        schema.TypeString,
-			Description: "Set which logging level to use for the search slow log, can be: `warn`, `info`, `debug`, `trace`",
-			Optional:    true,
+			Type:         schema.TypeString,
+			Description:  "Set which logging level to use for the search slow log, can be: `warn`, `info`, `debug`, `trace`",
+			Optional:     true,
+			ValidateFunc: validateSlowlogLevel,
 		},
 		"indexing_slowlog_source": {
 			Type:        schema.TypeString,
@@ -317,21 +475,34 @@ var (
 		},
 		// Other attributes
 		"mappings": {
-			Type:         schema.TypeString,
-			Description:  "A JSON string defining how documents in the index, and the fields they contain, are stored and indexed. To avoid the complexities of field mapping updates, updates of this field are not allowed via this provider. See the upstream [Elasticsearch docs](https://www.elastic.co/guide/en/elasticsearch/reference/6.8/indices-put-mapping.html#updating-field-mappings) for more details.",
-			Optional:     true,
-			ForceNew:     true,
-			ValidateFunc: validation.StringIsJSON,
+			Type:             schema.TypeString,
+			Description:      "A JSON string defining how documents in the index, and the fields they contain, are stored and indexed. To avoid the complexities of field mapping updates, updates of this field are not allowed via this provider. See the upstream [Elasticsearch docs](https://www.elastic.co/guide/en/elasticsearch/reference/6.8/indices-put-mapping.html#updating-field-mappings) for more details.",
+			Optional:         true,
+			ForceNew:         true,
+			ValidateFunc:     validation.StringIsJSON,
+			DiffSuppressFunc: suppressEquivalentJson,
+		},
+		"validate_mapping": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates that the `mappings` should be validated when planning, by creating a temporary index with the mappings and the analysis settings, which is deleted right after. The temporary index is hidden from ElasticSearch >= 7.7. When `mappings_dynamic` or the detection parameters are updated in place, they are validated along with the mappings of the existing index. Defaults to `false`.",
+			Default:     false,
+			Optional:    true,
 		},
 		"aliases": {
 			Type:        schema.TypeString,
-			Description: "A JSON string describing a set of aliases. The index aliases API allows aliasing an index with a name, with all APIs automatically converting the alias name to the actual index name. An alias can also be mapped to more than one index, and when specifying it, the alias will automatically expand to the aliased indices.",
+			Description: "A JSON string describing a set of aliases. The index aliases API allows aliasing an index with a name, with all APIs automatically converting the alias name to the actual index name. An alias can also be mapped to more than one index, and when specifying it, the alias will automatically expand to the aliased indices. An alias with `is_write_index` or `is_hidden` is checked against its other indices when planning the creation of the index.",
 			Optional:    true,
 			// In order to not handle the separate endpoint of alias updates, updates
 			// are not allowed via this provider currently.
 			ForceNew:     true,
 			ValidateFunc: validation.StringIsJSON,
 		},
+		"validate_aliases": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates that the `filter` queries of the `aliases` should be validated with the validate query API when planning. Defaults to `false`.",
+			Default:     false,
+			Optional:    true,
+		},
 		"analysis_analyzer": {
 			Type:         schema.TypeString,
 			Description:  "A JSON string describing the analyzers applied to the index.",
@@ -348,11 +519,17 @@ var (
 		},
 		"analysis_filter": {
 			Type:         schema.TypeString,
-			Description:  "A JSON string describing the filters applied to the index.",
+			Description:  "A JSON string describing the filters applied to the index. The `synonym` and `synonym_graph` filters may reference a set of the synonyms API with `synonyms_set`, with `updateable` set to `true` the set can be changed without recreating the index when the filter is only used by search analyzers.",
 			Optional:     true,
 			ForceNew:     true, // To add a filter, the index must be closed, updated, and then reopened; we can't handle that here.
 			ValidateFunc: validation.StringIsJSON,
 		},
+		"validate_synonyms_sets": {
+			Type:        schema.TypeBool,
+			Description: "A boolean that indicates that the synonyms sets referenced by the `synonyms_set` of the `synonym` and `synonym_graph` filters of `analysis_filter` should be checked for existence when planning. Defaults to `false`.",
+			Default:     false,
+			Optional:    true,
+		},
 		"analysis_normalizer": {
 			Type:         schema.TypeString,
 			Description:  "A JSON string describing the normalizers applied to the index.",
@@ -360,143 +537,1739 @@ var (
 			ForceNew:     true, // To add a normalizer, the index must be closed, updated, and then reopened; we can't handle that here.
 			ValidateFunc: validation.StringIsJSON,
 		},
+		"similarity": {
+			Type:             schema.TypeString,
+			Description:      "A JSON string describing the custom similarities of the index, e.g. configured `BM25` or `DFR` similarities, which can be referenced by name in the `similarity` parameter of fields in `mappings`.",
+			Optional:         true,
+			ForceNew:         true, // To add a similarity, the index must be closed, updated, and then reopened; we can't handle that here.
+			ValidateFunc:     validation.StringIsJSON,
+			DiffSuppressFunc: diffSuppressIndexSimilarity,
+		},
+		"mappings_dynamic": {
+			Type:         schema.TypeString,
+			Description:  "Whether new fields are added dynamically to the mappings: `true`, `false`, `strict` or `runtime` (ElasticSearch >= 7.11). It is merged into `mappings` on creation and can be updated in place. Only available from ElasticSearch >= 7.0.",
+			Optional:     true,
+			Computed:     true,
+			ValidateFunc: validation.StringInSlice([]string{"true", "false", "strict", "runtime"}, false),
+		},
+		"mappings_date_detection": {
+			Type:        schema.TypeBool,
+			Description: "Whether the new string fields matching `mappings_dynamic_date_formats` are mapped as `date` fields. It is merged into `mappings` on creation and can be updated in place. Only available from ElasticSearch >= 7.0. Defaults to `true`.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"mappings_numeric_detection": {
+			Type:        schema.TypeBool,
+			Description: "Whether the new string fields containing a number are mapped as `long` or `float` fields. It is merged into `mappings` on creation and can be updated in place. Only available from ElasticSearch >= 7.0. Defaults to `false`.",
+			Optional:    true,
+			Computed:    true,
+		},
+		"mappings_dynamic_date_formats": {
+			Type:        schema.TypeList,
+			Description: "The formats of the dates detected in the new string fields when `mappings_date_detection` is enabled, built-in formats like `strict_date_optional_time` or Java time patterns like `yyyy/MM/dd`, alternative formats are separated by `||`. It is merged into `mappings` on creation and can be updated in place. Only available from ElasticSearch >= 7.0. The Elasticsearch defaults are used when not set.",
+			Optional:    true,
+			Computed:    true,
+			Elem: &schema.Schema{
+				Type:         schema.TypeString,
+				ValidateFunc: validateIndexDynamicDateFormat,
+			},
+		},
+		"mappings_source": {
+			Type:        schema.TypeList,
+			Description: "The `_source` field of the mappings, which stores the original JSON documents. It is merged into `mappings` on creation, the `_source` field can't be changed on an existing index. Only available from ElasticSearch >= 7.0.",
+			Optional:    true,
+			ForceNew:    true,
+			MaxItems:    1,
+			Elem: &schema.Resource{
+				Schema: map[string]*schema.Schema{
+					"enabled": {
+						Type:         schema.TypeBool,
+						Description:  "Whether the `_source` is stored. Without it the documents can't be reindexed, updated or returned by searches. Defaults to `true`.",
+						Optional:     true,
+						ForceNew:     true,
+						Default:      true,
+						ValidateFunc: validateIndexMappingsSourceEnabled,
+					},
+					"includes": {
+						Type:        schema.TypeList,
+						Description: "The fields stored in the `_source`, wildcards are supported. The fields left out can't be reindexed, updated or returned by searches.",
+						Optional:    true,
+						ForceNew:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+					"excludes": {
+						Type:        schema.TypeList,
+						Description: "The fields left out of the `_source`, wildcards are supported. The fields left out can't be reindexed, updated or returned by searches.",
+						Optional:    true,
+						ForceNew:    true,
+						Elem:        &schema.Schema{Type: schema.TypeString},
+					},
+					"mode": {
+						Type:             schema.TypeString,
+						Description:      "How the `_source` is stored: `stored`, the default, or `synthetic` to rebuild it from the doc values when it is read, which saves storage (ElasticSearch >= 8.4). A synthetic `_source` can't be filtered with `includes` or `excludes`.",
+						Optional:         true,
+						ForceNew:         true,
+						ValidateFunc:     validation.StringInSlice([]string{"stored", "synthetic"}, false),
+						DiffSuppressFunc: diffSuppressIndexMappingsSourceMode,
+					},
+				},
+			},
+		},
 		// Computed attributes
 		"rollover_alias": {
 			Type:     schema.TypeString,
 			Optional: true,
 			Computed: true,
 		},
+		"uuid": {
+			Type:        schema.TypeString,
+			Description: "The UUID of the index, it changes when the index is recreated.",
+			Computed:    true,
+		},
+		"creation_date": {
+			Type:        schema.TypeString,
+			Description: "The creation date of the index, as a RFC3339 date.",
+			Computed:    true,
+		},
+		"provided_name": {
+			Type:        schema.TypeString,
+			Description: "The name the index was created with, e.g. with the date math expression of `name` before it was resolved.",
+			Computed:    true,
+		},
+	}
+)
+
+func resourceElasticsearchIndex() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch index resource.",
+		Create:      resourceElasticsearchIndexCreate,
+		Read:        resourceElasticsearchIndexRead,
+		Update:      resourceElasticsearchIndexUpdate,
+		Delete:      resourceElasticsearchIndexDelete,
+		Schema:      configSchema,
+		CustomizeDiff: customdiff.All(
+			resourceElasticsearchIndexValidatePipeline,
+			resourceElasticsearchIndexValidateAliasFilters,
+			resourceElasticsearchIndexValidateWriteAliases,
+			resourceElasticsearchIndexValidateSimilarity,
+			resourceElasticsearchIndexValidateSynonymsSets,
+			resourceElasticsearchIndexValidateMappingsDynamic,
+			resourceElasticsearchIndexValidateMappingsDetection,
+			resourceElasticsearchIndexValidateMappingsSource,
+			resourceElasticsearchIndexValidateMapping,
+			resourceElasticsearchIndexValidateMode,
+			resourceElasticsearchIndexValidateAutoExpandReplicas,
+		),
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(5 * time.Minute),
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceElasticsearchIndexValidatePipeline(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	pipeline, ok := d.GetOk("default_pipeline")
+	if !d.Get("validate_pipeline").(bool) || !ok || meta == nil {
+		return nil
+	}
+	name := pipeline.(string)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.IngestGetPipeline(name).Do(ctx)
+		if elastic7.IsNotFound(err) {
+			return fmt.Errorf("default_pipeline %q does not exist, create the ingest pipeline first", name)
+		}
+	case *elastic6.Client:
+		_, err = client.IngestGetPipeline(name).Do(ctx)
+		if elastic6.IsNotFound(err) {
+			return fmt.Errorf("default_pipeline %q does not exist, create the ingest pipeline first", name)
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+
+	return err
+}
+
+// resourceElasticsearchIndexValidateSynonymsSets checks the synonym filters
+// which reference a synonyms set of the synonyms API and, when asked, that the
+// sets exist, Elasticsearch would otherwise only fail when creating the index
+func resourceElasticsearchIndexValidateSynonymsSets(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	filterJSON, ok := d.GetOk("analysis_filter")
+	if !ok || !d.NewValueKnown("analysis_filter") {
+		return nil
+	}
+
+	synonymsSets, err := indexAnalysisSynonymsSets(filterJSON.(string))
+	if err != nil {
+		return err
+	}
+	if !d.Get("validate_synonyms_sets").(bool) || len(synonymsSets) == 0 || meta == nil {
+		return nil
+	}
+
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if esVersion.LessThan(synonymsSetsMinimalVersion) {
+		return fmt.Errorf("synonyms_set is only available from ElasticSearch >= %s, got version %s", synonymsSetsMinimalVersion.String(), esVersion.String())
+	}
+
+	filters := make([]string, 0, len(synonymsSets))
+	for filter := range synonymsSets {
+		filters = append(filters, filter)
+	}
+	sort.Strings(filters)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	for _, filter := range filters {
+		path, err := uritemplates.Expand("/_synonyms/{id}", map[string]string{
+			"id": synonymsSets[filter],
+		})
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+				Method: "GET",
+				Path:   path,
+			})
+			if elastic7.IsNotFound(err) {
+				return fmt.Errorf("synonyms_set %q of the filter %q does not exist, create the synonyms set first", synonymsSets[filter], filter)
+			}
+		default:
+			err = errors.New("Elasticsearch version not supported")
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// indexAnalysisSynonymsSets returns the synonyms sets referenced by the
+// synonym filters, by filter name
+func indexAnalysisSynonymsSets(filterJSON string) (map[string]string, error) {
+	var filters map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(filterJSON), &filters); err != nil {
+		return nil, fmt.Errorf("fail to unmarshal: %v", err)
+	}
+
+	synonymsSets := make(map[string]string)
+	for name, filter := range filters {
+		synonymsSet, ok := filter["synonyms_set"]
+		if !ok {
+			continue
+		}
+		if filterType := fmt.Sprint(filter["type"]); filterType != "synonym" && filterType != "synonym_graph" {
+			return nil, fmt.Errorf("synonyms_set is only allowed in synonym and synonym_graph filters, got type %q for the filter %q", filterType, name)
+		}
+		for _, source := range []string{"synonyms", "synonyms_path"} {
+			if _, ok := filter[source]; ok {
+				return nil, fmt.Errorf("the filter %q sets both synonyms_set and %s, only one of them is allowed", name, source)
+			}
+		}
+		synonymsSets[name] = fmt.Sprint(synonymsSet)
+	}
+	return synonymsSets, nil
+}
+
+func resourceElasticsearchIndexValidateAliasFilters(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	aliasesJSON, ok := d.GetOk("aliases")
+	if !d.Get("validate_aliases").(bool) || !ok || !d.NewValueKnown("aliases") || meta == nil {
+		return nil
+	}
+
+	var aliases map[string]struct {
+		Filter map[string]interface{} `json:"filter"`
+	}
+	if err := json.Unmarshal([]byte(aliasesJSON.(string)), &aliases); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+
+	// the index doesn't exist before its creation, the filters are then only
+	// parsed against all the indices
+	path := "/_validate/query"
+	if d.Id() != "" {
+		var err error
+		path, err = uritemplates.Expand("/{index}/_validate/query", map[string]string{
+			"index": d.Id(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	params := url.Values{"explain": []string{"true"}}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if aliases[name].Filter == nil {
+			continue
+		}
+		body := map[string]interface{}{"query": aliases[name].Filter}
+
+		var responseBody json.RawMessage
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			var res *elastic7.Response
+			res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+				Method: "POST",
+				Path:   path,
+				Params: params,
+				Body:   body,
+			})
+			if err == nil {
+				responseBody = res.Body
+			}
+		case *elastic6.Client:
+			var res *elastic6.Response
+			res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+				Method: "POST",
+				Path:   path,
+				Params: params,
+				Body:   body,
+			})
+			if err == nil {
+				responseBody = res.Body
+			}
+		default:
+			err = errors.New("Elasticsearch version not supported")
+		}
+		if err != nil {
+			return err
+		}
+
+		if explanation, err := invalidQueryExplanation(responseBody); err != nil {
+			return err
+		} else if explanation != "" {
+			return fmt.Errorf("the filter of the alias %q is invalid: %s", name, explanation)
+		}
+	}
+
+	return nil
+}
+
+// resourceElasticsearchIndexValidateWriteAliases checks the aliases against the
+// other indices of the aliases, Elasticsearch rejects an alias with several
+// write indices or which is hidden on some of its indices only
+func resourceElasticsearchIndexValidateWriteAliases(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	aliasesJSON, ok := d.GetOk("aliases")
+	if !ok || !d.NewValueKnown("aliases") || !d.NewValueKnown("name") {
+		return nil
+	}
+
+	// the aliases are decoded as a map, a repeated alias would be silently
+	// dropped
+	names, err := jsonObjectKeys(aliasesJSON.(string))
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			return fmt.Errorf("alias %q is declared more than once in aliases", name)
+		}
+		seen[name] = true
+	}
+
+	var aliases map[string]indexAliasOptions
+	if err := json.Unmarshal([]byte(aliasesJSON.(string)), &aliases); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	// the other indices are only checked when the aliases are set, the write
+	// index moves to the new indices of the alias when it is rolled over
+	if meta == nil || (d.Id() != "" && !d.HasChange("aliases")) {
+		return nil
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		alias := aliases[name]
+		if !alias.IsWriteIndex && !alias.IsHidden {
+			continue
+		}
+
+		indices, err := resourceElasticsearchGetAliasIndices(ctx, name, meta)
+		if err != nil {
+			return err
+		}
+
+		indexNames := make([]string, 0, len(indices))
+		for index := range indices {
+			if index != d.Get("name").(string) {
+				indexNames = append(indexNames, index)
+			}
+		}
+		sort.Strings(indexNames)
+		for _, index := range indexNames {
+			if alias.IsWriteIndex && indices[index].IsWriteIndex {
+				return fmt.Errorf("alias %q already has the write index %q, set is_write_index to false on one of them", name, index)
+			}
+			if alias.IsHidden != indices[index].IsHidden {
+				return fmt.Errorf("alias %q must be hidden on all its indices or on none, is_hidden is %t on index %q", name, indices[index].IsHidden, index)
+			}
+		}
+	}
+
+	return nil
+}
+
+type indexAliasOptions struct {
+	IsWriteIndex bool `json:"is_write_index"`
+	IsHidden     bool `json:"is_hidden"`
+}
+
+// resourceElasticsearchGetAliasIndices returns the options of the alias on
+// each of its indices
+func resourceElasticsearchGetAliasIndices(ctx context.Context, name string, meta interface{}) (map[string]indexAliasOptions, error) {
+	path, err := uritemplates.Expand("/_alias/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var body json.RawMessage
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if elastic7.IsNotFound(err) {
+			return nil, nil
+		}
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+		})
+		if elastic6.IsNotFound(err) {
+			return nil, nil
+		}
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]struct {
+		Aliases map[string]indexAliasOptions `json:"aliases"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling aliases body: %+v: %+v", err, body)
+	}
+
+	indices := make(map[string]indexAliasOptions)
+	for index, aliases := range response {
+		indices[index] = aliases.Aliases[name]
+	}
+	return indices, nil
+}
+
+// jsonObjectKeys returns the keys of a JSON object in order, including the
+// repeated ones
+func jsonObjectKeys(s string) ([]string, error) {
+	decoder := json.NewDecoder(strings.NewReader(s))
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a JSON object, got %v", token)
+		}
+		keys = append(keys, key)
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// invalidQueryExplanation returns the error of an invalid query from the
+// validate query API response, or an empty string for a valid query
+func invalidQueryExplanation(body json.RawMessage) (string, error) {
+	var response struct {
+		Valid        bool   `json:"valid"`
+		Error        string `json:"error"`
+		Explanations []struct {
+			Valid bool   `json:"valid"`
+			Error string `json:"error"`
+		} `json:"explanations"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling validate query body: %+v: %+v", err, body)
+	}
+	if response.Valid {
+		return "", nil
+	}
+
+	if response.Error != "" {
+		return response.Error, nil
+	}
+	for _, explanation := range response.Explanations {
+		if !explanation.Valid && explanation.Error != "" {
+			return explanation.Error, nil
+		}
+	}
+	return "unknown error", nil
+}
+
+// The similarities that can be referenced from mappings without being defined
+// in the index settings
+var builtinSimilarities = []string{"BM25", "boolean", "classic"}
+
+func resourceElasticsearchIndexValidateSimilarity(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("mappings") || !d.NewValueKnown("similarity") {
+		return nil
+	}
+	mappingsJSON, ok := d.GetOk("mappings")
+	if !ok {
+		return nil
+	}
+
+	var mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(mappingsJSON.(string)), &mappings); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+
+	defined := make(map[string]bool)
+	for _, name := range builtinSimilarities {
+		defined[name] = true
+	}
+	if similarityJSON, ok := d.GetOk("similarity"); ok {
+		var similarity map[string]interface{}
+		if err := json.Unmarshal([]byte(similarityJSON.(string)), &similarity); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		for name := range similarity {
+			defined[name] = true
+		}
+	}
+
+	for _, name := range mappingsSimilarities(mappings) {
+		if !defined[name] {
+			return fmt.Errorf("mappings reference similarity %q which is not defined in similarity", name)
+		}
+	}
+
+	return nil
+}
+
+// mappingsSimilarities returns the similarities referenced by the fields of
+// the mappings, including multi-fields and nested properties
+func mappingsSimilarities(mappings map[string]interface{}) []string {
+	var similarities []string
+	for k, v := range mappings {
+		switch value := v.(type) {
+		case string:
+			if k == "similarity" {
+				similarities = append(similarities, value)
+			}
+		case map[string]interface{}:
+			similarities = append(similarities, mappingsSimilarities(value)...)
+		}
+	}
+	return similarities
+}
+
+func resourceElasticsearchIndexValidateMappingsDynamic(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("mappings") || !d.HasChange("mappings_dynamic") {
+		return nil
+	}
+	mappingsJSON, ok := d.GetOk("mappings")
+	dynamic := d.Get("mappings_dynamic").(string)
+	if !ok || dynamic == "" {
+		return nil
+	}
+
+	var mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(mappingsJSON.(string)), &mappings); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	if value, ok := mappings["dynamic"]; ok && fmt.Sprintf("%v", value) != dynamic {
+		return fmt.Errorf("mappings_dynamic %q conflicts with the dynamic parameter %q of mappings, set only one of them", dynamic, fmt.Sprintf("%v", value))
+	}
+
+	return nil
+}
+
+// resourceElasticsearchIndexValidateMappingsDetection checks that the typed
+// detection parameters don't conflict with the ones of mappings
+func resourceElasticsearchIndexValidateMappingsDetection(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	mappingsJSON, ok := d.GetOk("mappings")
+	if !ok || !d.NewValueKnown("mappings") {
+		return nil
+	}
+
+	var mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(mappingsJSON.(string)), &mappings); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	detection := indexMappingsDetection(d.GetOkExists)
+	for key, param := range indexMappingsDetectionParameters {
+		configured, ok := detection[param]
+		if !ok || !d.HasChange(key) {
+			continue
+		}
+		if value, ok := mappings[param]; ok && fmt.Sprintf("%v", value) != fmt.Sprintf("%v", configured) {
+			return fmt.Errorf("%s %v conflicts with the %s parameter %v of mappings, set only one of them", key, configured, param, value)
+		}
+	}
+
+	return nil
+}
+
+// resourceElasticsearchIndexValidateMappingsSource checks the combinations of
+// the _source parameters which Elasticsearch rejects when creating the index
+func resourceElasticsearchIndexValidateMappingsSource(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("mappings") || !d.NewValueKnown("mappings_source") {
+		return nil
+	}
+	source := indexMappingsSourceFromConfig(d.Get("mappings_source").([]interface{}))
+	if source == nil {
+		return nil
+	}
+
+	if mappingsJSON, ok := d.GetOk("mappings"); ok {
+		var mappings map[string]interface{}
+		if err := json.Unmarshal([]byte(mappingsJSON.(string)), &mappings); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		if _, ok := mappings["_source"]; ok {
+			return fmt.Errorf("mappings_source conflicts with the _source field of mappings, set only one of them")
+		}
+	}
+
+	_, filtered := source["includes"]
+	if _, ok := source["excludes"]; ok {
+		filtered = true
+	}
+	if source["mode"] == "synthetic" && filtered {
+		return fmt.Errorf("the includes and excludes of mappings_source can't be set with the synthetic mode")
+	}
+	if source["enabled"] == false && (filtered || source["mode"] != nil) {
+		return fmt.Errorf("the includes, excludes and mode of mappings_source can't be set when it is disabled")
+	}
+
+	return nil
+}
+
+// validateIndexMappingsSourceEnabled warns about a disabled _source, which
+// Elasticsearch accepts but which breaks the reindex and update APIs
+func validateIndexMappingsSourceEnabled(v interface{}, k string) (ws []string, errors []error) {
+	if !v.(bool) {
+		ws = append(ws, fmt.Sprintf("%q: without the _source the documents of the index can't be reindexed, updated with the update and update by query APIs, or returned by searches", k))
+	}
+	return
+}
+
+// validateIndexDynamicDateFormat checks the alternatives of a date format,
+// which are either built-in formats, e.g. strict_date_optional_time, or Java
+// time patterns, e.g. yyyy/MM/dd HH:mm:ss
+func validateIndexDynamicDateFormat(v interface{}, k string) (ws []string, errors []error) {
+	for _, format := range strings.Split(v.(string), "||") {
+		if strings.TrimSpace(format) == "" {
+			errors = append(errors, fmt.Errorf("%q contains an empty date format: %q", k, v))
+			continue
+		}
+		if indexBuiltinDateFormatRegexp.MatchString(format) {
+			continue
+		}
+		if err := checkIndexDateFormatPattern(format); err != nil {
+			errors = append(errors, fmt.Errorf("%q contains an invalid date format %q: %v", k, format, err))
+		}
+	}
+	return
+}
+
+// the built-in date formats are snake cased, e.g. epoch_millis, or single
+// words which aren't patterns
+var indexBuiltinDateFormatRegexp = regexp.MustCompile(`^([a-z0-9]+(_[a-z0-9]+)+|date|time|year|hour|iso8601)$`)
+
+// checkIndexDateFormatPattern checks the letters and the quotes and optional
+// sections of a Java time pattern
+func checkIndexDateFormatPattern(pattern string) error {
+	quoted := false
+	optional := 0
+	for _, c := range pattern {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			optional++
+		case c == ']':
+			if optional == 0 {
+				return fmt.Errorf("unbalanced ]")
+			}
+			optional--
+		case c == '{' || c == '}' || c == '#':
+			return fmt.Errorf("reserved character %q", c)
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			if !strings.ContainsRune("GuyDMLdQqYwWEecFaBhKkHmsSAnNVvzOXxZp", c) {
+				return fmt.Errorf("unknown pattern letter %q, quote literal letters, e.g. 'T'", c)
+			}
+		}
+	}
+	if quoted {
+		return fmt.Errorf("unbalanced quote")
+	}
+	if optional > 0 {
+		return fmt.Errorf("unbalanced [")
+	}
+	return nil
+}
+
+// stored is the default mode, it is only returned when set explicitly
+func diffSuppressIndexMappingsSourceMode(k, old, new string, d *schema.ResourceData) bool {
+	return (old == "" || old == "stored") && (new == "" || new == "stored")
+}
+
+// indexMappingsSourceFromConfig returns the _source field of the mappings,
+// only with the parameters which differ from the defaults
+func indexMappingsSourceFromConfig(config []interface{}) map[string]interface{} {
+	if len(config) == 0 || config[0] == nil {
+		return nil
+	}
+	block := config[0].(map[string]interface{})
+
+	source := make(map[string]interface{})
+	if enabled, ok := block["enabled"].(bool); ok && !enabled {
+		source["enabled"] = false
+	}
+	for _, key := range []string{"includes", "excludes"} {
+		if values, ok := block[key].([]interface{}); ok && len(values) > 0 {
+			source[key] = expandStringList(values)
+		}
+	}
+	if mode, ok := block["mode"].(string); ok && mode != "" {
+		source["mode"] = mode
+	}
+	return source
+}
+
+// flattenIndexMappingsSource returns the block of the _source field of the
+// mappings, a missing field has the default parameters
+func flattenIndexMappingsSource(mappings map[string]interface{}) []interface{} {
+	source, _ := mappings["_source"].(map[string]interface{})
+
+	enabled := true
+	if value, ok := source["enabled"].(bool); ok {
+		enabled = value
+	}
+	mode, _ := source["mode"].(string)
+	if mode == "disabled" {
+		enabled, mode = false, ""
+	}
+	includes, _ := source["includes"].([]interface{})
+	excludes, _ := source["excludes"].([]interface{})
+
+	return []interface{}{map[string]interface{}{
+		"enabled":  enabled,
+		"includes": expandStringList(includes),
+		"excludes": expandStringList(excludes),
+		"mode":     mode,
+	}}
+}
+
+// checkIndexMappingsSource checks that the _source parameters are supported by
+// the cluster
+func checkIndexMappingsSource(source map[string]interface{}, meta interface{}) error {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if _, ok := esClient.(*elastic7.Client); !ok {
+		return fmt.Errorf("mappings_source is only available from ElasticSearch >= 7.0")
+	}
+
+	if source["mode"] == "synthetic" {
+		esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		if esVersion.LessThan(syntheticSourceMinimalVersion) {
+			return fmt.Errorf("synthetic mode of mappings_source is only available from ElasticSearch >= 8.4, got version %s", esVersion.String())
+		}
+	}
+
+	return nil
+}
+
+// the settings which can be referenced by the mappings, they are set on the
+// temporary index which validates them
+var indexValidateMappingAnalysisKeys = map[string]string{
+	"analysis_analyzer":   "analyzer",
+	"analysis_tokenizer":  "tokenizer",
+	"analysis_filter":     "filter",
+	"analysis_normalizer": "normalizer",
+}
+
+// resourceElasticsearchIndexValidateMapping creates a temporary index with the
+// new mappings, Elasticsearch would otherwise only reject invalid mappings when
+// creating the index. The mappings updated in place are merged with the
+// mappings of the existing index, like Elasticsearch does.
+func resourceElasticsearchIndexValidateMapping(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	mappingsJSON, ok := d.GetOk("mappings")
+	if !d.Get("validate_mapping").(bool) || !ok || meta == nil {
+		return nil
+	}
+	for _, key := range append([]string{"mappings", "mappings_dynamic", "mappings_source", "similarity", "analysis_analyzer", "analysis_tokenizer", "analysis_filter", "analysis_normalizer"}, indexMappingsDetectionKeys()...) {
+		if !d.NewValueKnown(key) {
+			return nil
+		}
+	}
+
+	// the index is recreated when the mappings change, only the dynamic and
+	// the detection parameters are updated in place
+	updatedInPlace := false
+	if d.Id() != "" && !d.HasChange("mappings") {
+		for _, key := range append([]string{"mappings_dynamic"}, indexMappingsDetectionKeys()...) {
+			if d.HasChange(key) {
+				updatedInPlace = true
+			}
+		}
+		if !updatedInPlace {
+			return nil
+		}
+	}
+
+	var mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(mappingsJSON.(string)), &mappings); err != nil {
+		return fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	if updatedInPlace {
+		existing, _, err := resourceElasticsearchGetIndexMappingsAndAliases(ctx, d.Id(), meta)
+		if err != nil {
+			return err
+		}
+		mappings = mergeIndexMappings(existing, mappings)
+	}
+	if dynamic, ok := d.GetOk("mappings_dynamic"); ok {
+		mappings["dynamic"] = dynamic
+	}
+	for param, value := range indexMappingsDetection(d.GetOkExists) {
+		mappings[param] = value
+	}
+	if source := indexMappingsSourceFromConfig(d.Get("mappings_source").([]interface{})); source != nil {
+		mappings["_source"] = source
+	}
+
+	settings := map[string]interface{}{
+		"number_of_shards":   1,
+		"number_of_replicas": 0,
+	}
+	analysis := map[string]interface{}{}
+	for key, analysisKey := range indexValidateMappingAnalysisKeys {
+		if v, ok := d.GetOk(key); ok {
+			var value map[string]interface{}
+			if err := json.Unmarshal([]byte(v.(string)), &value); err != nil {
+				return fmt.Errorf("fail to unmarshal: %v", err)
+			}
+			analysis[analysisKey] = value
+		}
+	}
+	if len(analysis) > 0 {
+		settings["analysis"] = analysis
+	}
+	if v, ok := d.GetOk("similarity"); ok {
+		var similarity map[string]interface{}
+		if err := json.Unmarshal([]byte(v.(string)), &similarity); err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		settings["similarity"] = similarity
+	}
+
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if !esVersion.LessThan(hiddenIndexMinimalVersion) {
+		settings["hidden"] = true
+	}
+
+	body := map[string]interface{}{
+		"settings": settings,
+		"mappings": mappings,
+	}
+	name := resource.PrefixedUniqueId("terraform-validate-mapping-")
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.CreateIndex(name).BodyJson(body).Do(ctx)
+		// the index is deleted even when the create failed, e.g. on a timeout
+		// after the index was created
+		if _, deleteErr := client.DeleteIndex(name).Do(ctx); deleteErr != nil && !elastic7.IsNotFound(deleteErr) {
+			log.Printf("[WARN] Failed to delete the index %s validating the mappings: %+v", name, deleteErr)
+		}
+	case *elastic6.Client:
+		body["mappings"] = elastic6IndexMappings(mappings)
+		_, err = client.CreateIndex(name).BodyJson(body).Do(ctx)
+		if _, deleteErr := client.DeleteIndex(name).Do(ctx); deleteErr != nil && !elastic6.IsNotFound(deleteErr) {
+			log.Printf("[WARN] Failed to delete the index %s validating the mappings: %+v", name, deleteErr)
+		}
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+
+	if err != nil {
+		return fmt.Errorf("the mappings are invalid, creating a temporary index with them failed: %+v", err)
+	}
+
+	return nil
+}
+
+// mergeIndexMappings returns the existing mappings updated with the new ones,
+// the objects, e.g. the properties, are merged and the other values replaced
+func mergeIndexMappings(existing, mappings map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range mappings {
+		existingValue, existingOk := merged[key].(map[string]interface{})
+		newValue, newOk := value.(map[string]interface{})
+		if existingOk && newOk {
+			merged[key] = mergeIndexMappings(existingValue, newValue)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// the root parameters of typeless mappings, the mappings of ElasticSearch 6
+// are otherwise keyed by their type
+var indexMappingsRootParameters = []string{"properties", "dynamic", "dynamic_templates", "dynamic_date_formats", "date_detection", "numeric_detection", "_source", "_all", "_meta", "_routing", "_field_names", "enabled"}
+
+// elastic6IndexMappings wraps typeless mappings in the `_doc` type, which
+// ElasticSearch 6 requires
+func elastic6IndexMappings(mappings map[string]interface{}) map[string]interface{} {
+	for _, parameter := range indexMappingsRootParameters {
+		if _, ok := mappings[parameter]; ok {
+			return map[string]interface{}{"_doc": mappings}
+		}
+	}
+	return mappings
+}
+
+// resourceElasticsearchIndexValidateMode checks the settings required by or
+// only allowed with the time_series mode
+func resourceElasticsearchIndexValidateMode(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("mode") || !d.NewValueKnown("routing_path") {
+		return nil
+	}
+	mode := d.Get("mode").(string)
+	routingPath := d.Get("routing_path").([]interface{})
+
+	if mode == "time_series" {
+		if len(routingPath) == 0 {
+			return fmt.Errorf("routing_path is required with the time_series mode")
+		}
+		return nil
+	}
+
+	if len(routingPath) > 0 {
+		return fmt.Errorf("routing_path is only allowed with the time_series mode, got mode %q", mode)
+	}
+	for _, key := range []string{"time_series_start_time", "time_series_end_time"} {
+		if _, ok := d.GetOk(key); ok && d.NewValueKnown(key) {
+			return fmt.Errorf("%s is only allowed with the time_series mode, got mode %q", key, mode)
+		}
+	}
+
+	return nil
+}
+
+// resourceElasticsearchIndexValidateAutoExpandReplicas checks that the number
+// of replicas isn't set along with auto_expand_replicas, which overrides it
+func resourceElasticsearchIndexValidateAutoExpandReplicas(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("number_of_replicas") || !d.NewValueKnown("auto_expand_replicas") {
+		return nil
+	}
+	autoExpandReplicas := d.Get("auto_expand_replicas").(string)
+	if _, ok := d.GetOk("number_of_replicas"); ok && indexAutoExpandReplicasEnabled(autoExpandReplicas) {
+		return fmt.Errorf("number_of_replicas conflicts with auto_expand_replicas %q which sets the number of replicas, set only one of them", autoExpandReplicas)
+	}
+
+	return nil
+}
+
+func indexAutoExpandReplicasEnabled(autoExpandReplicas string) bool {
+	return autoExpandReplicas != "" && autoExpandReplicas != "false"
+}
+
+// checkIndexMode checks that the index mode is supported by the cluster
+func checkIndexMode(mode string, meta interface{}) error {
+	minimalVersion := timeSeriesModeMinimalVersion
+	if mode == "logsdb" {
+		minimalVersion = logsdbModeMinimalVersion
+	}
+
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if esVersion.LessThan(minimalVersion) {
+		return fmt.Errorf("%s mode is only available from ElasticSearch >= %s, got version %s", mode, minimalVersion.String(), esVersion.String())
+	}
+
+	return nil
+}
+
+// checkIndexMappingsDynamic checks that the dynamic parameter is supported
+// by the cluster
+func checkIndexMappingsDynamic(dynamic string, meta interface{}) error {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if _, ok := esClient.(*elastic7.Client); !ok {
+		return fmt.Errorf("mappings_dynamic is only available from ElasticSearch >= 7.0")
+	}
+
+	if dynamic == "runtime" {
+		esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		if esVersion.LessThan(runtimeFieldsMinimalVersion) {
+			return fmt.Errorf("runtime value of mappings_dynamic is only available from ElasticSearch >= 7.11, got version %s", esVersion.String())
+		}
+	}
+
+	return nil
+}
+
+// the typed attributes of the dynamic detection parameters of the mappings
+var indexMappingsDetectionParameters = map[string]string{
+	"mappings_date_detection":       "date_detection",
+	"mappings_numeric_detection":    "numeric_detection",
+	"mappings_dynamic_date_formats": "dynamic_date_formats",
+}
+
+func indexMappingsDetectionKeys() []string {
+	keys := make([]string, 0, len(indexMappingsDetectionParameters))
+	for key := range indexMappingsDetectionParameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// indexMappingsDetection returns the configured detection parameters of the
+// mappings, keyed by parameter, the default formats are kept when none is set
+func indexMappingsDetection(getOkExists func(string) (interface{}, bool)) map[string]interface{} {
+	params := make(map[string]interface{})
+	for key, param := range indexMappingsDetectionParameters {
+		value, ok := getOkExists(key)
+		if !ok {
+			continue
+		}
+		if formats, isList := value.([]interface{}); isList && len(formats) == 0 {
+			continue
+		}
+		params[param] = value
+	}
+	return params
+}
+
+// flattenIndexMappingsDetection returns the detection parameters of the
+// mappings, with the Elasticsearch defaults when they aren't set
+func flattenIndexMappingsDetection(mappings map[string]interface{}) map[string]interface{} {
+	flattened := map[string]interface{}{
+		"mappings_date_detection":       true,
+		"mappings_numeric_detection":    false,
+		"mappings_dynamic_date_formats": []interface{}{},
+	}
+	for key, param := range indexMappingsDetectionParameters {
+		if value, ok := mappings[param]; ok {
+			flattened[key] = value
+		}
+	}
+	// the booleans are returned as strings by some versions
+	for _, key := range []string{"mappings_date_detection", "mappings_numeric_detection"} {
+		if value, ok := flattened[key].(string); ok {
+			flattened[key] = value == "true"
+		}
+	}
+	return flattened
+}
+
+func checkIndexMappingsDetection(meta interface{}) error {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if _, ok := esClient.(*elastic7.Client); !ok {
+		return fmt.Errorf("%s are only available from ElasticSearch >= 7.0", strings.Join(indexMappingsDetectionKeys(), ", "))
+	}
+	return nil
+}
+
+func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{}) error {
+	var (
+		name     = d.Get("name").(string)
+		settings = settingsFromIndexResourceData(d, settingsKeys)
+		body     = make(map[string]interface{})
+		ctx      = context.Background()
+		err      error
+	)
+	if len(settings) > 0 {
+		body["settings"] = settings
+	}
+
+	if aliasJSON, ok := d.GetOk("aliases"); ok {
+		var aliases map[string]interface{}
+		bytes := []byte(aliasJSON.(string))
+		err = json.Unmarshal(bytes, &aliases)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		body["aliases"] = aliases
+	}
+
+	analysis := map[string]interface{}{}
+	settings["analysis"] = analysis
+
+	if analyzerJSON, ok := d.GetOk("analysis_analyzer"); ok {
+		var analyzer map[string]interface{}
+		bytes := []byte(analyzerJSON.(string))
+		err = json.Unmarshal(bytes, &analyzer)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		analysis["analyzer"] = analyzer
+	}
+	if tokenizerJSON, ok := d.GetOk("analysis_tokenizer"); ok {
+		var tokenizer map[string]interface{}
+		bytes := []byte(tokenizerJSON.(string))
+		err = json.Unmarshal(bytes, &tokenizer)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		analysis["tokenizer"] = tokenizer
+	}
+	if filterJSON, ok := d.GetOk("analysis_filter"); ok {
+		var filter map[string]interface{}
+		bytes := []byte(filterJSON.(string))
+		err = json.Unmarshal(bytes, &filter)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		analysis["filter"] = filter
+	}
+	if normalizerJSON, ok := d.GetOk("analysis_normalizer"); ok {
+		var normalizer map[string]interface{}
+		bytes := []byte(normalizerJSON.(string))
+		err = json.Unmarshal(bytes, &normalizer)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		analysis["normalizer"] = normalizer
+	}
+
+	if similarityJSON, ok := d.GetOk("similarity"); ok {
+		var similarity map[string]interface{}
+		bytes := []byte(similarityJSON.(string))
+		err = json.Unmarshal(bytes, &similarity)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		settings["similarity"] = similarity
+	}
+
+	if mappingsJSON, ok := d.GetOk("mappings"); ok {
+		var mappings map[string]interface{}
+		bytes := []byte(mappingsJSON.(string))
+		err = json.Unmarshal(bytes, &mappings)
+		if err != nil {
+			return fmt.Errorf("fail to unmarshal: %v", err)
+		}
+		if _, ok := mappings["runtime"]; ok {
+			esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+			if err != nil {
+				return err
+			}
+			if esVersion.LessThan(runtimeFieldsMinimalVersion) {
+				return fmt.Errorf("runtime fields in mappings are only available from ElasticSearch >= 7.11, got version %s", esVersion.String())
+			}
+		}
+		body["mappings"] = mappings
+	}
+
+	if dynamic, ok := d.GetOk("mappings_dynamic"); ok {
+		err = checkIndexMappingsDynamic(dynamic.(string), meta)
+		if err != nil {
+			return err
+		}
+		mappings, ok := body["mappings"].(map[string]interface{})
+		if !ok {
+			mappings = make(map[string]interface{})
+			body["mappings"] = mappings
+		}
+		mappings["dynamic"] = dynamic
+	}
+
+	if detection := indexMappingsDetection(d.GetOkExists); len(detection) > 0 {
+		err = checkIndexMappingsDetection(meta)
+		if err != nil {
+			return err
+		}
+		mappings, ok := body["mappings"].(map[string]interface{})
+		if !ok {
+			mappings = make(map[string]interface{})
+			body["mappings"] = mappings
+		}
+		for param, value := range detection {
+			mappings[param] = value
+		}
+	}
+
+	if source := indexMappingsSourceFromConfig(d.Get("mappings_source").([]interface{})); source != nil {
+		err = checkIndexMappingsSource(source, meta)
+		if err != nil {
+			return err
+		}
+		mappings, ok := body["mappings"].(map[string]interface{})
+		if !ok {
+			mappings = make(map[string]interface{})
+			body["mappings"] = mappings
+		}
+		mappings["_source"] = source
+	}
+
+	if mode, ok := d.GetOk("mode"); ok && mode.(string) != "standard" {
+		err = checkIndexMode(mode.(string), meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	// if date math is used, we need to pass the resolved name along to the read
+	// so we can pull the right result from the response
+	var resolvedName string
+
+	// the creation and the wait for the green health share the create timeout
+	waitForGreen := d.Get("wait_for_green").(bool)
+	timeout := d.Timeout(schema.TimeoutCreate)
+	deadline := time.Now().Add(timeout)
+
+	// Note: the CreateIndex call handles URL encoding under the hood to handle
+	// non-URL friendly characters and functionality like date math
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		if waitForGreen {
+			resolvedName, err = elastic7CreateIndexWaitForActiveShards(client, name, body, timeout)
+		} else {
+			resp, requestErr := client.CreateIndex(name).BodyJson(body).Do(ctx)
+			err = requestErr
+			if err == nil {
+				resolvedName = resp.Index
+			}
+		}
+
+	case *elastic6.Client:
+		if mappings, ok := body["mappings"].(map[string]interface{}); ok {
+			body["mappings"] = elastic6IndexMappings(mappings)
+		}
+		if waitForGreen {
+			resolvedName, err = elastic6CreateIndexWaitForActiveShards(client, name, body, timeout)
+		} else {
+			resp, requestErr := client.CreateIndex(name).BodyJson(body).Do(ctx)
+			err = requestErr
+			if err == nil {
+				resolvedName = resp.Index
+			}
+		}
+
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+
+	if err != nil && d.Get("adopt_existing").(bool) {
+		resolvedName, err = resourceElasticsearchIndexAdoptExisting(ctx, err, name, body, meta)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Let terraform know the resource was created
+	d.SetId(resolvedName)
+
+	if waitForGreen {
+		err = resourceElasticsearchIndexWaitForGreen(resolvedName, meta, indexRemainingTimeout(deadline))
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchIndexRead(d, meta)
+}
+
+// resourceElasticsearchIndexAdoptExisting handles a create which failed
+// because the index already exists, e.g. when the response of a previous
+// attempt was lost and the create was retried. The existing index is adopted
+// if its settings, mappings and aliases match the body of the create request,
+// otherwise the create error is returned.
+func resourceElasticsearchIndexAdoptExisting(ctx context.Context, createErr error, name string, body map[string]interface{}, meta interface{}) (string, error) {
+	index, ok := indexAlreadyExists(createErr)
+	if !ok {
+		return "", createErr
+	}
+	if index == "" {
+		index = name
+	}
+
+	existing, err := resourceElasticsearchGetIndexFlatSettings(ctx, index, meta)
+	if err != nil {
+		return "", createErr
+	}
+	settings, _ := body["settings"].(map[string]interface{})
+	if mismatches := indexSettingsMismatches(settings, existing); len(mismatches) > 0 {
+		return "", fmt.Errorf("%v, the settings %v of the existing index don't match the configuration", createErr, mismatches)
+	}
+
+	mappings, aliases, err := resourceElasticsearchGetIndexMappingsAndAliases(ctx, index, meta)
+	if err != nil {
+		return "", createErr
+	}
+	// an index with other fields, e.g. mapped dynamically from its documents,
+	// wasn't created by the lost request
+	if !indexJSONEqual(body["mappings"], mappings) {
+		return "", fmt.Errorf("%v, the mappings of the existing index don't match the configuration", createErr)
+	}
+	if !indexJSONEqual(body["aliases"], aliases) {
+		return "", fmt.Errorf("%v, the aliases of the existing index don't match the configuration", createErr)
+	}
+
+	log.Printf("[WARN] Index (%s) already exists with the configured settings, adopting it", index)
+	return index, nil
+}
+
+// indexAlreadyExists returns the name of the index from an index already
+// exists error
+func indexAlreadyExists(err error) (string, bool) {
+	var errorType, index string
+	switch e := err.(type) {
+	case *elastic7.Error:
+		if e.Details != nil {
+			errorType, index = e.Details.Type, e.Details.Index
+		}
+	case *elastic6.Error:
+		if e.Details != nil {
+			errorType, index = e.Details.Type, e.Details.Index
+		}
+	}
+
+	switch errorType {
+	case "resource_already_exists_exception", "index_already_exists_exception":
+		return index, true
+	}
+	return "", false
+}
+
+// indexSettingsMismatches returns the sorted keys of the settings which differ
+// from the flat settings of an index, the nested settings like the analysis
+// aren't compared
+func indexSettingsMismatches(settings map[string]interface{}, existing map[string]interface{}) []string {
+	var mismatches []string
+	for key, value := range settings {
+		if _, ok := value.(map[string]interface{}); ok {
+			continue
+		}
+		existingValue, ok := existing["index."+key]
+		if !ok {
+			existingValue, ok = existing[key]
+		}
+		if !ok || fmt.Sprint(existingValue) != fmt.Sprint(value) {
+			mismatches = append(mismatches, key)
+		}
+	}
+	sort.Strings(mismatches)
+	return mismatches
+}
+
+// indexJSONEqual compares the configured JSON with the one returned by
+// Elasticsearch, the scalars are compared as strings as some are returned as
+// strings
+func indexJSONEqual(configured, existing interface{}) bool {
+	switch c := configured.(type) {
+	case nil:
+		e, _ := existing.(map[string]interface{})
+		return len(e) == 0
+	case map[string]interface{}:
+		e, ok := existing.(map[string]interface{})
+		if !ok || len(c) != len(e) {
+			return len(c) == 0 && len(e) == 0
+		}
+		for key, value := range c {
+			existingValue, ok := e[key]
+			if !ok || !indexJSONEqual(value, existingValue) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		e, ok := existing.([]interface{})
+		if !ok || len(c) != len(e) {
+			return false
+		}
+		for i := range c {
+			if !indexJSONEqual(c[i], e[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return fmt.Sprint(configured) == fmt.Sprint(existing)
+	}
+}
+
+func resourceElasticsearchGetIndexMappingsAndAliases(ctx context.Context, index string, meta interface{}) (map[string]interface{}, map[string]interface{}, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		r, err := client.IndexGet(index).Do(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp, ok := r[index]; ok {
+			return resp.Mappings, resp.Aliases, nil
+		}
+	case *elastic6.Client:
+		r, err := client.IndexGet(index).Do(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp, ok := r[index]; ok {
+			return resp.Mappings, resp.Aliases, nil
+		}
+	default:
+		return nil, nil, errors.New("Elasticsearch version not supported")
+	}
+	return nil, nil, fmt.Errorf("index %s not found in the response", index)
+}
+
+func resourceElasticsearchGetIndexFlatSettings(ctx context.Context, index string, meta interface{}) (map[string]interface{}, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]interface{}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		r, err := client.IndexGetSettings(index).FlatSettings(true).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if resp, ok := r[index]; ok {
+			settings = resp.Settings
+		}
+	case *elastic6.Client:
+		r, err := client.IndexGetSettings(index).FlatSettings(true).Do(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if resp, ok := r[index]; ok {
+			settings = resp.Settings
+		}
+	default:
+		return nil, errors.New("Elasticsearch version not supported")
+	}
+	return settings, nil
+}
+
+// the create index services don't support the wait_for_active_shards
+// parameter, the index is created with the same URL encoding of the name
+func elastic7CreateIndexWaitForActiveShards(client *elastic7.Client, name string, body map[string]interface{}, timeout time.Duration) (string, error) {
+	path, err := uritemplates.Expand("/{index}", map[string]string{
+		"index": name,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Params: url.Values{
+			"wait_for_active_shards": []string{"all"},
+			"timeout":                []string{fmt.Sprintf("%ds", int(timeout.Seconds()))},
+		},
+		Body: body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return indexNameFromCreateResponse(res.Body)
+}
+
+func elastic6CreateIndexWaitForActiveShards(client *elastic6.Client, name string, body map[string]interface{}, timeout time.Duration) (string, error) {
+	path, err := uritemplates.Expand("/{index}", map[string]string{
+		"index": name,
+	})
+	if err != nil {
+		return "", err
 	}
-)
 
-func resourceElasticsearchIndex() *schema.Resource {
-	return &schema.Resource{
-		Description: "Provides an Elasticsearch index resource.",
-		Create:      resourceElasticsearchIndexCreate,
-		Read:        resourceElasticsearchIndexRead,
-		Update:      resourceElasticsearchIndexUpdate,
-		Delete:      resourceElasticsearchIndexDelete,
-		Schema:      configSchema,
-		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+	res, err := client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Params: url.Values{
+			"wait_for_active_shards": []string{"all"},
+			"timeout":                []string{fmt.Sprintf("%ds", int(timeout.Seconds()))},
 		},
+		Body: body,
+	})
+	if err != nil {
+		return "", err
 	}
+
+	return indexNameFromCreateResponse(res.Body)
 }
 
-func resourceElasticsearchIndexCreate(d *schema.ResourceData, meta interface{}) error {
-	var (
-		name     = d.Get("name").(string)
-		settings = settingsFromIndexResourceData(d)
-		body     = make(map[string]interface{})
-		ctx      = context.Background()
-		err      error
-	)
-	if len(settings) > 0 {
-		body["settings"] = settings
+func indexNameFromCreateResponse(body json.RawMessage) (string, error) {
+	var response struct {
+		Index              string `json:"index"`
+		ShardsAcknowledged bool   `json:"shards_acknowledged"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling create index body: %+v: %+v", err, body)
 	}
 
-	if aliasJSON, ok := d.GetOk("aliases"); ok {
-		var aliases map[string]interface{}
-		bytes := []byte(aliasJSON.(string))
-		err = json.Unmarshal(bytes, &aliases)
-		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
-		}
-		body["aliases"] = aliases
+	// the index is created even if all the shards weren't started in time,
+	// the health check reports the unassigned shards
+	if !response.ShardsAcknowledged {
+		log.Printf("[WARN] Not all the shards of index %s were started before the timeout", response.Index)
 	}
 
-	analysis := map[string]interface{}{}
-	settings["analysis"] = analysis
+	return response.Index, nil
+}
 
-	if analyzerJSON, ok := d.GetOk("analysis_analyzer"); ok {
-		var analyzer map[string]interface{}
-		bytes := []byte(analyzerJSON.(string))
-		err = json.Unmarshal(bytes, &analyzer)
-		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
-		}
-		analysis["analyzer"] = analyzer
+// indexRemainingTimeout returns the time left before the deadline, Elasticsearch
+// returns right away with a zero timeout
+func indexRemainingTimeout(deadline time.Time) time.Duration {
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		return 0
 	}
-	if tokenizerJSON, ok := d.GetOk("analysis_tokenizer"); ok {
-		var tokenizer map[string]interface{}
-		bytes := []byte(tokenizerJSON.(string))
-		err = json.Unmarshal(bytes, &tokenizer)
-		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
-		}
-		analysis["tokenizer"] = tokenizer
+	return remaining
+}
+
+// resourceElasticsearchIndexWaitForGreen waits for the index health to be
+// green, on timeout the error explains why the first unassigned shard isn't
+// allocated
+func resourceElasticsearchIndexWaitForGreen(name string, meta interface{}, timeout time.Duration) error {
+	var (
+		ctx        = context.Background()
+		esTimeout  = fmt.Sprintf("%ds", int(timeout.Seconds()))
+		timedOut   bool
+		status     string
+		unassigned int
+		shard      string
+		primary    bool
+	)
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
 	}
-	if filterJSON, ok := d.GetOk("analysis_filter"); ok {
-		var filter map[string]interface{}
-		bytes := []byte(filterJSON.(string))
-		err = json.Unmarshal(bytes, &filter)
-		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.ClusterHealthResponse
+		res, err = client.ClusterHealth().Index(name).Level("shards").WaitForGreenStatus().Timeout(esTimeout).Do(ctx)
+		if err == nil {
+			timedOut, status, unassigned = res.TimedOut, res.Status, res.UnassignedShards
+			if index, ok := res.Indices[name]; ok {
+				for id, health := range index.Shards {
+					if health.UnassignedShards > 0 && (shard == "" || id < shard) {
+						shard, primary = id, !health.PrimaryActive
+					}
+				}
+			}
 		}
-		analysis["filter"] = filter
-	}
-	if normalizerJSON, ok := d.GetOk("analysis_normalizer"); ok {
-		var normalizer map[string]interface{}
-		bytes := []byte(normalizerJSON.(string))
-		err = json.Unmarshal(bytes, &normalizer)
-		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
+	case *elastic6.Client:
+		var res *elastic6.ClusterHealthResponse
+		res, err = client.ClusterHealth().Index(name).Level("shards").WaitForGreenStatus().Timeout(esTimeout).Do(ctx)
+		if err == nil {
+			timedOut, status, unassigned = res.TimedOut, res.Status, res.UnassignedShards
+			if index, ok := res.Indices[name]; ok {
+				for id, health := range index.Shards {
+					if health.UnassignedShards > 0 && (shard == "" || id < shard) {
+						shard, primary = id, !health.PrimaryActive
+					}
+				}
+			}
 		}
-		analysis["normalizer"] = normalizer
+	default:
+		err = errors.New("Elasticsearch version not supported")
 	}
 
-	if mappingsJSON, ok := d.GetOk("mappings"); ok {
-		var mappings map[string]interface{}
-		bytes := []byte(mappingsJSON.(string))
-		err = json.Unmarshal(bytes, &mappings)
-		if err != nil {
-			return fmt.Errorf("fail to unmarshal: %v", err)
-		}
-		body["mappings"] = mappings
+	if err != nil {
+		return err
+	}
+	if !timedOut {
+		return nil
 	}
 
-	// if date math is used, we need to pass the resolved name along to the read
-	// so we can pull the right result from the response
-	var resolvedName string
+	err = fmt.Errorf("timed out after %s waiting for the health of index %s to be green, got %s with %d unassigned shards", timeout, name, status, unassigned)
+	if shard == "" {
+		return err
+	}
+
+	explanation, explainErr := resourceElasticsearchIndexExplainAllocation(name, shard, primary, meta)
+	if explainErr != nil {
+		log.Printf("[WARN] Failed to explain the allocation of shard %s of index %s: %+v", shard, name, explainErr)
+		return err
+	}
+	return fmt.Errorf("%s: shard %s (primary: %t): %s", err, shard, primary, explanation)
+}
+
+func resourceElasticsearchIndexExplainAllocation(name string, shard string, primary bool, meta interface{}) (string, error) {
+	var body json.RawMessage
+
+	shardID, err := strconv.Atoi(shard)
+	if err != nil {
+		return "", err
+	}
+	request := map[string]interface{}{
+		"index":   name,
+		"shard":   shardID,
+		"primary": primary,
+	}
 
-	// Note: the CreateIndex call handles URL encoding under the hood to handle
-	// non-URL friendly characters and functionality like date math
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
-		return err
+		return "", err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		resp, requestErr := client.CreateIndex(name).BodyJson(body).Do(ctx)
-		err = requestErr
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_cluster/allocation/explain",
+			Body:   request,
+		})
 		if err == nil {
-			resolvedName = resp.Index
+			body = res.Body
 		}
-
 	case *elastic6.Client:
-		resp, requestErr := client.CreateIndex(name).BodyJson(body).Do(ctx)
-		err = requestErr
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_cluster/allocation/explain",
+			Body:   request,
+		})
 		if err == nil {
-			resolvedName = resp.Index
+			body = res.Body
 		}
-
 	default:
-		return errors.New("Elasticsearch version not supported")
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return "", err
 	}
 
-	if err == nil {
-		// Let terraform know the resource was created
-		d.SetId(resolvedName)
-		return resourceElasticsearchIndexRead(d, meta)
+	var response struct {
+		AllocateExplanation string `json:"allocate_explanation"`
+		UnassignedInfo      struct {
+			Reason string `json:"reason"`
+		} `json:"unassigned_info"`
 	}
-	return err
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("error unmarshalling allocation explain body: %+v: %+v", err, body)
+	}
+
+	return fmt.Sprintf("%s (%s)", response.AllocateExplanation, response.UnassignedInfo.Reason), nil
+}
+
+// indexTemplateSettingsSchema returns the schema of the typed index settings
+// of index templates, which unlike indices can be updated
+func indexTemplateSettingsSchema() map[string]*schema.Schema {
+	settingsSchema := make(map[string]*schema.Schema)
+	for _, key := range indexTemplateSettingsKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		s := *configSchema[schemaName]
+		s.ForceNew = false
+		s.Description = strings.TrimSuffix(s.Description, " This can be set only on creation.")
+		settingsSchema[schemaName] = &s
+	}
+	return settingsSchema
 }
 
-func settingsFromIndexResourceData(d *schema.ResourceData) map[string]interface{} {
+// settingsFromIndexResourceData returns the typed index settings of the keys,
+// it is shared by the index and the index template resources
+func settingsFromIndexResourceData(d *schema.ResourceData, keys []string) map[string]interface{} {
 	settings := make(map[string]interface{})
-	for _, key := range settingsKeys {
+	for _, key := range keys {
 		schemaName := strings.Replace(key, ".", "_", -1)
-		if raw, ok := d.GetOk(schemaName); ok {
+		raw, ok := d.GetOk(schemaName)
+		// soft deletes are enabled by default, disabling them must be sent
+		if key == "soft_deletes.enabled" {
+			raw, ok = d.GetOkExists(schemaName)
+		}
+		if ok {
 			log.Printf("[INFO] settingsFromIndexResourceData: key:%+v schemaName:%+v value:%+v, %+v", key, schemaName, raw, settings)
 			settings[key] = raw
 		}
@@ -504,14 +2277,21 @@ func settingsFromIndexResourceData(d *schema.ResourceData) map[string]interface{
 	return settings
 }
 
-func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.ResourceData) {
+// indexResourceDataFromSettings is the inverse of
+// settingsFromIndexResourceData, the settings can be prefixed by `index.`
+func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.ResourceData, keys []string) {
 	log.Printf("[INFO] indexResourceDataFromSettings: %+v", settings)
-	for _, key := range settingsKeys {
+	for _, key := range keys {
 		rawValue, okRaw := settings[key]
 		rawPrefixedValue, okPrefixed := settings["index."+key]
 		var value interface{}
 		if !okRaw && !okPrefixed {
-			continue
+			// the blocks are not returned once lifted
+			if strings.HasPrefix(key, "blocks.") {
+				value = false
+			} else {
+				continue
+			}
 		} else if okRaw {
 			value = rawValue
 		} else if okPrefixed {
@@ -519,6 +2299,16 @@ func indexResourceDataFromSettings(settings map[string]interface{}, d *schema.Re
 		}
 
 		schemaName := strings.Replace(key, ".", "_", -1)
+		// single values of list settings can be returned as strings
+		if s, ok := value.(string); ok && configSchema[schemaName].Type == schema.TypeList {
+			value = []interface{}{s}
+		}
+		// bool settings are returned as strings
+		if s, ok := value.(string); ok && configSchema[schemaName].Type == schema.TypeBool {
+			if b, err := strconv.ParseBool(s); err == nil {
+				value = b
+			}
+		}
 		err := d.Set(schemaName, value)
 		if err != nil {
 			log.Printf("[ERROR] indexResourceDataFromSettings: %+v", err)
@@ -603,12 +2393,100 @@ func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{})
 			settings[key] = d.Get(schemaName)
 		}
 	}
+	// switching between auto expanded and fixed replicas resets the setting
+	// which is no longer configured, as does removing the refresh interval,
+	// e.g. after a bulk load with a disabled (`-1`) refresh
+	for _, key := range []string{"number_of_replicas", "auto_expand_replicas", "refresh_interval"} {
+		if value, ok := settings[key]; ok && value == "" {
+			settings[key] = nil
+		}
+	}
 
-	// if we're not changing any settings, no-op this function
-	if len(settings) == 0 {
+	// if we're not changing anything, no-op this function
+	if len(settings) == 0 && !d.HasChange("mappings_dynamic") && !d.HasChanges(indexMappingsDetectionKeys()...) {
 		return resourceElasticsearchIndexRead(d, meta)
 	}
 
+	name := d.Id()
+	if alias, ok := d.GetOk("rollover_alias"); ok {
+		name = getWriteIndexByAlias(alias.(string), d, meta)
+	}
+
+	// Blocks reject the updates of the other settings and of the mappings,
+	// lift them first and set them last to apply all the changes at once
+	liftedBlocks, otherSettings, setBlocks := splitIndexSettingsByBlocks(settings)
+
+	err := resourceElasticsearchIndexPutSettings(name, liftedBlocks, meta)
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("mappings_dynamic") {
+		err = resourceElasticsearchIndexUpdateMappingsDynamic(d, meta)
+	}
+	if err == nil && d.HasChanges(indexMappingsDetectionKeys()...) {
+		err = resourceElasticsearchIndexUpdateMappingsDetection(d, meta)
+	}
+	if err == nil {
+		err = resourceElasticsearchIndexPutSettings(name, otherSettings, meta)
+	}
+	if err != nil {
+		// don't leave the index without the blocks it had on a failed update
+		return resourceElasticsearchIndexRestoreBlocks(d, name, liftedBlocks, err, meta)
+	}
+
+	err = resourceElasticsearchIndexPutSettings(name, setBlocks, meta)
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchIndexRead(d, meta.(*ProviderConf))
+}
+
+// resourceElasticsearchIndexRestoreBlocks sets back the lifted blocks which
+// were set before the update, and returns the update error
+func resourceElasticsearchIndexRestoreBlocks(d *schema.ResourceData, name string, liftedBlocks map[string]interface{}, updateErr error, meta interface{}) error {
+	restoredBlocks := make(map[string]interface{})
+	for key := range liftedBlocks {
+		if o, _ := d.GetChange(strings.Replace(key, ".", "_", -1)); o == true {
+			restoredBlocks[key] = true
+		}
+	}
+	if len(restoredBlocks) == 0 {
+		return updateErr
+	}
+
+	log.Printf("[WARN] Failed to update index (%s), setting back the blocks %v: %+v", name, restoredBlocks, updateErr)
+	err := resourceElasticsearchIndexPutSettings(name, restoredBlocks, meta)
+	if err != nil {
+		return fmt.Errorf("%+v, the blocks %v lifted for the update could not be set back: %+v", updateErr, restoredBlocks, err)
+	}
+	return updateErr
+}
+
+// splitIndexSettingsByBlocks splits the changed settings in the blocks being
+// lifted, the other settings and the blocks being set
+func splitIndexSettingsByBlocks(settings map[string]interface{}) (map[string]interface{}, map[string]interface{}, map[string]interface{}) {
+	liftedBlocks := make(map[string]interface{})
+	otherSettings := make(map[string]interface{})
+	setBlocks := make(map[string]interface{})
+	for key, value := range settings {
+		if !strings.HasPrefix(key, "blocks.") {
+			otherSettings[key] = value
+		} else if enabled, ok := value.(bool); ok && enabled {
+			setBlocks[key] = value
+		} else {
+			liftedBlocks[key] = value
+		}
+	}
+	return liftedBlocks, otherSettings, setBlocks
+}
+
+func resourceElasticsearchIndexPutSettings(name string, settings map[string]interface{}, meta interface{}) error {
+	if len(settings) == 0 {
+		return nil
+	}
+
 	body := map[string]interface{}{
 		// Note you do not have to explicitly specify the `index` section inside
 		// the `settings` section
@@ -616,15 +2494,10 @@ func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{})
 	}
 
 	var (
-		name = d.Id()
-		ctx  = context.Background()
-		err  error
+		ctx = context.Background()
+		err error
 	)
 
-	if alias, ok := d.GetOk("rollover_alias"); ok {
-		name = getWriteIndexByAlias(alias.(string), d, meta)
-	}
-
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
@@ -640,9 +2513,61 @@ func resourceElasticsearchIndexUpdate(d *schema.ResourceData, meta interface{})
 		return errors.New("Elasticsearch version not supported")
 	}
 
-	if err == nil {
-		return resourceElasticsearchIndexRead(d, meta.(*ProviderConf))
+	return err
+}
+
+func resourceElasticsearchIndexUpdateMappingsDynamic(d *schema.ResourceData, meta interface{}) error {
+	var (
+		name    = d.Id()
+		dynamic = d.Get("mappings_dynamic").(string)
+	)
+
+	if alias, ok := d.GetOk("rollover_alias"); ok {
+		name = getWriteIndexByAlias(alias.(string), d, meta)
+	}
+
+	// the attribute is computed, it can't be unset but only changed
+	if dynamic == "" {
+		return nil
+	}
+	err := checkIndexMappingsDynamic(dynamic, meta)
+	if err != nil {
+		return err
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	_, err = esClient.(*elastic7.Client).PutMapping().Index(name).BodyJson(map[string]interface{}{
+		"dynamic": dynamic,
+	}).Do(context.Background())
+
+	return err
+}
+
+func resourceElasticsearchIndexUpdateMappingsDetection(d *schema.ResourceData, meta interface{}) error {
+	name := d.Id()
+	if alias, ok := d.GetOk("rollover_alias"); ok {
+		name = getWriteIndexByAlias(alias.(string), d, meta)
 	}
+
+	// the attributes are computed, they can't be unset but only changed
+	detection := indexMappingsDetection(d.GetOkExists)
+	if len(detection) == 0 {
+		return nil
+	}
+	err := checkIndexMappingsDetection(meta)
+	if err != nil {
+		return err
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	_, err = esClient.(*elastic7.Client).PutMapping().Index(name).BodyJson(detection).Do(context.Background())
+
 	return err
 }
 
@@ -722,6 +2647,32 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 		if resp, ok := r[index]; ok {
 			settings = resp.Settings
 		}
+
+		mappings, err := elastic7GetIndexMappings(client, index)
+		if err != nil {
+			return err
+		}
+		dynamic := ""
+		if value, ok := mappings["dynamic"]; ok {
+			dynamic = fmt.Sprintf("%v", value)
+		}
+		err = d.Set("mappings_dynamic", dynamic)
+		if err != nil {
+			return err
+		}
+		for key, value := range flattenIndexMappingsDetection(mappings) {
+			err = d.Set(key, value)
+			if err != nil {
+				return err
+			}
+		}
+		// only read when managed, the _source can also be set in mappings
+		if _, ok := d.GetOk("mappings_source"); ok {
+			err = d.Set("mappings_source", flattenIndexMappingsSource(mappings))
+			if err != nil {
+				return err
+			}
+		}
 	case *elastic6.Client:
 		r, err := client.IndexGetSettings(index).FlatSettings(true).Do(ctx)
 		if err != nil {
@@ -765,7 +2716,71 @@ func resourceElasticsearchIndexRead(d *schema.ResourceData, meta interface{}) er
 		}
 	}
 
-	indexResourceDataFromSettings(settings, d)
+	indexResourceDataFromSettings(settings, d, settingsKeys)
 
-	return nil
+	ds := &resourceDataSetter{d: d}
+	// the number of replicas follows the node count while they auto expand
+	if indexAutoExpandReplicasEnabled(d.Get("auto_expand_replicas").(string)) {
+		ds.set("number_of_replicas", "")
+	}
+	// the default refresh interval is not returned, don't keep the interval
+	// which was reset, e.g. `-1`
+	if _, ok := settings["index.refresh_interval"]; !ok {
+		ds.set("refresh_interval", "")
+	}
+	ds.set("uuid", settings["index.uuid"])
+	ds.set("provided_name", settings["index.provided_name"])
+	// the creation date is returned in milliseconds since the epoch
+	if creationDate, ok := settings["index.creation_date"].(string); ok {
+		ms, err := strconv.ParseInt(creationDate, 10, 64)
+		if err != nil {
+			return fmt.Errorf("error parsing the creation date of index %s: %+v", index, err)
+		}
+		ds.set("creation_date", time.Unix(0, ms*int64(time.Millisecond)).UTC().Format(time.RFC3339Nano))
+	}
+	if ds.err != nil {
+		return ds.err
+	}
+
+	return indexSimilarityFromSettings(settings, d)
+}
+
+// elastic7GetIndexMappings returns the mappings of the index, empty when it
+// has none
+func elastic7GetIndexMappings(client *elastic7.Client, index string) (map[string]interface{}, error) {
+	r, err := client.GetMapping().Index(index).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	indexMappings, ok := r[index].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	mappings, ok := indexMappings["mappings"].(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{}, nil
+	}
+	return mappings, nil
+}
+
+// indexSimilarityFromSettings rebuilds the similarity JSON from the flat
+// index.similarity.* settings
+func indexSimilarityFromSettings(settings map[string]interface{}, d *schema.ResourceData) error {
+	similarity := make(map[string]interface{})
+	for key, value := range settings {
+		if strings.HasPrefix(key, "index.similarity.") {
+			similarity[strings.TrimPrefix(key, "index.similarity.")] = value
+		}
+	}
+
+	if len(similarity) == 0 {
+		return d.Set("similarity", nil)
+	}
+
+	similarityJSON, err := json.Marshal(unflattenMap(similarity))
+	if err != nil {
+		return err
+	}
+	return d.Set("similarity", string(similarityJSON))
 }
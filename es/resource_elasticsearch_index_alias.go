@@ -0,0 +1,396 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func resourceElasticsearchIndexAlias() *schema.Resource {
+	return &schema.Resource{
+		Description: "Manages an alias of an index, or of all the indices matching a pattern, independently of the index and of the index templates. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/indices-aliases.html) for more details.",
+		Create:      resourceElasticsearchIndexAliasCreate,
+		Read:        resourceElasticsearchIndexAliasRead,
+		Update:      resourceElasticsearchIndexAliasUpdate,
+		Delete:      resourceElasticsearchIndexAliasDelete,
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringDoesNotContainAny(",/"),
+				Description:  "The name of the index, or a pattern matching the indices with `*` wildcards, e.g. `logs-*`. Only the indices existing when the alias is applied are added to it.",
+			},
+			"alias": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringDoesNotContainAny(",/*"),
+				Description:  "The name of the alias.",
+			},
+			"filter": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+				Description:      "A JSON query limiting the documents which can be accessed through the alias.",
+			},
+			"routing": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"index_routing", "search_routing"},
+				Description:   "The routing value of both the indexing and the search operations through the alias.",
+			},
+			"index_routing": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"routing"},
+				Description:   "The routing value of the indexing operations through the alias.",
+			},
+			"search_routing": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ConflictsWith: []string{"routing"},
+				Description:   "The routing value of the search operations through the alias.",
+			},
+			"is_write_index": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether the index is the write index of the alias, the indexing requests through an alias pointing to several indices go to its write index. An alias has at most one write index, applying the alias fails when another index is its write index.",
+			},
+			"indices": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The sorted names of the indices of the alias matched by `index`.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceElasticsearchIndexAliasImport,
+		},
+	}
+}
+
+func resourceElasticsearchIndexAliasCreate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchIndexAliasPut(d, meta)
+	if err != nil {
+		return err
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("index").(string), d.Get("alias").(string)))
+	return resourceElasticsearchIndexAliasRead(d, meta)
+}
+
+func resourceElasticsearchIndexAliasRead(d *schema.ResourceData, meta interface{}) error {
+	index := d.Get("index").(string)
+	alias := d.Get("alias").(string)
+
+	var aliases map[string]IndexAlias
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		aliases, err = elastic7GetIndexAliases(client, index, alias)
+	case *elastic6.Client:
+		aliases, err = elastic6GetIndexAliases(client, index, alias)
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+			log.Printf("[WARN] Index alias (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	// a pattern matching no index of the alias is not an error
+	if len(aliases) == 0 {
+		log.Printf("[WARN] Index alias (%s) not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	indices := make([]string, 0, len(aliases))
+	for name := range aliases {
+		indices = append(indices, name)
+	}
+	sort.Strings(indices)
+	// the settings of the alias are the same for all the indices it was
+	// applied to
+	definition := aliases[indices[0]]
+
+	filter := ""
+	if definition.Filter != nil {
+		body, err := json.Marshal(definition.Filter)
+		if err != nil {
+			return err
+		}
+		filter = string(body)
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("index", index)
+	ds.set("alias", alias)
+	ds.set("filter", filter)
+	// the routing is returned as the index and the search routing
+	_, indexRouting := d.GetOk("index_routing")
+	_, searchRouting := d.GetOk("search_routing")
+	if definition.IndexRouting == definition.SearchRouting && !indexRouting && !searchRouting {
+		ds.set("routing", definition.IndexRouting)
+		ds.set("index_routing", "")
+		ds.set("search_routing", "")
+	} else {
+		ds.set("routing", "")
+		ds.set("index_routing", definition.IndexRouting)
+		ds.set("search_routing", definition.SearchRouting)
+	}
+	ds.set("is_write_index", definition.IsWriteIndex)
+	ds.set("indices", indices)
+	return ds.err
+}
+
+func resourceElasticsearchIndexAliasUpdate(d *schema.ResourceData, meta interface{}) error {
+	// adding the alias again replaces its settings
+	err := resourceElasticsearchIndexAliasPut(d, meta)
+	if err != nil {
+		return err
+	}
+
+	return resourceElasticsearchIndexAliasRead(d, meta)
+}
+
+func resourceElasticsearchIndexAliasDelete(d *schema.ResourceData, meta interface{}) error {
+	actions := []map[string]interface{}{{
+		"remove": map[string]interface{}{
+			"index": d.Get("index").(string),
+			"alias": d.Get("alias").(string),
+		},
+	}}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		err = elastic7UpdateAliases(client, actions)
+	case *elastic6.Client:
+		err = elastic6UpdateAliases(client, actions)
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		// the alias was removed with its indices
+		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceElasticsearchIndexAliasImport accepts the `index/alias` ID
+func resourceElasticsearchIndexAliasImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("unexpected format of ID (%s), expected index/alias", d.Id())
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("index", parts[0])
+	ds.set("alias", parts[1])
+	if ds.err != nil {
+		return nil, ds.err
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceElasticsearchIndexAliasPut(d *schema.ResourceData, meta interface{}) error {
+	index := d.Get("index").(string)
+	alias := d.Get("alias").(string)
+
+	add := map[string]interface{}{
+		"index": index,
+		"alias": alias,
+	}
+	if filter, ok := d.GetOk("filter"); ok {
+		var query map[string]interface{}
+		if err := json.Unmarshal([]byte(filter.(string)), &query); err != nil {
+			return fmt.Errorf("fail to unmarshal filter: %v", err)
+		}
+		add["filter"] = query
+	}
+	for _, key := range []string{"routing", "index_routing", "search_routing"} {
+		if value, ok := d.GetOk(key); ok {
+			add[key] = value
+		}
+	}
+	isWriteIndex := d.Get("is_write_index").(bool)
+	// unset, the index is the write index when it is the only index of the
+	// alias, it is sent as configured when changed
+	if isWriteIndex || d.HasChange("is_write_index") {
+		add["is_write_index"] = isWriteIndex
+	}
+	actions := []map[string]interface{}{{"add": add}}
+
+	var aliases map[string]IndexAlias
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		if isWriteIndex {
+			aliases, err = elastic7GetIndexAliases(client, "_all", alias)
+		}
+		if err == nil || elastic7.IsNotFound(err) {
+			err = indexAliasWriteIndexConflict(aliases, index, alias)
+		}
+		if err == nil {
+			err = elastic7UpdateAliases(client, actions)
+		}
+	case *elastic6.Client:
+		if isWriteIndex {
+			aliases, err = elastic6GetIndexAliases(client, "_all", alias)
+		}
+		if err == nil || elastic6.IsNotFound(err) {
+			err = indexAliasWriteIndexConflict(aliases, index, alias)
+		}
+		if err == nil {
+			err = elastic6UpdateAliases(client, actions)
+		}
+	default:
+		err = errors.New("Elasticsearch version not supported")
+	}
+
+	return err
+}
+
+// indexAliasWriteIndexConflict returns an error when another index than the
+// ones matched by the index pattern is the write index of the alias,
+// Elasticsearch rejects a second write index
+func indexAliasWriteIndexConflict(aliases map[string]IndexAlias, index string, alias string) error {
+	var conflicts []string
+	for name, definition := range aliases {
+		if definition.IsWriteIndex && !indexPatternMatch(index, name) {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	sort.Strings(conflicts)
+	return fmt.Errorf("the index %s is already the write index of the alias %s, set is_write_index to false on it before making %s the write index", strings.Join(conflicts, ", "), alias, index)
+}
+
+func indexAliasPath(index string, alias string) (string, error) {
+	path, err := uritemplates.Expand("/{index}/_alias/{alias}", map[string]string{
+		"index": index,
+		"alias": alias,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error building URL path for index alias: %+v", err)
+	}
+	return path, nil
+}
+
+func elastic7GetIndexAliases(client *elastic7.Client, index string, alias string) (map[string]IndexAlias, error) {
+	path, err := indexAliasPath(index, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return indexAliasesFromResponse(res.Body, alias)
+}
+
+func elastic6GetIndexAliases(client *elastic6.Client, index string, alias string) (map[string]IndexAlias, error) {
+	path, err := indexAliasPath(index, alias)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return indexAliasesFromResponse(res.Body, alias)
+}
+
+// indexAliasesFromResponse returns the definitions of the alias by index
+func indexAliasesFromResponse(body json.RawMessage, alias string) (map[string]IndexAlias, error) {
+	var response map[string]struct {
+		Aliases map[string]IndexAlias `json:"aliases"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling index alias body: %+v: %+v", err, body)
+	}
+
+	aliases := make(map[string]IndexAlias)
+	for index, indexAliases := range response {
+		if definition, ok := indexAliases.Aliases[alias]; ok {
+			aliases[index] = definition
+		}
+	}
+	return aliases, nil
+}
+
+func elastic7UpdateAliases(client *elastic7.Client, actions []map[string]interface{}) error {
+	_, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   "/_aliases",
+		Body: map[string]interface{}{
+			"actions": actions,
+		},
+	})
+	return err
+}
+
+func elastic6UpdateAliases(client *elastic6.Client, actions []map[string]interface{}) error {
+	_, err := client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+		Method: "POST",
+		Path:   "/_aliases",
+		Body: map[string]interface{}{
+			"actions": actions,
+		},
+	})
+	return err
+}
+
+type IndexAlias struct {
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  bool                   `json:"is_write_index,omitempty"`
+}
@@ -0,0 +1,211 @@
+package es
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchIndexAlias(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIndexAliasDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexAlias(`routing = "1"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexAliasExists("elasticsearch_index_alias.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "id", "terraform-test-alias-*/terraform-test-alias"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "routing", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "indices.#", "2"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "indices.0", "terraform-test-alias-1"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexAlias(`
+  index_routing  = "1"
+  search_routing = "1,2"`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexAliasExists("elasticsearch_index_alias.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "routing", ""),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "index_routing", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.test", "search_routing", "1,2"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_index_alias.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndexAlias_writeIndex(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIndexAliasDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexAliasWriteIndex(false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIndexAliasExists("elasticsearch_index_alias.write"),
+					resource.TestCheckResourceAttr("elasticsearch_index_alias.write", "is_write_index", "true"),
+				),
+			},
+			{
+				Config:      testAccElasticsearchIndexAliasWriteIndex(true),
+				ExpectError: regexp.MustCompile("the index terraform-test-alias-1 is already the write index of the alias terraform-test-alias-write"),
+			},
+		},
+	})
+}
+
+func TestIndexAliasWriteIndexConflict(t *testing.T) {
+	aliases, err := indexAliasesFromResponse([]byte(`{
+  "logs-1": {"aliases": {"logs": {"is_write_index": true}}},
+  "logs-2": {"aliases": {"logs": {"index_routing": "1", "search_routing": "1", "filter": {"term": {"user": "kimchy"}}}}},
+  "metrics-1": {"aliases": {"metrics": {"is_write_index": true}}}
+}`), "logs")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := map[string]IndexAlias{
+		"logs-1": {IsWriteIndex: true},
+		"logs-2": {
+			IndexRouting:  "1",
+			SearchRouting: "1",
+			Filter:        map[string]interface{}{"term": map[string]interface{}{"user": "kimchy"}},
+		},
+	}
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected the aliases %v, got %v", expected, aliases)
+	}
+
+	if err := indexAliasWriteIndexConflict(aliases, "logs-*", "logs"); err != nil {
+		t.Errorf("expected no conflict with the matched write index, got %s", err)
+	}
+	if err := indexAliasWriteIndexConflict(aliases, "logs-2", "logs"); err == nil {
+		t.Error("expected a conflict with the write index logs-1")
+	}
+}
+
+func testCheckElasticsearchIndexAliasExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No index alias ID is set")
+		}
+
+		aliases, err := testGetElasticsearchIndexAliases(rs.Primary.Attributes["index"], rs.Primary.Attributes["alias"])
+		if err != nil {
+			return err
+		}
+		if len(aliases) == 0 {
+			return fmt.Errorf("Index alias %s not found", rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchIndexAliasDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_index_alias" {
+			continue
+		}
+
+		aliases, err := testGetElasticsearchIndexAliases(rs.Primary.Attributes["index"], rs.Primary.Attributes["alias"])
+		if err != nil {
+			if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if len(aliases) > 0 {
+			return fmt.Errorf("Index alias %s still exists", rs.Primary.ID)
+		}
+	}
+
+	return nil
+}
+
+func testGetElasticsearchIndexAliases(index string, alias string) (map[string]IndexAlias, error) {
+	esClient, err := getClient(testAccProvider.Meta().(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		return elastic7GetIndexAliases(client, index, alias)
+	case *elastic6.Client:
+		return elastic6GetIndexAliases(client, index, alias)
+	default:
+		return nil, errors.New("Elasticsearch version not supported")
+	}
+}
+
+var testAccElasticsearchIndexAliasIndices = `
+resource "elasticsearch_index" "first" {
+  name               = "terraform-test-alias-1"
+  number_of_shards   = 1
+  number_of_replicas = 0
+}
+
+resource "elasticsearch_index" "second" {
+  name               = "terraform-test-alias-2"
+  number_of_shards   = 1
+  number_of_replicas = 0
+}
+`
+
+func testAccElasticsearchIndexAlias(routing string) string {
+	return testAccElasticsearchIndexAliasIndices + fmt.Sprintf(`
+resource "elasticsearch_index_alias" "test" {
+  index = "terraform-test-alias-*"
+  alias = "terraform-test-alias"
+  filter = jsonencode({
+    term = {
+      user = "kimchy"
+    }
+  })
+  %s
+
+  depends_on = [elasticsearch_index.first, elasticsearch_index.second]
+}
+`, routing)
+}
+
+func testAccElasticsearchIndexAliasWriteIndex(conflict bool) string {
+	config := testAccElasticsearchIndexAliasIndices + `
+resource "elasticsearch_index_alias" "write" {
+  index          = elasticsearch_index.first.name
+  alias          = "terraform-test-alias-write"
+  is_write_index = true
+}
+`
+	if conflict {
+		config += `
+resource "elasticsearch_index_alias" "conflict" {
+  index          = elasticsearch_index.second.name
+  alias          = elasticsearch_index_alias.write.alias
+  is_write_index = true
+}
+`
+	}
+	return config
+}
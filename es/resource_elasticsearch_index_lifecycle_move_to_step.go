@@ -0,0 +1,234 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var validateIndexLifecyclePhase = validation.StringInSlice([]string{"new", "hot", "warm", "cold", "frozen", "delete"}, false)
+
+func resourceElasticsearchIndexLifecycleMoveToStep() *schema.Resource {
+	return &schema.Resource{
+		Description: "Moves an index managed by an index lifecycle policy to another step of its policy, e.g. to retry a step manually or to skip a stuck one. The index is moved once, on creation, changing `triggers` moves it again from the same `current_step`. The resource doesn't follow the index afterwards, ILM keeps running the policy from the new step and destroying the resource doesn't move the index back. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/ilm-move-to-step.html) for more details.",
+		Create:      resourceElasticsearchIndexLifecycleMoveToStepCreate,
+		Read:        resourceElasticsearchIndexLifecycleMoveToStepRead,
+		Delete:      resourceElasticsearchIndexLifecycleMoveToStepDelete,
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "The name of the index.",
+			},
+			"current_step": {
+				Type:        schema.TypeList,
+				Required:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Description: "The step the index is expected to be in, the move fails when the index is in another step.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"phase": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validateIndexLifecyclePhase,
+							Description:  "The phase of the step, e.g. `hot`.",
+						},
+						"action": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "The action of the step, e.g. `rollover`.",
+						},
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "The name of the step, e.g. `check-rollover-ready`.",
+						},
+					},
+				},
+			},
+			"next_step": {
+				Type:        schema.TypeList,
+				Required:    true,
+				ForceNew:    true,
+				MaxItems:    1,
+				Description: "The step the index is moved to.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"phase": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validateIndexLifecyclePhase,
+							Description:  "The phase of the step, e.g. `warm`.",
+						},
+						"action": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "The action of the step, the first action of the phase when not set.",
+						},
+						"name": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ForceNew:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							RequiredWith: []string{"next_step.0.action"},
+							Description:  "The name of the step, the first step of the action when not set. It requires `action`.",
+						},
+					},
+				},
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values which run the action again when changed.",
+			},
+			"phase": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The phase of the index after the move, empty when it couldn't be explained.",
+			},
+			"action": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The action of the index after the move.",
+			},
+			"step": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The step of the index after the move.",
+			},
+		},
+	}
+}
+
+func resourceElasticsearchIndexLifecycleMoveToStepCreate(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	client, ok := esClient.(*elastic7.Client)
+	if !ok {
+		return fmt.Errorf("index lifecycle move to step endpoint only available from ElasticSearch >= 7.0, got version < 7.0.0")
+	}
+
+	index := d.Get("index").(string)
+	body := map[string]interface{}{
+		"current_step": indexLifecycleStepFromResourceData(d.Get("current_step.0").(map[string]interface{})),
+		"next_step":    indexLifecycleStepFromResourceData(d.Get("next_step.0").(map[string]interface{})),
+	}
+
+	err = elastic7IndexLifecycleMoveToStep(client, index, body)
+	if err != nil {
+		return fmt.Errorf("error moving the index %s to another lifecycle step: %+v", index, err)
+	}
+	d.SetId(resource.UniqueId())
+
+	// the index was moved, the step it is in is only informative
+	explain, err := elastic7IndexLifecycleExplain(client, index)
+	if err != nil {
+		log.Printf("[WARN] Failed to explain the lifecycle of the index %s after moving it: %+v", index, err)
+		return nil
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("phase", explain.Phase)
+	ds.set("action", explain.Action)
+	ds.set("step", explain.Step)
+	return ds.err
+}
+
+// resourceElasticsearchIndexLifecycleMoveToStepRead keeps the step of the
+// index right after the move, ILM moves the index on to the next steps
+func resourceElasticsearchIndexLifecycleMoveToStepRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceElasticsearchIndexLifecycleMoveToStepDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+// indexLifecycleStepFromResourceData returns the step keys which are set, the
+// action and the name of the next step are optional
+func indexLifecycleStepFromResourceData(step map[string]interface{}) map[string]interface{} {
+	keys := make(map[string]interface{})
+	for _, key := range []string{"phase", "action", "name"} {
+		if value, ok := step[key].(string); ok && value != "" {
+			keys[key] = value
+		}
+	}
+	return keys
+}
+
+func elastic7IndexLifecycleMoveToStep(client *elastic7.Client, index string, body map[string]interface{}) error {
+	path, err := uritemplates.Expand("/_ilm/move/{index}", map[string]string{
+		"index": index,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for index lifecycle move to step: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   body,
+	})
+	return err
+}
+
+func elastic7IndexLifecycleExplain(client *elastic7.Client, index string) (*IndexLifecycleExplain, error) {
+	path, err := uritemplates.Expand("/{index}/_ilm/explain", map[string]string{
+		"index": index,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error building URL path for index lifecycle explain: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Indices map[string]IndexLifecycleExplain `json:"indices"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling index lifecycle explain body: %+v: %+v", err, res.Body)
+	}
+	explain, ok := response.Indices[index]
+	if !ok {
+		return nil, fmt.Errorf("index %s not found in the index lifecycle explain response", index)
+	}
+	return &explain, nil
+}
+
+type IndexLifecycleExplain struct {
+	Managed bool   `json:"managed"`
+	Policy  string `json:"policy"`
+	Phase   string `json:"phase"`
+	Action  string `json:"action"`
+	Step    string `json:"step"`
+}
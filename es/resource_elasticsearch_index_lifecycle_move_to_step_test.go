@@ -0,0 +1,69 @@
+package es
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func TestAccElasticsearchIndexLifecycleMoveToStep_unmanagedIndex(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexLifecycleMoveToStep,
+				ExpectError: regexp.MustCompile("error moving the index terraform-test to another lifecycle step"),
+			},
+		},
+	})
+}
+
+func TestIndexLifecycleStepFromResourceData(t *testing.T) {
+	step := indexLifecycleStepFromResourceData(map[string]interface{}{
+		"phase":  "warm",
+		"action": "",
+		"name":   "",
+	})
+	expected := map[string]interface{}{"phase": "warm"}
+	if !reflect.DeepEqual(step, expected) {
+		t.Errorf("expected step %v, got %v", expected, step)
+	}
+
+	step = indexLifecycleStepFromResourceData(map[string]interface{}{
+		"phase":  "hot",
+		"action": "rollover",
+		"name":   "check-rollover-ready",
+	})
+	expected = map[string]interface{}{"phase": "hot", "action": "rollover", "name": "check-rollover-ready"}
+	if !reflect.DeepEqual(step, expected) {
+		t.Errorf("expected step %v, got %v", expected, step)
+	}
+}
+
+var testAccElasticsearchIndexLifecycleMoveToStep = `
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 0
+}
+
+resource "elasticsearch_index_lifecycle_move_to_step" "test" {
+  index = elasticsearch_index.test.name
+
+  current_step {
+    phase  = "new"
+    action = "complete"
+    name   = "complete"
+  }
+
+  next_step {
+    phase = "hot"
+  }
+}
+`
@@ -0,0 +1,139 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var reloadSearchAnalyzersMinimalVersion, _ = version.NewVersion("7.3.0")
+
+func resourceElasticsearchIndexReloadSearchAnalyzers() *schema.Resource {
+	return &schema.Resource{
+		Description: "Reloads the search analyzers of indices, e.g. after a change of the synonyms of an `updateable` synonym filter. The analyzers are reloaded on creation, so set `triggers` to what the synonyms depend on, e.g. the hash of a synonyms file, to reload them on each of its changes. Destroying the resource does nothing.",
+		Create:      resourceElasticsearchIndexReloadSearchAnalyzersCreate,
+		Read:        resourceElasticsearchIndexReloadSearchAnalyzersRead,
+		Delete:      resourceElasticsearchIndexReloadSearchAnalyzersDelete,
+		Schema: map[string]*schema.Schema{
+			"index": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The indices whose search analyzers are reloaded, a comma separated list of names or wildcard expressions.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values which run the action again when changed, e.g. a hash of the content of the synonyms set.",
+			},
+			"reload_details": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The analyzers reloaded on each index.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The name of the index.",
+						},
+						"reloaded_analyzers": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The names of the reloaded analyzers.",
+						},
+						"reloaded_node_ids": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "The IDs of the nodes where the analyzers were reloaded.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceElasticsearchIndexReloadSearchAnalyzersCreate(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	index := d.Get("index").(string)
+	path, err := uritemplates.Expand("/{index}/_reload_search_analyzers", map[string]string{
+		"index": index,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for reload search analyzers: %+v", err)
+	}
+
+	var body json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		if elasticVersion.LessThan(reloadSearchAnalyzersMinimalVersion) {
+			return fmt.Errorf("reload search analyzers endpoint only available from ElasticSearch >= 7.3, got version %s", elasticVersion.String())
+		}
+
+		res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+		})
+		if err != nil {
+			return fmt.Errorf("error reloading the search analyzers of %s: %+v", index, err)
+		}
+		body = res.Body
+	default:
+		return fmt.Errorf("reload search analyzers endpoint only available from ElasticSearch >= 7.3, got version < 7.0.0")
+	}
+
+	var response struct {
+		ReloadDetails []struct {
+			Index             string   `json:"index"`
+			ReloadedAnalyzers []string `json:"reloaded_analyzers"`
+			ReloadedNodeIDs   []string `json:"reloaded_node_ids"`
+		} `json:"reload_details"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("error unmarshalling reload search analyzers body: %+v: %+v", err, body)
+	}
+
+	reloadDetails := make([]map[string]interface{}, 0, len(response.ReloadDetails))
+	for _, details := range response.ReloadDetails {
+		reloadDetails = append(reloadDetails, map[string]interface{}{
+			"index":              details.Index,
+			"reloaded_analyzers": details.ReloadedAnalyzers,
+			"reloaded_node_ids":  details.ReloadedNodeIDs,
+		})
+	}
+
+	d.SetId(resource.UniqueId())
+	ds := &resourceDataSetter{d: d}
+	ds.set("reload_details", reloadDetails)
+	return ds.err
+}
+
+// resourceElasticsearchIndexReloadSearchAnalyzersRead keeps the details of
+// the last reload, a reload leaves nothing on the indices to compare them with
+func resourceElasticsearchIndexReloadSearchAnalyzersRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceElasticsearchIndexReloadSearchAnalyzersDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
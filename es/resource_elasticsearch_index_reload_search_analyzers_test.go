@@ -0,0 +1,91 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchIndexReloadSearchAnalyzers(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(reloadSearchAnalyzersMinimalVersion) {
+				t.Skip("Reload search analyzers only supported on ES >= 7.3")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexReloadSearchAnalyzers("v1"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index_reload_search_analyzers.test", "reload_details.#", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_index_reload_search_analyzers.test", "reload_details.0.index", "terraform-test"),
+					resource.TestCheckResourceAttr("elasticsearch_index_reload_search_analyzers.test", "reload_details.0.reloaded_analyzers.0", "terraform_search"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexReloadSearchAnalyzers("v2"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index_reload_search_analyzers.test", "triggers.synonyms", "v2"),
+					resource.TestCheckResourceAttr("elasticsearch_index_reload_search_analyzers.test", "reload_details.0.reloaded_analyzers.0", "terraform_search"),
+				),
+			},
+		},
+	})
+}
+
+func testAccElasticsearchIndexReloadSearchAnalyzers(trigger string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  analysis_filter = jsonencode({
+    terraform_synonyms = {
+      type       = "synonym_graph"
+      synonyms   = ["terraform, tf"]
+      updateable = true
+    }
+  })
+  analysis_analyzer = jsonencode({
+    terraform_search = {
+      type      = "custom"
+      tokenizer = "standard"
+      filter    = ["lowercase", "terraform_synonyms"]
+    }
+  })
+  mappings = jsonencode({
+    properties = {
+      title = {
+        type            = "text"
+        analyzer        = "standard"
+        search_analyzer = "terraform_search"
+      }
+    }
+  })
+}
+
+resource "elasticsearch_index_reload_search_analyzers" "test" {
+  index = elasticsearch_index.test.name
+  triggers = {
+    synonyms = "%s"
+  }
+}
+`, trigger)
+}
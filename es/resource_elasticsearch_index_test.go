@@ -2,11 +2,19 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
@@ -22,6 +30,14 @@ resource "elasticsearch_index" "test" {
   number_of_shards = 1
   number_of_replicas = 1
 }
+`
+	testAccElasticsearchIndexAdoptExisting = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  adopt_existing = true
+}
 `
 	testAccElasticsearchIndexUpdate1 = `
 resource "elasticsearch_index" "test" {
@@ -36,6 +52,7 @@ resource "elasticsearch_index" "test" {
 	blocks_read = false
 	blocks_write = false
 	blocks_metadata = false
+	search_idle_after = "30s"
 	search_slowlog_threshold_query_warn = "5s"
 	search_slowlog_threshold_fetch_warn = "5s"
 	search_slowlog_level = "warn"
@@ -108,178 +125,2010 @@ resource "elasticsearch_index" "test" {
 EOF
 }
 `
-	testAccElasticsearchIndexUpdateForceDestroy = `
+	testAccElasticsearchIndexInvalidDuration = `
 resource "elasticsearch_index" "test" {
   name = "terraform-test"
   number_of_shards = 1
-  number_of_replicas = 2
-  force_destroy = true
+  number_of_replicas = 1
+  search_slowlog_threshold_query_warn = "5 seconds"
 }
 `
-	testAccElasticsearchIndexDateMath = `
-resource "elasticsearch_index" "test_date_math" {
-  name = "<terraform-test-{now/y{yyyy}}-000001>"
-  # name = "%3Ctest-%7Bnow%2Fy%7Byyyy%7D%7D-000001%3E"
+	testAccElasticsearchIndexMissingPipeline = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
   number_of_shards = 1
   number_of_replicas = 1
+  default_pipeline = "terraform-test-missing"
+  validate_pipeline = true
 }
 `
-	testAccElasticsearchIndexRolloverAliasXpack = `
-resource "elasticsearch_xpack_index_lifecycle_policy" "test" {
+	testAccElasticsearchIndexInvalidAliasFilter = `
+resource "elasticsearch_index" "test" {
   name = "terraform-test"
-  body = <<EOF
+  number_of_shards = 1
+  number_of_replicas = 1
+  validate_aliases = true
+  aliases = jsonencode({
+    "terraform-test-alias" = {
+      "filter" = {
+        "terms" = { "user" = "kimchy" }
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexAliasFilter = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  validate_aliases = true
+  aliases = jsonencode({
+    "terraform-test-alias" = {
+      "filter" = {
+        "term" = { "user" = "kimchy" }
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexWriteAlias = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test-alias" = {
+      "is_write_index" = true
+    }
+  })
+}
+`
+	testAccElasticsearchIndexConflictingWriteAlias = testAccElasticsearchIndexWriteAlias + `
+resource "elasticsearch_index" "test_2" {
+  name = "terraform-test-2"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test-alias" = {
+      "is_write_index" = true
+    }
+  })
+}
+`
+	testAccElasticsearchIndexConflictingHiddenAlias = testAccElasticsearchIndexWriteAlias + `
+resource "elasticsearch_index" "test_2" {
+  name = "terraform-test-2"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test-alias" = {
+      "is_hidden" = true
+    }
+  })
+}
+`
+	testAccElasticsearchIndexRepeatedAlias = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = <<EOF
 {
-  "policy": {
-    "phases": {
-      "hot": {
-        "min_age": "0ms",
-        "actions": {
-          "rollover": {
-            "max_size": "50gb"
-          }
+  "terraform-test-alias": {"is_write_index": true},
+  "terraform-test-alias": {"is_write_index": false}
+}
+EOF
+}
+`
+	testAccElasticsearchIndexRuntimeFields = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    runtime = {
+      day_of_week = {
+        type = "keyword"
+        script = {
+          source = "emit(doc['@timestamp'].value.dayOfWeekEnum.getDisplayName(TextStyle.FULL, Locale.ROOT))"
         }
       }
     }
-  }
+    properties = {
+      "@timestamp" = {
+        type = "date"
+      }
+    }
+  })
 }
-EOF
+`
+	testAccElasticsearchIndexSimilarity = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  similarity = jsonencode({
+    short_fields = {
+      type = "BM25"
+      b    = 0.3
+      k1   = 1.2
+    }
+  })
+  mappings = jsonencode({
+    properties = {
+      title = {
+        type       = "text"
+        similarity = "short_fields"
+      }
+      body = {
+        type       = "text"
+        similarity = "BM25"
+      }
+    }
+  })
 }
-
-resource "elasticsearch_index_template" "test" {
+`
+	testAccElasticsearchIndexMissingSimilarity = `
+resource "elasticsearch_index" "test" {
   name = "terraform-test"
-  body = <<EOF
-{
-  "index_patterns": ["terraform-test-*"],
-  "settings": {
-    "index": {
-      "lifecycle": {
-        "name": "${elasticsearch_xpack_index_lifecycle_policy.test.name}",
-        "rollover_alias": "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    properties = {
+      title = {
+        type       = "text"
+        similarity = "short_fields"
       }
     }
-  }
+  })
 }
-EOF
+`
+	testAccElasticsearchIndexMappingsDynamicStrict = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings_dynamic = "strict"
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
+      }
+    }
+  })
 }
-
+`
+	testAccElasticsearchIndexMappingsDynamicFalse = `
 resource "elasticsearch_index" "test" {
-  name = "terraform-test-000001"
+  name = "terraform-test"
   number_of_shards = 1
   number_of_replicas = 1
-  aliases = jsonencode({
-    "terraform-test" = {
-      "is_write_index" = true
+  mappings_dynamic = "false"
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
+      }
     }
   })
-
-  depends_on = [elasticsearch_index_template.test]
 }
 `
-	testAccElasticsearchIndexRolloverAliasOpendistro = `
-resource elasticsearch_opendistro_ism_policy "test" {
-  policy_id = "test"
-  body      = <<EOF
-{
-  "policy": {
-    "description": "Terraform Test",
-    "default_state": "hot",
-    "states": [
-      {
-        "name": "hot",
-        "actions": [
-          {
-            "rollover": {
-              "min_size": "50gb"
-            }
-          }
-        ],
-        "transitions": []
+	testAccElasticsearchIndexMappingsDynamicConflict = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings_dynamic = "strict"
+  mappings = jsonencode({
+    dynamic = "false"
+  })
+}
+`
+	testAccElasticsearchIndexMappingsDetection = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings_date_detection = true
+  mappings_numeric_detection = true
+  mappings_dynamic_date_formats = ["strict_date_optional_time", "yyyy/MM/dd||epoch_millis"]
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
       }
-    ]
-  }
+    }
+  })
 }
-  EOF
+`
+	testAccElasticsearchIndexMappingsDetectionUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings_date_detection = false
+  mappings_numeric_detection = true
+  mappings_dynamic_date_formats = ["yyyy-MM-dd'T'HH:mm:ss"]
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexMappingsDetectionConflict = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings_numeric_detection = true
+  mappings = jsonencode({
+    numeric_detection = false
+  })
+}
+`
+	testAccElasticsearchIndexBlocksReadOnly = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  blocks_read_only = true
+}
+`
+	testAccElasticsearchIndexBlocksLifted = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 2
+}
+`
+	testAccElasticsearchIndexBlocksLiftedInvalidUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  max_result_window = "foo"
+}
+`
+	testAccElasticsearchIndexBlocksLiftedValidUpdate = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  max_result_window = "10000"
 }
+`
+	testAccElasticsearchIndexBlocksSet = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  blocks_read = true
+  blocks_write = true
+}
+`
+	testAccElasticsearchIndexWaitForGreen = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 0
+  wait_for_green = true
+}
+`
+	testAccElasticsearchIndexWaitForGreenUnassigned = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  # the replicas can't be allocated on a single node cluster
+  number_of_replicas = 1
+  wait_for_green = true
 
-resource "elasticsearch_index_template" "test" {
+  timeouts {
+    create = "5s"
+  }
+}
+`
+	testAccElasticsearchIndexMappingLimits = `
+resource "elasticsearch_index" "test" {
   name = "terraform-test"
-  body = <<EOF
+  number_of_shards = 1
+  number_of_replicas = 1
+  mapping_depth_limit = "30"
+  mapping_nested_fields_limit = "60"
+}
+`
+	testAccElasticsearchIndexSortSettings = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  codec = "best_compression"
+  sort_field = ["@timestamp", "host"]
+  sort_order = ["desc", "asc"]
+  soft_deletes_enabled = true
+  store_preload = ["nvd", "dvd"]
+  mappings = <<EOF
 {
-  "index_patterns": ["terraform-test-*"],
-  "settings": {
-    "index": {
-      "opendistro": {
-        "index_state_management": {
-          "policy_id": "${elasticsearch_opendistro_ism_policy.test.policy_id}",
-          "rollover_alias": "terraform-test"
-        }
-      }
-    }
+  "properties": {
+    "@timestamp": { "type": "date" },
+    "host": { "type": "keyword" }
   }
 }
-EOF
+EOF
+}
+`
+	testAccElasticsearchIndexMappingLimitsUpdated = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mapping_depth_limit = "40"
+  mapping_nested_fields_limit = "60"
+}
+`
+	testAccElasticsearchIndexMappingLimitsInvalid = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mapping_depth_limit = "0"
+}
+`
+	testAccElasticsearchIndexTimeSeries = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 0
+  mode = "time_series"
+  routing_path = ["host"]
+  time_series_start_time = "2030-01-01T00:00:00Z"
+  time_series_end_time = "2030-01-02T00:00:00Z"
+  mappings = <<EOF
+{
+  "properties": {
+    "@timestamp": { "type": "date" },
+    "host": { "type": "keyword", "time_series_dimension": true },
+    "cpu": { "type": "double", "time_series_metric": "gauge" }
+  }
+}
+EOF
+}
+`
+	testAccElasticsearchIndexTimeSeriesUpdated = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 0
+  mode = "time_series"
+  routing_path = ["host"]
+  time_series_start_time = "2030-01-01T00:00:00Z"
+  time_series_end_time = "2030-01-03T00:00:00Z"
+  mappings = <<EOF
+{
+  "properties": {
+    "@timestamp": { "type": "date" },
+    "host": { "type": "keyword", "time_series_dimension": true },
+    "cpu": { "type": "double", "time_series_metric": "gauge" }
+  }
+}
+EOF
+}
+`
+	testAccElasticsearchIndexTimeSeriesWithoutRoutingPath = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  mode = "time_series"
+}
+`
+	testAccElasticsearchIndexStandardWithRoutingPath = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  mode = "standard"
+  routing_path = ["host"]
+}
+`
+	testAccElasticsearchIndexStaticAndDynamic = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+}
+`
+	testAccElasticsearchIndexStaticAndDynamicChanged = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 2
+  number_of_replicas = 0
+}
+`
+	testAccElasticsearchIndexDynamicChanged = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 2
+  number_of_replicas = 1
+}
+`
+	testAccElasticsearchIndexUpdateForceDestroy = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 2
+  force_destroy = true
+}
+`
+	testAccElasticsearchIndexDateMath = `
+resource "elasticsearch_index" "test_date_math" {
+  name = "<terraform-test-{now/y{yyyy}}-000001>"
+  # name = "%3Ctest-%7Bnow%2Fy%7Byyyy%7D%7D-000001%3E"
+  number_of_shards = 1
+  number_of_replicas = 1
+}
+`
+	testAccElasticsearchIndexRolloverAliasXpack = `
+resource "elasticsearch_xpack_index_lifecycle_policy" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "policy": {
+    "phases": {
+      "hot": {
+        "min_age": "0ms",
+        "actions": {
+          "rollover": {
+            "max_size": "50gb"
+          }
+        }
+      }
+    }
+  }
+}
+EOF
+}
+
+resource "elasticsearch_index_template" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-*"],
+  "settings": {
+    "index": {
+      "lifecycle": {
+        "name": "${elasticsearch_xpack_index_lifecycle_policy.test.name}",
+        "rollover_alias": "terraform-test"
+      }
+    }
+  }
+}
+EOF
+}
+
+resource "elasticsearch_index" "test" {
+  name = "terraform-test-000001"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test" = {
+      "is_write_index" = true
+    }
+  })
+
+  depends_on = [elasticsearch_index_template.test]
+}
+`
+	testAccElasticsearchIndexRolloverAliasOpendistro = `
+resource elasticsearch_opendistro_ism_policy "test" {
+  policy_id = "test"
+  body      = <<EOF
+{
+  "policy": {
+    "description": "Terraform Test",
+    "default_state": "hot",
+    "states": [
+      {
+        "name": "hot",
+        "actions": [
+          {
+            "rollover": {
+              "min_size": "50gb"
+            }
+          }
+        ],
+        "transitions": []
+      }
+    ]
+  }
+}
+  EOF
+}
+
+resource "elasticsearch_index_template" "test" {
+  name = "terraform-test"
+  body = <<EOF
+{
+  "index_patterns": ["terraform-test-*"],
+  "settings": {
+    "index": {
+      "opendistro": {
+        "index_state_management": {
+          "policy_id": "${elasticsearch_opendistro_ism_policy.test.policy_id}",
+          "rollover_alias": "terraform-test"
+        }
+      }
+    }
+  }
+}
+EOF
+}
+
+resource "elasticsearch_index" "test" {
+  name = "terraform-test-000001"
+  number_of_shards = 1
+  number_of_replicas = 1
+  aliases = jsonencode({
+    "terraform-test" = {
+      "is_write_index" = true
+    }
+  })
+
+  depends_on = [elasticsearch_index_template.test]
+}
+`
+	testAccElasticsearchIndexFieldMappings = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    properties = {
+      title = {
+        type    = "text"
+        copy_to = ["all_text"]
+        meta = {
+          unit = "words"
+        }
+        fields = {
+          raw = {
+            type         = "keyword"
+            ignore_above = 256
+          }
+        }
+      }
+      status = {
+        type       = "keyword"
+        null_value = "unknown"
+      }
+      all_text = {
+        type = "text"
+      }
+    }
+  })
+}
+`
+	testAccElasticsearchIndexMissingSynonymsSet = `
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  analysis_filter = jsonencode({
+    terraform_synonyms = {
+      type         = "synonym_graph"
+      synonyms_set = "terraform-test-missing"
+      updateable   = true
+    }
+  })
+  analysis_analyzer = jsonencode({
+    terraform_search = {
+      type      = "custom"
+      tokenizer = "standard"
+      filter    = ["lowercase", "terraform_synonyms"]
+    }
+  })
+  validate_synonyms_sets = true
+}
+`
+)
+
+func TestAccElasticsearchIndex(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndex,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexUpdate1,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexUpdateForceDestroy,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndexAnalysis(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexAnalysis,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_metadata(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndex,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_index.test", "uuid"),
+					resource.TestCheckResourceAttrSet("elasticsearch_index.test", "creation_date"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "provided_name", "terraform-test"),
+				),
+			},
+			{
+				// the metadata is only read, re-applying the config must not
+				// plan any change
+				Config:   testAccElasticsearchIndex,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_runtimeFields(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(runtimeFieldsMinimalVersion) {
+				t.Skip("Runtime fields only supported on ES >= 7.11")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexRuntimeFields,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+				),
+			},
+			{
+				// the runtime section is passed through as is, re-applying the
+				// config must not plan a replacement
+				Config:   testAccElasticsearchIndexRuntimeFields,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_similarity(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexSimilarity,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "similarity", `{"short_fields":{"b":"0.3","k1":"1.2","type":"BM25"}}`),
+				),
+			},
+			{
+				Config:      testAccElasticsearchIndexMissingSimilarity,
+				ExpectError: regexp.MustCompile(`mappings reference similarity "short_fields" which is not defined`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_validateMapping(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	_, allowed := esClient.(*elastic7.Client)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("typeless mappings only supported on ES >= 7.0")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexValidateMapping("keyword_lowercase", "not_a_type"),
+				ExpectError: regexp.MustCompile(`the mappings are invalid`),
+			},
+			{
+				Config:      testAccElasticsearchIndexValidateMapping("missing_normalizer", "keyword"),
+				ExpectError: regexp.MustCompile(`the mappings are invalid`),
+			},
+			{
+				Config: testAccElasticsearchIndexValidateMapping("keyword_lowercase", "keyword"),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					checkElasticsearchIndexValidateMappingCleanedUp,
+				),
+			},
+		},
+	})
+}
+
+// checkElasticsearchIndexValidateMappingCleanedUp checks the temporary indices
+// validating the mappings were deleted
+func checkElasticsearchIndexValidateMappingCleanedUp(s *terraform.State) error {
+	meta := testAccProvider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	var body json.RawMessage
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/terraform-validate-mapping-*",
+			Params: url.Values{"expand_wildcards": []string{"all"}},
+		})
+		if err != nil {
+			return err
+		}
+		body = res.Body
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+
+	var indices map[string]interface{}
+	if err := json.Unmarshal(body, &indices); err != nil {
+		return err
+	}
+	if len(indices) > 0 {
+		return fmt.Errorf("expected the indices validating the mappings to be deleted, got %s", body)
+	}
+
+	return nil
+}
+
+func TestAccElasticsearchIndex_mappingsDynamic(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	_, allowed := esClient.(*elastic7.Client)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("mappings_dynamic only supported on ES >= 7.0")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexMappingsDynamicStrict,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_dynamic", "strict"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexMappingsDynamicFalse,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_dynamic", "false"),
+				),
+			},
+			{
+				Config:      testAccElasticsearchIndexMappingsDynamicConflict,
+				ExpectError: regexp.MustCompile(`mappings_dynamic "strict" conflicts with the dynamic parameter "false" of mappings`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_mappingsDetection(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	_, allowed := esClient.(*elastic7.Client)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("mappings_date_detection only supported on ES >= 7.0")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexMappingsDetection,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_date_detection", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_numeric_detection", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_dynamic_date_formats.#", "2"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_dynamic_date_formats.1", "yyyy/MM/dd||epoch_millis"),
+				),
+			},
+			{
+				Config:   testAccElasticsearchIndexMappingsDetection,
+				PlanOnly: true,
+			},
+			{
+				// updated in place
+				Config: testAccElasticsearchIndexMappingsDetectionUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_date_detection", "false"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_dynamic_date_formats.#", "1"),
+				),
+			},
+			{
+				Config:      testAccElasticsearchIndexMappingsDetectionConflict,
+				ExpectError: regexp.MustCompile(`mappings_numeric_detection true conflicts with the numeric_detection parameter false of mappings`),
+			},
+		},
+	})
+}
+
+func TestFlattenIndexMappingsDetection(t *testing.T) {
+	for _, test := range []struct {
+		mappings map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			map[string]interface{}{},
+			map[string]interface{}{"mappings_date_detection": true, "mappings_numeric_detection": false, "mappings_dynamic_date_formats": []interface{}{}},
+		},
+		{
+			map[string]interface{}{"date_detection": false, "numeric_detection": "true", "dynamic_date_formats": []interface{}{"yyyy/MM/dd"}},
+			map[string]interface{}{"mappings_date_detection": false, "mappings_numeric_detection": true, "mappings_dynamic_date_formats": []interface{}{"yyyy/MM/dd"}},
+		},
+	} {
+		detection := flattenIndexMappingsDetection(test.mappings)
+		if !reflect.DeepEqual(detection, test.expected) {
+			t.Errorf("expected the detection parameters %v for %v, got %v", test.expected, test.mappings, detection)
+		}
+	}
+
+	// the empty formats keep the defaults
+	detection := indexMappingsDetection(func(key string) (interface{}, bool) {
+		switch key {
+		case "mappings_numeric_detection":
+			return false, true
+		case "mappings_dynamic_date_formats":
+			return []interface{}{}, true
+		}
+		return nil, false
+	})
+	expected := map[string]interface{}{"numeric_detection": false}
+	if !reflect.DeepEqual(detection, expected) {
+		t.Errorf("expected the detection parameters %v, got %v", expected, detection)
+	}
+}
+
+func TestValidateIndexDynamicDateFormat(t *testing.T) {
+	for _, format := range []string{
+		"strict_date_optional_time",
+		"epoch_millis",
+		"date",
+		"yyyy/MM/dd HH:mm:ss",
+		"yyyy-MM-dd'T'HH:mm:ss.SSSZ",
+		"yyyy-MM-dd[ HH:mm]",
+		"yyyy/MM/dd||epoch_second",
+	} {
+		if _, errs := validateIndexDynamicDateFormat(format, "mappings_dynamic_date_formats"); len(errs) != 0 {
+			t.Errorf("expected %q to be valid, got %v", format, errs)
+		}
+	}
+	for _, format := range []string{
+		"",
+		"yyyy/MM/dd||",
+		"yyyy-MM-ddTHH:mm:ss",
+		"yyyy-MM-dd'T",
+		"yyyy-MM-dd[ HH:mm",
+		"yyyy-MM-dd]",
+		"{yyyy}",
+	} {
+		if _, errs := validateIndexDynamicDateFormat(format, "mappings_dynamic_date_formats"); len(errs) == 0 {
+			t.Errorf("expected %q to be invalid", format)
+		}
+	}
+}
+
+func TestAccElasticsearchIndex_mappingsSource(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	_, allowed := esClient.(*elastic7.Client)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("mappings_source only supported on ES >= 7.0")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexMappingsSource(`
+    excludes = ["meta.*"]`),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_source.0.enabled", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_source.0.excludes.0", "meta.*"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexMappingsSource(`
+    mode     = "synthetic"
+    excludes = ["meta.*"]`),
+				ExpectError: regexp.MustCompile("can't be set with the synthetic mode"),
+			},
+			{
+				Config: testAccElasticsearchIndexMappingsSource(`
+    enabled  = false
+    includes = ["name"]`),
+				ExpectError: regexp.MustCompile("can't be set when it is disabled"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_mappingsSourceSynthetic(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(syntheticSourceMinimalVersion) {
+				t.Skip("synthetic _source only supported on ES >= 8.4")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexMappingsSource(`
+    mode = "synthetic"`),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mappings_source.0.mode", "synthetic"),
+				),
+			},
+			{
+				// no diff once read back
+				Config: testAccElasticsearchIndexMappingsSource(`
+    mode = "synthetic"`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestFlattenIndexMappingsSource(t *testing.T) {
+	for _, test := range []struct {
+		mappings map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			map[string]interface{}{},
+			map[string]interface{}{"enabled": true, "includes": []string{}, "excludes": []string{}, "mode": ""},
+		},
+		{
+			map[string]interface{}{"_source": map[string]interface{}{"excludes": []interface{}{"meta.*"}}},
+			map[string]interface{}{"enabled": true, "includes": []string{}, "excludes": []string{"meta.*"}, "mode": ""},
+		},
+		{
+			map[string]interface{}{"_source": map[string]interface{}{"mode": "disabled"}},
+			map[string]interface{}{"enabled": false, "includes": []string{}, "excludes": []string{}, "mode": ""},
+		},
+	} {
+		source := flattenIndexMappingsSource(test.mappings)
+		if !reflect.DeepEqual(source, []interface{}{test.expected}) {
+			t.Errorf("expected the _source %v for %v, got %v", test.expected, test.mappings, source)
+		}
+	}
+
+	// the defaults are left out of the mappings
+	source := indexMappingsSourceFromConfig([]interface{}{map[string]interface{}{
+		"enabled":  true,
+		"includes": []interface{}{},
+		"excludes": []interface{}{"meta.*"},
+		"mode":     "synthetic",
+	}})
+	expected := map[string]interface{}{"excludes": []string{"meta.*"}, "mode": "synthetic"}
+	if !reflect.DeepEqual(source, expected) {
+		t.Errorf("expected the _source %v, got %v", expected, source)
+	}
+
+	if ws, _ := validateIndexMappingsSourceEnabled(false, "enabled"); len(ws) != 1 {
+		t.Errorf("expected a warning for a disabled _source, got %v", ws)
+	}
+}
+
+func TestAccElasticsearchIndex_blocks(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexBlocksReadOnly,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_read_only", "true"),
+				),
+			},
+			{
+				// the block is lifted before updating the replicas
+				Config: testAccElasticsearchIndexBlocksLifted,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_read_only", "false"),
+				),
+			},
+			{
+				// the blocks are set after updating the replicas
+				Config: testAccElasticsearchIndexBlocksSet,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_read", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_write", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_blocksWrite(t *testing.T) {
+	var uuid string
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexBlocksWrite(false),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_write", "false"),
+				),
+			},
+			{
+				// the source index is frozen during a reindex
+				Config: testAccElasticsearchIndexBlocksWrite(true),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_write", "true"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexBlocksWrite(false),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_write", "false"),
+				),
+			},
+			{
+				// a block set outside of Terraform is lifted
+				PreConfig: func() {
+					err := resourceElasticsearchIndexPutSettings("terraform-test", map[string]interface{}{"blocks.write": true}, testAccProvider.Meta())
+					if err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccElasticsearchIndexBlocksWrite(false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_autoExpandReplicas(t *testing.T) {
+	var uuid string
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexReplicas(`auto_expand_replicas = "0-1"`),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "auto_expand_replicas", "0-1"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", ""),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexReplicas(`number_of_replicas = "0"`),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "auto_expand_replicas", ""),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", "0"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexReplicas(`auto_expand_replicas = "0-all"`),
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "auto_expand_replicas", "0-all"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", ""),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexReplicas(`
+  number_of_replicas   = "1"
+  auto_expand_replicas = "0-1"`),
+				ExpectError: regexp.MustCompile(`number_of_replicas conflicts with auto_expand_replicas "0-1"`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_refreshInterval(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexReplicas(`refresh_interval = "-1"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "refresh_interval", "-1"),
+				),
+			},
+			{
+				// back to the default interval, without a diff after the apply
+				Config: testAccElasticsearchIndexReplicas(""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "refresh_interval", ""),
+				),
+			},
+			{
+				Config:   testAccElasticsearchIndexReplicas(`refresh_interval = "1s"`),
+				PlanOnly: true,
+			},
+			{
+				Config: testAccElasticsearchIndexReplicas(`refresh_interval = "-1"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "refresh_interval", "-1"),
+				),
+			},
+		},
+	})
+}
+
+func TestDiffSuppressIndexRefreshInterval(t *testing.T) {
+	for _, values := range [][2]string{{"", "1s"}, {"1s", ""}, {"", ""}, {"-1", "-1"}} {
+		if !diffSuppressIndexRefreshInterval("refresh_interval", values[0], values[1], nil) {
+			t.Errorf("expected %q to be equivalent to %q", values[0], values[1])
+		}
+	}
+
+	for _, values := range [][2]string{{"", "-1"}, {"-1", ""}, {"1s", "-1"}, {"", "30s"}} {
+		if diffSuppressIndexRefreshInterval("refresh_interval", values[0], values[1], nil) {
+			t.Errorf("expected %q to differ from %q", values[0], values[1])
+		}
+	}
+}
+
+func TestAccElasticsearchIndex_blocksRestoredOnFailure(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexBlocksReadOnly,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_read_only", "true"),
+				),
+			},
+			{
+				// the block is lifted, then the update fails
+				Config:      testAccElasticsearchIndexBlocksLiftedInvalidUpdate,
+				ExpectError: regexp.MustCompile("max_result_window"),
+			},
+			{
+				PreConfig: func() {
+					settings, err := resourceElasticsearchGetIndexFlatSettings(context.Background(), "terraform-test", testAccProvider.Meta())
+					if err != nil {
+						t.Fatal(err)
+					}
+					if settings["index.blocks.read_only"] != "true" {
+						t.Fatalf("expected the read only block to be set back after the failed update, got settings %+v", settings)
+					}
+				},
+				Config: testAccElasticsearchIndexBlocksLiftedValidUpdate,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "blocks_read_only", "false"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "max_result_window", "10000"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_waitForGreen(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexWaitForGreen,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "wait_for_green", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_waitForGreenTimeout(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexWaitForGreenUnassigned,
+				ExpectError: regexp.MustCompile("got yellow with 1 unassigned shards"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_mappingLimits(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexMappingLimitsInvalid,
+				ExpectError: regexp.MustCompile("must be a positive integer"),
+			},
+			{
+				Config: testAccElasticsearchIndexMappingLimits,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mapping_depth_limit", "30"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mapping_nested_fields_limit", "60"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexMappingLimitsUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mapping_depth_limit", "40"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_sortSettings(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexSortSettings,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "codec", "best_compression"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "sort_field.1", "host"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "sort_order.0", "desc"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "soft_deletes_enabled", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "store_preload.#", "2"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "store_preload.1", "dvd"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_modeValidation(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexTimeSeriesWithoutRoutingPath,
+				ExpectError: regexp.MustCompile("routing_path is required with the time_series mode"),
+			},
+			{
+				Config:      testAccElasticsearchIndexStandardWithRoutingPath,
+				ExpectError: regexp.MustCompile("routing_path is only allowed with the time_series mode"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_timeSeriesMode(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(timeSeriesModeMinimalVersion) {
+				t.Skip("time_series index mode only supported on ES >= 8.1")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexTimeSeries,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "mode", "time_series"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "routing_path.0", "host"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIndexTimeSeriesUpdated,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestMatchResourceAttr("elasticsearch_index.test", "time_series_end_time", regexp.MustCompile("^2030-01-03T00:00:00")),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_staticAndDynamicSettings(t *testing.T) {
+	var uuid string
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIndexStaticAndDynamic,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
+				),
+			},
+			{
+				// the static change recreates the index with both changes
+				Config: testAccElasticsearchIndexStaticAndDynamicChanged,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, false),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_shards", "2"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", "0"),
+				),
+			},
+			{
+				// a dynamic only change is applied in place
+				Config: testAccElasticsearchIndexDynamicChanged,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexUUID("elasticsearch_index.test", &uuid, true),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "number_of_replicas", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_handleInvalid(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexInvalid,
+				ExpectError: regexp.MustCompile("Failed to parse mapping"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_handleInvalidDuration(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexInvalidDuration,
+				ExpectError: regexp.MustCompile("must be a time unit value"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_handleMissingPipeline(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexMissingPipeline,
+				ExpectError: regexp.MustCompile(`default_pipeline "terraform-test-missing" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_adoptExisting(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	// the index is created out of band, as if the response of the create
+	// request had been lost
+	createIndex := func(shards int) func() {
+		return func() {
+			body := fmt.Sprintf(`{"settings":{"number_of_shards":%d,"number_of_replicas":1}}`, shards)
+			esClient, err := getClient(meta.(*ProviderConf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch client := esClient.(type) {
+			case *elastic7.Client:
+				_, _ = client.DeleteIndex("terraform-test").Do(context.TODO())
+				_, err = client.CreateIndex("terraform-test").BodyString(body).Do(context.TODO())
+			case *elastic6.Client:
+				_, _ = client.DeleteIndex("terraform-test").Do(context.TODO())
+				_, err = client.CreateIndex("terraform-test").BodyString(body).Do(context.TODO())
+			default:
+				err = errors.New("Elasticsearch version not supported")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the existing index is only adopted when enabled
+				PreConfig:   createIndex(1),
+				Config:      testAccElasticsearchIndex,
+				ExpectError: regexp.MustCompile(`already exists`),
+			},
+			{
+				PreConfig:   createIndex(2),
+				Config:      testAccElasticsearchIndexAdoptExisting,
+				ExpectError: regexp.MustCompile(`the settings \[number_of_shards\] of the existing index don't match the configuration`),
+			},
+			{
+				PreConfig: createIndex(1),
+				Config:    testAccElasticsearchIndexAdoptExisting,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					resource.TestCheckResourceAttr("elasticsearch_index.test", "id", "terraform-test"),
+				),
+			},
+		},
+	})
+}
+
+// TestIndexAdoptExistingLostResponse creates an index whose create response
+// is lost, the index is created but the gateway answers with a 504 and the
+// create is retried
+func TestIndexAdoptExistingLostResponse(t *testing.T) {
+	var created bool
+	var existingMappings string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/terraform-test":
+			if !created {
+				created = true
+				w.WriteHeader(http.StatusGatewayTimeout)
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": {"type": "resource_already_exists_exception", "reason": "index [terraform-test/abc] already exists", "index": "terraform-test"}, "status": 400}`)
+		case r.Method == "GET" && r.URL.Path == "/terraform-test/_settings":
+			fmt.Fprint(w, `{"terraform-test": {"settings": {"index.number_of_shards": "1", "index.number_of_replicas": "1"}}}`)
+		case r.Method == "GET" && r.URL.Path == "/terraform-test":
+			fmt.Fprintf(w, `{"terraform-test": {"aliases": {}, "mappings": %s, "settings": {}}}`, existingMappings)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &ProviderConf{
+		rawUrl:        ts.URL,
+		parsedUrl:     parsedUrl,
+		esVersion:     "7.10.2",
+		retryOnStatus: []int{http.StatusGatewayTimeout},
+		maxRetries:    1,
+		retryBackoff:  time.Millisecond,
+		retryTimeout:  time.Second,
+	}
+	esClient, err := getClient(conf)
+	if err != nil {
+		t.Fatalf("getClient returned an error: %+v", err)
+	}
+
+	body := map[string]interface{}{
+		"settings": map[string]interface{}{"number_of_shards": "1", "number_of_replicas": "1"},
+		"mappings": map[string]interface{}{"properties": map[string]interface{}{"name": map[string]interface{}{"type": "keyword"}}},
+	}
+	for _, test := range []struct {
+		existingMappings string
+		expectedError    string
+	}{
+		{`{"properties": {"name": {"type": "keyword"}}}`, ""},
+		// the index has documents with other fields, it was created by someone else
+		{`{"properties": {"name": {"type": "keyword"}, "message": {"type": "text"}}}`, "the mappings of the existing index don't match the configuration"},
+	} {
+		created = false
+		existingMappings = test.existingMappings
+
+		_, err := esClient.(*elastic7.Client).CreateIndex("terraform-test").BodyJson(body).Do(context.TODO())
+		index, err := resourceElasticsearchIndexAdoptExisting(context.TODO(), err, "terraform-test", body, conf)
+		if test.expectedError == "" {
+			if err != nil || index != "terraform-test" {
+				t.Errorf("expected the index to be adopted, got %q and %+v", index, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+			t.Errorf("expected the error %q, got %+v", test.expectedError, err)
+		}
+	}
+}
+
+func TestIndexCreateSoftDeletesDisabled(t *testing.T) {
+	var createBody map[string]interface{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != "PUT" || r.URL.Path != "/terraform-test" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&createBody); err != nil {
+			t.Errorf("unexpected create body: %s", err)
+		}
+		// the index isn't read back, only the create body matters
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error": {"type": "illegal_argument_exception", "reason": "stop"}, "status": 400}`)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchIndex().Schema, map[string]interface{}{
+		"name":                 "terraform-test",
+		"number_of_shards":     "1",
+		"soft_deletes_enabled": false,
+	})
+	err = resourceElasticsearchIndexCreate(d, &ProviderConf{rawUrl: ts.URL, parsedUrl: parsedUrl, esVersion: "7.10.2"})
+	if err == nil || !strings.Contains(err.Error(), "stop") {
+		t.Fatalf("expected the create to fail, got %v", err)
+	}
+
+	settings, _ := createBody["settings"].(map[string]interface{})
+	if value, ok := settings["soft_deletes.enabled"]; !ok || value != false {
+		t.Errorf("expected soft_deletes.enabled to be false in the create body, got %v", createBody)
+	}
+}
+
+func TestMergeIndexMappings(t *testing.T) {
+	var existing, mappings map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"dynamic": "strict", "properties": {"name": {"type": "keyword"}, "message": {"type": "text"}}}`), &existing); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{"dynamic": "false", "properties": {"name": {"type": "keyword", "ignore_above": 256}}}`), &mappings); err != nil {
+		t.Fatal(err)
+	}
+
+	var expected map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"dynamic": "false", "properties": {"name": {"type": "keyword", "ignore_above": 256}, "message": {"type": "text"}}}`), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if merged := mergeIndexMappings(existing, mappings); !reflect.DeepEqual(merged, expected) {
+		t.Errorf("expected %v, got %v", expected, merged)
+	}
+}
+
+func TestElastic6IndexMappings(t *testing.T) {
+	typeless := map[string]interface{}{"properties": map[string]interface{}{"name": map[string]interface{}{"type": "keyword"}}}
+	if mappings := elastic6IndexMappings(typeless); !reflect.DeepEqual(mappings, map[string]interface{}{"_doc": typeless}) {
+		t.Errorf("expected the typeless mappings in _doc, got %v", mappings)
+	}
+
+	typed := map[string]interface{}{"people": typeless}
+	if mappings := elastic6IndexMappings(typed); !reflect.DeepEqual(mappings, typed) {
+		t.Errorf("expected the typed mappings to be kept, got %v", mappings)
+	}
 }
 
-resource "elasticsearch_index" "test" {
-  name = "terraform-test-000001"
-  number_of_shards = 1
-  number_of_replicas = 1
-  aliases = jsonencode({
-    "terraform-test" = {
-      "is_write_index" = true
-    }
-  })
+func TestIndexRemainingTimeout(t *testing.T) {
+	if remaining := indexRemainingTimeout(time.Now().Add(-time.Minute)); remaining != 0 {
+		t.Errorf("expected no time left after the deadline, got %s", remaining)
+	}
+	if remaining := indexRemainingTimeout(time.Now().Add(time.Minute)); remaining <= 0 || remaining > time.Minute {
+		t.Errorf("expected up to a minute left, got %s", remaining)
+	}
+}
 
-  depends_on = [elasticsearch_index_template.test]
+func TestIndexJSONEqual(t *testing.T) {
+	for _, test := range []struct {
+		configured interface{}
+		existing   interface{}
+		equal      bool
+	}{
+		{nil, map[string]interface{}{}, true},
+		{nil, nil, true},
+		{nil, map[string]interface{}{"alias": map[string]interface{}{}}, false},
+		{map[string]interface{}{"is_write_index": true}, map[string]interface{}{"is_write_index": true}, true},
+		{map[string]interface{}{"ignore_above": 256.0}, map[string]interface{}{"ignore_above": "256"}, true},
+		{map[string]interface{}{"a": []interface{}{"x"}}, map[string]interface{}{"a": []interface{}{"x", "y"}}, false},
+	} {
+		if equal := indexJSONEqual(test.configured, test.existing); equal != test.equal {
+			t.Errorf("expected %v and %v to be equal %t", test.configured, test.existing, test.equal)
+		}
+	}
 }
-`
-)
 
-func TestAccElasticsearchIndex(t *testing.T) {
+func TestIndexSettingsMismatches(t *testing.T) {
+	settings := map[string]interface{}{
+		"number_of_shards":   1,
+		"number_of_replicas": 1,
+		"codec":              "best_compression",
+		"analysis":           map[string]interface{}{},
+	}
+	existing := map[string]interface{}{
+		"index.number_of_shards":   "1",
+		"index.number_of_replicas": "2",
+		"index.provided_name":      "terraform-test",
+	}
+
+	mismatches := indexSettingsMismatches(settings, existing)
+	if fmt.Sprint(mismatches) != "[codec number_of_replicas]" {
+		t.Errorf("expected the mismatches [codec number_of_replicas], got %v", mismatches)
+	}
+}
+
+func TestAccElasticsearchIndex_fieldMappings(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	// the meta of the fields was added in 7.6
+	fieldMetaMinimalVersion, _ := version.NewVersion("7.6.0")
+
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(fieldMetaMinimalVersion) {
+				t.Skip("field meta only supported on ES >= 7.6")
+			}
+		},
 		Providers:    testAccProviders,
 		CheckDestroy: checkElasticsearchIndexDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccElasticsearchIndex,
+				Config: testAccElasticsearchIndexFieldMappings,
 				Check: resource.ComposeTestCheckFunc(
 					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					checkElasticsearchIndexFieldMapping("elasticsearch_index.test", "title", `{"copy_to":["all_text"],"fields":{"raw":{"ignore_above":256,"type":"keyword"}},"meta":{"unit":"words"},"type":"text"}`),
+					checkElasticsearchIndexFieldMapping("elasticsearch_index.test", "status", `{"null_value":"unknown","type":"keyword"}`),
 				),
 			},
 			{
-				Config: testAccElasticsearchIndexUpdate1,
-				Check: resource.ComposeTestCheckFunc(
-					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
-				),
+				// the mappings returned by Elasticsearch don't produce a diff
+				Config:   testAccElasticsearchIndexFieldMappings,
+				PlanOnly: true,
 			},
+		},
+	})
+}
+
+// checkElasticsearchIndexFieldMapping compares the mapping of a top level
+// field of the index with the expected JSON
+func checkElasticsearchIndexFieldMapping(name, field, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		client, ok := esClient.(*elastic7.Client)
+		if !ok {
+			return errors.New("Elasticsearch version not supported")
+		}
+		mappings, err := client.GetMapping().Index(rs.Primary.ID).Do(context.TODO())
+		if err != nil {
+			return err
+		}
+
+		var index struct {
+			Mappings struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"mappings"`
+		}
+		body, err := json.Marshal(mappings[rs.Primary.ID])
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(body, &index); err != nil {
+			return err
+		}
+
+		var actual, want interface{}
+		if err := json.Unmarshal(index.Mappings.Properties[field], &actual); err != nil {
+			return fmt.Errorf("field %s not found in the mappings of %s: %v", field, rs.Primary.ID, err)
+		}
+		if err := json.Unmarshal([]byte(expected), &want); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(actual, want) {
+			return fmt.Errorf("expected the mapping %s for the field %s, got %s", expected, field, index.Mappings.Properties[field])
+		}
+		return nil
+	}
+}
+
+func TestIndexAnalysisSynonymsSets(t *testing.T) {
+	synonymsSets, err := indexAnalysisSynonymsSets(`{
+		"my_synonyms": {"type": "synonym_graph", "synonyms_set": "my-set", "updateable": true},
+		"my_stop": {"type": "stop", "stopwords": ["a"]}
+	}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fmt.Sprint(synonymsSets) != "map[my_synonyms:my-set]" {
+		t.Errorf("expected the synonyms sets map[my_synonyms:my-set], got %v", synonymsSets)
+	}
+
+	if _, err := indexAnalysisSynonymsSets(`{"my_synonyms": {"type": "synonym", "synonyms_set": "my-set", "synonyms": ["a, b"]}}`); err == nil {
+		t.Error("expected an error when setting both synonyms_set and synonyms")
+	}
+	if _, err := indexAnalysisSynonymsSets(`{"my_stop": {"type": "stop", "synonyms_set": "my-set"}}`); err == nil {
+		t.Error("expected an error when setting synonyms_set on a stop filter")
+	}
+}
+
+func TestAccElasticsearchIndex_missingSynonymsSet(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(synonymsSetsMinimalVersion) {
+				t.Skip("synonyms sets only supported on ES >= 8.10")
+			}
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
 			{
-				Config: testAccElasticsearchIndexUpdateForceDestroy,
+				Config:      testAccElasticsearchIndexMissingSynonymsSet,
+				ExpectError: regexp.MustCompile(`synonyms_set "terraform-test-missing" of the filter "terraform_synonyms" does not exist`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchIndex_validateAliases(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: checkElasticsearchIndexDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchIndexInvalidAliasFilter,
+				ExpectError: regexp.MustCompile(`the filter of the alias "terraform-test-alias" is invalid`),
+			},
+			{
+				Config: testAccElasticsearchIndexAliasFilter,
 				Check: resource.ComposeTestCheckFunc(
-					checkElasticsearchIndexUpdated("elasticsearch_index.test"),
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
 				),
 			},
 		},
 	})
 }
 
-func TestAccElasticsearchIndexAnalysis(t *testing.T) {
+func TestAccElasticsearchIndex_writeAliasConflicts(t *testing.T) {
 	resource.Test(t, resource.TestCase{
-		PreCheck:     func() { testAccPreCheck(t) },
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
 		Providers:    testAccProviders,
 		CheckDestroy: checkElasticsearchIndexDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccElasticsearchIndexAnalysis,
+				Config:      testAccElasticsearchIndexRepeatedAlias,
+				ExpectError: regexp.MustCompile(`alias "terraform-test-alias" is declared more than once in aliases`),
+			},
+			{
+				Config: testAccElasticsearchIndexWriteAlias,
 				Check: resource.ComposeTestCheckFunc(
 					checkElasticsearchIndexExists("elasticsearch_index.test"),
 				),
 			},
+			{
+				Config:      testAccElasticsearchIndexConflictingWriteAlias,
+				ExpectError: regexp.MustCompile(`alias "terraform-test-alias" already has the write index "terraform-test"`),
+			},
+			{
+				Config:      testAccElasticsearchIndexConflictingHiddenAlias,
+				ExpectError: regexp.MustCompile(`alias "terraform-test-alias" must be hidden on all its indices or on none`),
+			},
 		},
 	})
 }
 
-func TestAccElasticsearchIndex_handleInvalid(t *testing.T) {
+func TestAccElasticsearchIndex_writeAliasRolledOver(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
@@ -288,13 +2137,64 @@ func TestAccElasticsearchIndex_handleInvalid(t *testing.T) {
 		CheckDestroy: checkElasticsearchIndexDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config:      testAccElasticsearchIndexInvalid,
-				ExpectError: regexp.MustCompile("Failed to parse mapping"),
+				Config: testAccElasticsearchIndexWriteAlias,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexExists("elasticsearch_index.test"),
+					checkElasticsearchIndexRollover("terraform-test-alias", "terraform-test-000002"),
+				),
+			},
+			{
+				// the write index of the alias is now the new index
+				Config:   testAccElasticsearchIndexWriteAlias,
+				PlanOnly: true,
+			},
+			{
+				Config: testAccElasticsearchIndexWriteAlias,
+				Check: resource.ComposeTestCheckFunc(
+					checkElasticsearchIndexDelete("terraform-test-000002"),
+				),
 			},
 		},
 	})
 }
 
+// checkElasticsearchIndexRollover rolls the alias over to a new index
+func checkElasticsearchIndexRollover(alias, newIndex string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		esClient, err := getClient(testAccProvider.Meta().(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = client.RolloverIndex(alias).NewIndex(newIndex).Do(context.TODO())
+		case *elastic6.Client:
+			_, err = client.RolloverIndex(alias).NewIndex(newIndex).Do(context.TODO())
+		default:
+			err = errors.New("Elasticsearch version not supported")
+		}
+		return err
+	}
+}
+
+func checkElasticsearchIndexDelete(index string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		esClient, err := getClient(testAccProvider.Meta().(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = client.DeleteIndex(index).Do(context.TODO())
+		case *elastic6.Client:
+			_, err = client.DeleteIndex(index).Do(context.TODO())
+		default:
+			err = errors.New("Elasticsearch version not supported")
+		}
+		return err
+	}
+}
+
 func TestAccElasticsearchIndex_importBasic(t *testing.T) {
 	resource.Test(t, resource.TestCase{
 		PreCheck:     func() { testAccPreCheck(t) },
@@ -311,6 +2211,11 @@ func TestAccElasticsearchIndex_importBasic(t *testing.T) {
 				ImportStateVerifyIgnore: []string{
 					// not returned from the API
 					"force_destroy",
+					"validate_aliases",
+					"validate_mapping",
+					"validate_pipeline",
+					"validate_synonyms_sets",
+					"wait_for_green",
 				},
 			},
 		},
@@ -352,8 +2257,9 @@ func TestAccElasticsearchIndex_rolloverAliasXpack(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 				ImportStateVerifyIgnore: []string{
-					"aliases",       // not handled by this provider
-					"force_destroy", // not returned from the API
+					"aliases",        // not handled by this provider
+					"force_destroy",  // not returned from the API
+					"wait_for_green", // not returned from the API
 				},
 				ImportStateCheck: checkElasticsearchIndexRolloverAliasState("terraform-test"),
 			},
@@ -402,8 +2308,9 @@ func TestAccElasticsearchIndex_rolloverAliasOpendistro(t *testing.T) {
 				ImportState:       true,
 				ImportStateVerify: true,
 				ImportStateVerifyIgnore: []string{
-					"aliases",       // not handled by this provider
-					"force_destroy", // not returned from the API
+					"aliases",        // not handled by this provider
+					"force_destroy",  // not returned from the API
+					"wait_for_green", // not returned from the API
 				},
 				ImportStateCheck: checkElasticsearchIndexRolloverAliasState("terraform-test"),
 			},
@@ -441,6 +2348,55 @@ func checkElasticsearchIndexExists(name string) resource.TestCheckFunc {
 	}
 }
 
+// checkElasticsearchIndexUUID compares the UUID of the index with the one of
+// the previous step, which changes when the index is recreated
+func checkElasticsearchIndexUUID(name string, uuid *string, same bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+		var settings map[string]interface{}
+
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			resp, err := client.IndexGetSettings(rs.Primary.ID).FlatSettings(true).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			settings = resp[rs.Primary.ID].Settings
+		case *elastic6.Client:
+			resp, err := client.IndexGetSettings(rs.Primary.ID).FlatSettings(true).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			settings = resp[rs.Primary.ID].Settings
+		default:
+			return errors.New("Elasticsearch version not supported")
+		}
+
+		current, _ := settings["index.uuid"].(string)
+		previous := *uuid
+		*uuid = current
+		if previous == "" {
+			return nil
+		}
+		if same && current != previous {
+			return fmt.Errorf("expected index %s to be updated in place, it was recreated", rs.Primary.ID)
+		}
+		if !same && current == previous {
+			return fmt.Errorf("expected index %s to be recreated, it was updated in place", rs.Primary.ID)
+		}
+		return nil
+	}
+}
+
 func checkElasticsearchIndexUpdated(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -490,6 +2446,27 @@ func checkElasticsearchIndexUpdated(name string) resource.TestCheckFunc {
 	}
 }
 
+func testAccElasticsearchIndexBlocksWrite(blocked bool) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 0
+  blocks_write       = %t
+}
+`, blocked)
+}
+
+func testAccElasticsearchIndexReplicas(replicas string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index" "test" {
+  name             = "terraform-test"
+  number_of_shards = 1
+  %s
+}
+`, replicas)
+}
+
 func checkElasticsearchIndexDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_index" {
@@ -603,3 +2580,63 @@ func checkElasticsearchIndexRolloverAliasDestroy(provider *schema.Provider, alia
 		return nil
 	}
 }
+
+func testAccElasticsearchIndexValidateMapping(normalizer, fieldType string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index" "test" {
+  name               = "terraform-test"
+  number_of_shards   = 1
+  number_of_replicas = 1
+  validate_mapping   = true
+  analysis_normalizer = jsonencode({
+    keyword_lowercase = {
+      type   = "custom"
+      filter = ["lowercase"]
+    }
+  })
+  mappings = jsonencode({
+    properties = {
+      code = {
+        type       = "%s"
+        normalizer = "%s"
+      }
+    }
+  })
+}
+`, fieldType, normalizer)
+}
+
+func testAccElasticsearchIndexMappingsSource(source string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_index" "test" {
+  name = "terraform-test"
+  number_of_shards = 1
+  number_of_replicas = 1
+  mappings = jsonencode({
+    properties = {
+      name = {
+        type = "keyword"
+      }
+    }
+  })
+
+  mappings_source {%s
+  }
+}
+`, source)
+}
+
+func TestIndexStaticSettingsForceNew(t *testing.T) {
+	indexSchema := resourceElasticsearchIndex().Schema
+	for _, key := range staticSettingsKeys {
+		schemaName := strings.Replace(key, ".", "_", -1)
+		s, ok := indexSchema[schemaName]
+		if !ok {
+			t.Errorf("expected the static setting %s to have the attribute %s", key, schemaName)
+			continue
+		}
+		if !s.ForceNew {
+			t.Errorf("expected the static setting %s to recreate the index when it changes", key)
+		}
+	}
+}
@@ -4,13 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
+var ingestPipelineIfVersionMinimalVersion, _ = version.NewVersion("7.16.0")
+
 func resourceElasticsearchIngestPipeline() *schema.Resource {
 	return &schema.Resource{
 		Create: resourceElasticsearchIngestPipelineCreate,
@@ -29,6 +38,12 @@ func resourceElasticsearchIngestPipeline() *schema.Resource {
 				Required:         true,
 				ValidateFunc:     validation.StringIsJSON,
 			},
+			"version": {
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Computed:    true,
+				Description: "The version of the pipeline, used when `body` has no `version`. From ElasticSearch 7.16, the updates bumping the version require the version of the pipeline in the cluster to still be the one last read, so concurrent modifications are detected.",
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -38,7 +53,7 @@ func resourceElasticsearchIngestPipeline() *schema.Resource {
 
 func resourceElasticsearchIngestPipelineCreate(d *schema.ResourceData, meta interface{}) error {
 
-	err := resourceElasticsearchPutIngestPipeline(d, meta)
+	err := resourceElasticsearchPutIngestPipeline(d, meta, 0)
 	if err != nil {
 		return err
 	}
@@ -67,9 +82,17 @@ func resourceElasticsearchIngestPipelineRead(d *schema.ResourceData, meta interf
 		return err
 	}
 
+	var pipeline struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(result), &pipeline); err != nil {
+		return fmt.Errorf("error unmarshalling pipeline body: %+v: %+v", err, result)
+	}
+
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
+	ds.set("version", pipeline.Version)
 	return ds.err
 }
 
@@ -105,7 +128,15 @@ func elastic6IngestGetPipeline(client *elastic6.Client, id string) (string, erro
 }
 
 func resourceElasticsearchIngestPipelineUpdate(d *schema.ResourceData, meta interface{}) error {
-	return resourceElasticsearchPutIngestPipeline(d, meta)
+	// when the version is bumped, the update fails if the pipeline was
+	// modified since the version read last. Elasticsearch rejects the updates
+	// which keep the version with if_version, they are sent without it
+	ifVersion := 0
+	if d.HasChange("version") {
+		oldVersion, _ := d.GetChange("version")
+		ifVersion = oldVersion.(int)
+	}
+	return resourceElasticsearchPutIngestPipeline(d, meta, ifVersion)
 }
 
 func resourceElasticsearchIngestPipelineDelete(d *schema.ResourceData, meta interface{}) error {
@@ -132,18 +163,29 @@ func resourceElasticsearchIngestPipelineDelete(d *schema.ResourceData, meta inte
 	return nil
 }
 
-func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interface{}) error {
+func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interface{}, ifVersion int) error {
 	name := d.Get("name").(string)
-	body := d.Get("body").(string)
+	body, err := ingestPipelineBodyWithVersion(d)
+	if err != nil {
+		return err
+	}
 
-	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
 		return err
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		_, err = client.IngestPutPipeline(name).BodyString(body).Do(context.TODO())
+		var esVersion *version.Version
+		esVersion, err = esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		if ifVersion != 0 && !esVersion.LessThan(ingestPipelineIfVersionMinimalVersion) {
+			err = elastic7PutIngestPipelineIfVersion(client, name, body, ifVersion)
+		} else {
+			_, err = client.IngestPutPipeline(name).BodyString(body).Do(context.TODO())
+		}
 	case *elastic6.Client:
 		_, err = client.IngestPutPipeline(name).BodyString(body).Do(context.TODO())
 	default:
@@ -152,3 +194,64 @@ func resourceElasticsearchPutIngestPipeline(d *schema.ResourceData, meta interfa
 
 	return err
 }
+
+// ingestPipelineBodyWithVersion adds the version attribute to the body, unless
+// the body has its own version
+func ingestPipelineBodyWithVersion(d *schema.ResourceData) (string, error) {
+	body := d.Get("body").(string)
+	pipelineVersion, ok := d.GetOk("version")
+	if !ok {
+		return body, nil
+	}
+
+	var pipeline map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &pipeline); err != nil {
+		return "", fmt.Errorf("fail to unmarshal: %v", err)
+	}
+	if _, ok := pipeline["version"]; ok {
+		return body, nil
+	}
+	pipeline["version"] = pipelineVersion
+
+	bodyWithVersion, err := json.Marshal(pipeline)
+	if err != nil {
+		return "", err
+	}
+	return string(bodyWithVersion), nil
+}
+
+func elastic7PutIngestPipelineIfVersion(client *elastic7.Client, name string, body string, ifVersion int) error {
+	path, err := uritemplates.Expand("/_ingest/pipeline/{id}", map[string]string{
+		"id": name,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Params: url.Values{
+			"if_version": []string{strconv.Itoa(ifVersion)},
+		},
+		Body: body,
+	})
+	if isIngestPipelineVersionConflict(err) {
+		return fmt.Errorf("pipeline %s was modified since version %d, refresh and plan again: %+v", name, ifVersion, err)
+	}
+	return err
+}
+
+// isIngestPipelineVersionConflict returns whether the update was rejected
+// because of if_version, Elasticsearch answers with a bad request rather than
+// a conflict
+func isIngestPipelineVersionConflict(err error) bool {
+	if elastic7.IsConflict(err) {
+		return true
+	}
+	e, ok := err.(*elastic7.Error)
+	if !ok || e.Status != http.StatusBadRequest || e.Details == nil {
+		return false
+	}
+	return strings.Contains(e.Details.Reason, "version conflict")
+}
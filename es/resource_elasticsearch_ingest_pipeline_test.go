@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -91,6 +92,94 @@ func TestAccElasticsearchIngestPipeline_importBasic(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchIngestPipeline_version(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchIngestPipelineDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchIngestPipelineVersion(1, "bar"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineExists("elasticsearch_ingest_pipeline.test"),
+					resource.TestCheckResourceAttr("elasticsearch_ingest_pipeline.test", "version", "1"),
+				),
+			},
+			{
+				Config: testAccElasticsearchIngestPipelineVersion(2, "bar"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineVersion("elasticsearch_ingest_pipeline.test", 2),
+					resource.TestCheckResourceAttr("elasticsearch_ingest_pipeline.test", "version", "2"),
+				),
+			},
+			{
+				// the processors change without a version bump
+				Config: testAccElasticsearchIngestPipelineVersion(2, "baz"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchIngestPipelineVersion("elasticsearch_ingest_pipeline.test", 2),
+					resource.TestCheckResourceAttr("elasticsearch_ingest_pipeline.test", "version", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestIsIngestPipelineVersionConflict(t *testing.T) {
+	for _, test := range []struct {
+		err      error
+		conflict bool
+	}{
+		{&elastic7.Error{Status: http.StatusConflict}, true},
+		{&elastic7.Error{Status: http.StatusBadRequest, Details: &elastic7.ErrorDetails{Reason: "version conflict, required version [1] for pipeline [test] but current version is [2]"}}, true},
+		{&elastic7.Error{Status: http.StatusBadRequest, Details: &elastic7.ErrorDetails{Reason: "pipeline [test] is missing a processor"}}, false},
+		{nil, false},
+	} {
+		if conflict := isIngestPipelineVersionConflict(test.err); conflict != test.conflict {
+			t.Errorf("expected %v to be a version conflict %t", test.err, test.conflict)
+		}
+	}
+}
+
+func testCheckElasticsearchIngestPipelineVersion(name string, expected int) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		meta := testAccProvider.Meta()
+
+		var pipelineVersion int
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			res, err := client.IngestGetPipeline(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			pipelineVersion = int(res[rs.Primary.ID].Version)
+		case *elastic6.Client:
+			res, err := client.IngestGetPipeline(rs.Primary.ID).Do(context.TODO())
+			if err != nil {
+				return err
+			}
+			pipelineVersion = int(res[rs.Primary.ID].Version)
+		default:
+			return errors.New("Elasticsearch version not supported")
+		}
+
+		if pipelineVersion != expected {
+			return fmt.Errorf("expected pipeline %s to have version %d, got %d", rs.Primary.ID, expected, pipelineVersion)
+		}
+		return nil
+	}
+}
+
 func testCheckElasticsearchIngestPipelineExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -215,3 +304,25 @@ resource "elasticsearch_ingest_pipeline" "test" {
 EOF
 }
 `
+
+func testAccElasticsearchIngestPipelineVersion(version int, value string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_ingest_pipeline" "test" {
+  name    = "terraform-test"
+  version = %d
+  body    = <<EOF
+{
+  "description" : "describe pipeline",
+  "processors" : [
+    {
+      "set" : {
+        "field": "foo",
+        "value": %q
+      }
+    }
+  ]
+}
+EOF
+}
+`, version, value)
+}
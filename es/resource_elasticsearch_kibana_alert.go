@@ -5,9 +5,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/olivere/elastic/uritemplates"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -17,6 +27,28 @@ import (
 
 var minimalKibanaVersion, _ = version.NewVersion("7.7.0")
 var notifyWhenKibanaVersion, _ = version.NewVersion("7.11.0")
+var actionFrequencyKibanaVersion, _ = version.NewVersion("8.6.0")
+var snoozeScheduleKibanaVersion, _ = version.NewVersion("8.4.0")
+var connectorTypesKibanaVersion, _ = version.NewVersion("7.13.0")
+var ruleAPIKibanaVersion, _ = version.NewVersion("7.13.0")
+
+var (
+	kibanaDurationRegexp = regexp.MustCompile(`^([0-9]+)(s|m|h|d)$`)
+	// from the largest to the smallest unit
+	kibanaDurationUnits = []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+)
+
+// The recurrence frequencies of the snooze schedules, indexed by their rrule
+// value
+var snoozeScheduleFrequencies = []string{"yearly", "monthly", "weekly", "daily", "hourly"}
 
 func resourceElasticsearchKibanaAlert() *schema.Resource {
 	return &schema.Resource{
@@ -24,6 +56,12 @@ func resourceElasticsearchKibanaAlert() *schema.Resource {
 		Read:   resourceElasticsearchKibanaAlertRead,
 		Update: resourceElasticsearchKibanaAlertUpdate,
 		Delete: resourceElasticsearchKibanaAlertDelete,
+		CustomizeDiff: customdiff.All(
+			resourceElasticsearchKibanaAlertValidateActionTypes,
+			resourceElasticsearchKibanaAlertValidateThreshold,
+			resourceElasticsearchKibanaAlertValidateNotifyWhen,
+			resourceElasticsearchKibanaAlertValidateSnoozeSchedule,
+		),
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:        schema.TypeString,
@@ -32,14 +70,19 @@ func resourceElasticsearchKibanaAlert() *schema.Resource {
 				Description: "",
 			},
 			"tags": {
-				Type:        schema.TypeSet,
-				Optional:    true,
-				Elem:        &schema.Schema{Type: schema.TypeString},
-				Description: "",
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringIsNotWhiteSpace,
+				},
+				Set:         kibanaAlertTagHash,
+				Description: "Tags of the alert, they are compared case-insensitively.",
 			},
 			"alert_type_id": {
 				Type:        schema.TypeString,
 				Optional:    true,
+				ForceNew:    true,
 				Default:     ".index-threshold",
 				Description: "The ID of the alert type that you want to call when the alert is scheduled to run, defaults to `.index-threshold`.",
 			},
@@ -52,16 +95,19 @@ func resourceElasticsearchKibanaAlert() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"interval": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:             schema.TypeString,
+							Required:         true,
+							DiffSuppressFunc: suppressEquivalentKibanaDuration,
+							Description:      "The interval between the runs of the alert, e.g. `1m`. An interval changed outside of terraform is updated back, equivalent durations like `60s` and `1m` don't produce a diff.",
 						},
 					},
 				},
 			},
 			"throttle": {
-				Type:        schema.TypeString,
-				Optional:    true,
-				Description: "",
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentKibanaDuration,
+				Description:      "How often the actions are run while the alert is active, e.g. `1h`. From Kibana 7.11 it is required when `notify_when` is `onThrottleInterval` and can't be set with the other values of `notify_when`.",
 			},
 			"notify_when": {
 				Type:        schema.TypeString,
@@ -77,28 +123,46 @@ func resourceElasticsearchKibanaAlert() *schema.Resource {
 			"consumer": {
 				Type:        schema.TypeString,
 				Optional:    true,
+				ForceNew:    true,
 				Default:     "alerts",
 				Description: "The name of the application that owns the alert. This name has to match the Kibana Feature name, as that dictates the required RBAC privileges. Defaults to `alerts`.",
 			},
+			"params_json": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ExactlyOneOf:     []string{"conditions", "params_json"},
+				Description:      "A JSON string of the `params` passed verbatim to the alert type executor, for the alert types other than `.index-threshold`, e.g. `.es-query`. Exactly one of `conditions` and `params_json` must be set.",
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+			},
 			"conditions": {
-				Type:        schema.TypeSet,
-				Required:    true,
-				MaxItems:    1,
-				MinItems:    1,
-				Description: "The conditions under which the alert is active, they create an expression to be evaluated by the alert type executor. These parameters are passed to the executor `params`. There may be specific attributes for different alert types.",
+				Type:         schema.TypeSet,
+				Optional:     true,
+				MaxItems:     1,
+				MinItems:     1,
+				ExactlyOneOf: []string{"conditions", "params_json"},
+				Description:  "The conditions under which the alert is active, they create an expression to be evaluated by the alert type executor. These parameters are passed to the executor `params`. They are the parameters of the `.index-threshold` alert type, use `params_json` for the other alert types.",
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"threshold_comparator": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The comparator of the value and the threshold: `<`, `>`, `<=`, `>=`, `between` or `notBetween`, the latter two compare the value to the two elements of `threshold`.",
+							ValidateFunc: validation.StringInSlice(kibanaAlertThresholdComparators, false),
 						},
 						"time_window_size": {
 							Type:     schema.TypeInt,
 							Required: true,
 						},
 						"time_window_unit": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							Description:  "The unit of `time_window_size`: `s`, `m`, `h` or `d`.",
+							ValidateFunc: validation.StringInSlice([]string{"s", "m", "h", "d"}, false),
 						},
 						"term_size": {
 							Type:     schema.TypeInt,
@@ -161,25 +225,326 @@ func resourceElasticsearchKibanaAlert() *schema.Resource {
 							Required: true,
 						},
 						"params": {
-							Type:     schema.TypeMap,
-							Optional: true,
+							Type:        schema.TypeMap,
+							Optional:    true,
+							Description: "The flat parameters of the action, all the values are strings. Use `params_json` for nested parameters.",
+						},
+						"params_json": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validation.StringIsJSON,
+							Description:  "The JSON parameters of the action, e.g. the `documents` of an `.index` connector. Takes precedence over `params`, use `jsonencode` so that the JSON is normalized.",
+						},
+						"frequency": {
+							Type:        schema.TypeList,
+							Optional:    true,
+							MaxItems:    1,
+							Description: "Per-action notification frequency, overriding the alert level `throttle` and `notify_when`. Only available in Kibana >= 8.6",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"summary": {
+										Type:        schema.TypeBool,
+										Optional:    true,
+										Default:     false,
+										Description: "Whether the action sends a summary of alerts or a notification per alert.",
+									},
+									"notify_when": {
+										Type:        schema.TypeString,
+										Required:    true,
+										Description: "The condition for throttling the notification: `onActionGroupChange`, `onActiveAlert`, or `onThrottleInterval`.",
+									},
+									"throttle": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										DiffSuppressFunc: suppressEquivalentKibanaDuration,
+										Description:      "The throttle interval of the action, used when `notify_when` is `onThrottleInterval`.",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"snooze_schedule": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Schedules during which the actions of the alert are silenced, e.g. planned maintenance windows. Only available in Kibana >= 8.4, it is checked when planning. They are managed with the snooze API of the Kibana UI, the public snooze schedule API only exists from Kibana 8.17.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"duration": {
+							Type:             schema.TypeString,
+							Required:         true,
+							Description:      "The duration of each snooze, e.g. `2h`.",
+							ValidateFunc:     validateKibanaAlertSnoozeDuration,
+							DiffSuppressFunc: suppressEquivalentDuration,
+						},
+						"rrule": {
+							Type:        schema.TypeList,
+							Required:    true,
+							MaxItems:    1,
+							Description: "The recurrence rule of the snooze, a snooze happening once only needs `dtstart` and `count = 1`.",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"dtstart": {
+										Type:             schema.TypeString,
+										Required:         true,
+										Description:      "The start date of the first snooze, in RFC 3339 format.",
+										ValidateFunc:     validation.IsRFC3339Time,
+										DiffSuppressFunc: suppressEquivalentRFC3339,
+									},
+									"tzid": {
+										Type:        schema.TypeString,
+										Optional:    true,
+										Default:     "UTC",
+										Description: "The time zone of the dates of the rule, defaults to `UTC`.",
+									},
+									"freq": {
+										Type:         schema.TypeString,
+										Optional:     true,
+										Description:  "The frequency of the recurrence: `yearly`, `monthly`, `weekly`, `daily` or `hourly`.",
+										ValidateFunc: validation.StringInSlice(snoozeScheduleFrequencies, false),
+									},
+									"interval": {
+										Type:        schema.TypeInt,
+										Optional:    true,
+										Description: "The interval between the recurrences, in units of `freq`.",
+									},
+									"count": {
+										Type:        schema.TypeInt,
+										Optional:    true,
+										Description: "The number of recurrences.",
+									},
+									"until": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										Description:      "The date of the end of the recurrences, in RFC 3339 format.",
+										ValidateFunc:     validation.IsRFC3339Time,
+										DiffSuppressFunc: suppressEquivalentRFC3339,
+									},
+									"byweekday": {
+										Type:        schema.TypeList,
+										Optional:    true,
+										Description: "The days of the week of the recurrences, e.g. `MO`.",
+										Elem: &schema.Schema{
+											Type:         schema.TypeString,
+											ValidateFunc: validation.StringInSlice([]string{"MO", "TU", "WE", "TH", "FR", "SA", "SU"}, false),
+										},
+									},
+								},
+							},
 						},
 					},
 				},
 			},
+			"space_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The ID of the Kibana space of the alert, the default space when not set. Alerts in a space are imported with the `space_id/alert_id` ID.",
+			},
+			"overwrite_on_conflict": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates that an update conflicting with a change of the alert made since it was read, e.g. in the Kibana UI, is applied anyway, overwriting the change. The update fails otherwise. Defaults to `false`.",
+				Default:     false,
+				Optional:    true,
+			},
+			"validate_action_types": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates that the `action_type_id` of the actions should be checked against the connector types available in Kibana when planning. Defaults to `false`.",
+				Default:     false,
+				Optional:    true,
+			},
+			"active_snoozes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The IDs of the snooze schedules currently silencing the alert.",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"is_snoozed_until": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date until which the alert is snoozed, if it is currently snoozed.",
+			},
+			"scheduled_task_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The ID of the task manager task running the alert on its schedule, it is only returned by Kibana >= 7.11.",
+			},
+			"version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The version of the alert, it is compared to the current version before the updates to detect the changes made since the alert was read.",
+			},
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceElasticsearchKibanaAlertImport,
 		},
 		Description: "Alerts allow you to define rules to detect conditions and trigger actions when those conditions are met. Alerts work by running checks on a schedule to detect conditions. When a condition is met, the alert tracks it as an alert instance and responds by triggering one or more actions. Actions typically involve interaction with Kibana services or third party integrations. For more see the [docs](https://www.elastic.co/guide/en/kibana/current/alerting-getting-started.html).",
 	}
 }
 
+// the comparators of the threshold, the range comparators take two thresholds
+var (
+	kibanaAlertThresholdComparators      = []string{"<", ">", "<=", ">=", "between", "notBetween"}
+	kibanaAlertRangeThresholdComparators = []string{"between", "notBetween"}
+)
+
+// resourceElasticsearchKibanaAlertValidateThreshold checks the number of
+// thresholds against the comparator, Kibana would otherwise only reject it when
+// the alert is created
+func resourceElasticsearchKibanaAlertValidateThreshold(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("conditions") {
+		return nil
+	}
+
+	for _, raw := range d.Get("conditions").(*schema.Set).List() {
+		conditions, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		comparator := conditions["threshold_comparator"].(string)
+		thresholds := conditions["threshold"].(*schema.Set).Len()
+
+		expected := 1
+		for _, c := range kibanaAlertRangeThresholdComparators {
+			if comparator == c {
+				expected = 2
+			}
+		}
+		if thresholds != expected {
+			return fmt.Errorf("threshold_comparator %q takes %d threshold values, got %d", comparator, expected, thresholds)
+		}
+	}
+
+	return nil
+}
+
+// resourceElasticsearchKibanaAlertValidateNotifyWhen checks the throttle
+// against notify_when, Kibana would otherwise only reject it when the alert is
+// created or updated
+func resourceElasticsearchKibanaAlertValidateNotifyWhen(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("notify_when") || !d.NewValueKnown("throttle") || meta == nil {
+		return nil
+	}
+	notifyWhen := d.Get("notify_when").(string)
+	if notifyWhen == "" {
+		return nil
+	}
+
+	// older Kibana versions have no notify_when, it is rejected when the alert
+	// is created
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(notifyWhenKibanaVersion) {
+		return nil
+	}
+
+	return validateKibanaAlertThrottle(notifyWhen, d.Get("throttle").(string))
+}
+
+// resourceElasticsearchKibanaAlertValidateSnoozeSchedule fails the plan
+// instead of creating the alert without its snooze schedules
+func resourceElasticsearchKibanaAlertValidateSnoozeSchedule(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("snooze_schedule") || meta == nil {
+		return nil
+	}
+
+	return checkKibanaAlertSnoozeScheduleVersion(len(d.Get("snooze_schedule").([]interface{})), meta)
+}
+
+func checkKibanaAlertSnoozeScheduleVersion(schedules int, meta interface{}) error {
+	if schedules == 0 {
+		return nil
+	}
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(snoozeScheduleKibanaVersion) {
+		return fmt.Errorf("Kibana Alert snooze schedules only available from Kibana >= 8.4, got version %s", elasticVersion.String())
+	}
+	return nil
+}
+
+// validateKibanaAlertThrottle checks that the throttle is only set, and is
+// set, when the actions are throttled
+func validateKibanaAlertThrottle(notifyWhen, throttle string) error {
+	switch notifyWhen {
+	case "onThrottleInterval":
+		if throttle == "" {
+			return fmt.Errorf("throttle is required when notify_when is %q", notifyWhen)
+		}
+	case "onActiveAlert", "onActionGroupChange":
+		if throttle != "" {
+			return fmt.Errorf("throttle %q can't be set when notify_when is %q, only with onThrottleInterval", throttle, notifyWhen)
+		}
+	}
+	return nil
+}
+
+func resourceElasticsearchKibanaAlertValidateActionTypes(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("validate_action_types").(bool) || !d.HasChange("actions") || !d.NewValueKnown("actions") || meta == nil {
+		return nil
+	}
+
+	var actionTypeIDs []string
+	for _, raw := range d.Get("actions").(*schema.Set).List() {
+		if action, ok := raw.(map[string]interface{}); ok {
+			actionTypeIDs = append(actionTypeIDs, action["action_type_id"].(string))
+		}
+	}
+	if len(actionTypeIDs) == 0 {
+		return nil
+	}
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	var available []string
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		available, err = kibanaGetConnectorTypes(client, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, actionTypeID := range actionTypeIDs {
+		if i := sort.SearchStrings(available, actionTypeID); i == len(available) || available[i] != actionTypeID {
+			return fmt.Errorf("action_type_id %q is not an available connector type, expected one of %v", actionTypeID, available)
+		}
+	}
+
+	return nil
+}
+
 func resourceElasticsearchKibanaAlertCreate(d *schema.ResourceData, meta interface{}) error {
 	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
 	if err != nil {
 		return err
 	}
+	// the snooze schedules are set once the alert exists, they are checked
+	// first not to leave an alert without them
+	err = checkKibanaAlertSnoozeScheduleVersion(len(d.Get("snooze_schedule").([]interface{})), meta)
+	if err != nil {
+		return err
+	}
 
 	id, err := resourceElasticsearchPostKibanaAlert(d, meta)
 	if err != nil {
@@ -189,7 +554,16 @@ func resourceElasticsearchKibanaAlertCreate(d *schema.ResourceData, meta interfa
 	log.Printf("[INFO] Kibana Alert (%s) created", id)
 	d.SetId(id)
 
-	return nil
+	// older Kibana versions ignore enabled in the body and create the alert
+	// enabled
+	if !d.Get("enabled").(bool) {
+		err = resourceElasticsearchKibanaAlertSetEnabled(d, meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchKibanaAlertUpdateSnoozeSchedules(d, meta)
 }
 
 func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface{}) error {
@@ -198,8 +572,13 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 		return err
 	}
 
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
 	id := d.Id()
-	spaceID := ""
+	spaceID := d.Get("space_id").(string)
 
 	var alert kibana.Alert
 
@@ -210,7 +589,7 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		alert, err = kibanaGetAlert(client, id, spaceID)
+		alert, err = kibanaGetAlert(client, id, spaceID, elasticVersion)
 	default:
 		err = fmt.Errorf("Kibana Alert endpoint only available from Kibana >= 7.7, got version < 7.0.0")
 	}
@@ -226,19 +605,43 @@ func resourceElasticsearchKibanaAlertRead(d *schema.ResourceData, meta interface
 	}
 
 	schedule := make([]map[string]interface{}, 0, 1)
-	schedule = append(schedule, map[string]interface{}{"interval": alert.Schedule.Interval})
+	schedule = append(schedule, map[string]interface{}{"interval": normalizeKibanaDuration(alert.Schedule.Interval)})
 
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", alert.Name)
-	ds.set("tags", alert.Tags)
+	ds.set("tags", normalizeKibanaAlertTags(alert.Tags, d.Get("tags").(*schema.Set)))
 	ds.set("alert_type_id", alert.AlertTypeID)
 	ds.set("schedule", schedule)
-	ds.set("throttle", alert.Throttle)
-	ds.set("notify_when", alert.NotifyWhen)
+	ds.set("throttle", normalizeKibanaDuration(alert.Throttle))
+	// older Kibana versions have no notify_when, it is left as configured
+	if elasticVersion.GreaterThanOrEqual(notifyWhenKibanaVersion) {
+		ds.set("notify_when", alert.NotifyWhen)
+	}
 	ds.set("enabled", alert.Enabled)
 	ds.set("consumer", alert.Consumer)
-	ds.set("conditions", flattenKibanaAlertConditions(alert.Params))
-	// ds.set("actions", alert.Actions) // TODO
+	if kibanaAlertUsesParamsJSON(d, alert) {
+		params, err := json.Marshal(alert.Params)
+		if err != nil {
+			return err
+		}
+		paramsJSON, err := structure.NormalizeJsonString(string(params))
+		if err != nil {
+			return err
+		}
+		ds.set("params_json", paramsJSON)
+	} else {
+		ds.set("conditions", flattenKibanaAlertConditions(alert.Params))
+	}
+	actions, err := flattenKibanaAlertActions(alert.Actions, kibanaAlertActionsWithJSONParams(d.Get("actions").(*schema.Set).List()))
+	if err != nil {
+		return err
+	}
+	ds.set("actions", actions)
+	ds.set("snooze_schedule", flattenKibanaAlertSnoozeSchedules(alert.SnoozeSchedule))
+	ds.set("active_snoozes", alert.ActiveSnoozes)
+	ds.set("is_snoozed_until", alert.IsSnoozedUntil)
+	ds.set("scheduled_task_id", alert.ScheduledTaskID)
+	ds.set("version", alert.Version)
 
 	return ds.err
 }
@@ -249,7 +652,37 @@ func resourceElasticsearchKibanaAlertUpdate(d *schema.ResourceData, meta interfa
 		return err
 	}
 
-	return resourceElasticsearchPutKibanaAlert(d, meta)
+	err = resourceElasticsearchPutKibanaAlert(d, meta)
+	if err != nil {
+		return err
+	}
+
+	// the update endpoint doesn't change the enabled state
+	if d.HasChange("enabled") {
+		return resourceElasticsearchKibanaAlertSetEnabled(d, meta)
+	}
+	return nil
+}
+
+func resourceElasticsearchKibanaAlertSetEnabled(d *schema.ResourceData, meta interface{}) error {
+	spaceID := d.Get("space_id").(string)
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		return kibanaSetAlertEnabled(client, d.Id(), spaceID, d.Get("enabled").(bool), elasticVersion)
+	default:
+		return fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	}
 }
 
 func resourceElasticsearchKibanaAlertDelete(d *schema.ResourceData, meta interface{}) error {
@@ -259,7 +692,12 @@ func resourceElasticsearchKibanaAlertDelete(d *schema.ResourceData, meta interfa
 	}
 
 	id := d.Id()
-	spaceID := ""
+	spaceID := d.Get("space_id").(string)
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
 
 	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
 	if err != nil {
@@ -268,7 +706,7 @@ func resourceElasticsearchKibanaAlertDelete(d *schema.ResourceData, meta interfa
 
 	switch client := kibanaClient.(type) {
 	case *elastic7.Client:
-		err = kibanaDeleteAlert(client, id, spaceID)
+		err = kibanaDeleteAlert(client, id, spaceID, elasticVersion)
 	default:
 		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
 	}
@@ -281,13 +719,35 @@ func resourceElasticsearchKibanaAlertDelete(d *schema.ResourceData, meta interfa
 }
 
 func resourceElasticsearchPostKibanaAlert(d *schema.ResourceData, meta interface{}) (string, error) {
-	spaceID := ""
+	spaceID := d.Get("space_id").(string)
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return "", err
+	}
 
 	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
 	if err != nil {
 		return "", err
 	}
 
+	alert, err := kibanaAlertFromResourceData(d, meta)
+	if err != nil {
+		return "", err
+	}
+
+	var id string
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		id, err = kibanaPostAlert(client, spaceID, alert, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	}
+
+	return id, err
+}
+
+func kibanaAlertFromResourceData(d *schema.ResourceData, meta interface{}) (kibana.Alert, error) {
 	alertSchedule := kibana.AlertSchedule{}
 	schedule := d.Get("schedule").([]interface{})
 	if len(schedule) > 0 {
@@ -296,12 +756,20 @@ func resourceElasticsearchPostKibanaAlert(d *schema.ResourceData, meta interface
 	}
 	actions, err := expandKibanaActionsList(d.Get("actions").(*schema.Set).List())
 	if err != nil {
-		return "", err
+		return kibana.Alert{}, err
 	}
 
 	tags := expandStringList(d.Get("tags").(*schema.Set).List())
 
-	conditions := d.Get("conditions").(*schema.Set).List()[0].(map[string]interface{})
+	var params map[string]interface{}
+	if paramsJSON, ok := d.GetOk("params_json"); ok {
+		if err := json.Unmarshal([]byte(paramsJSON.(string)), &params); err != nil {
+			return kibana.Alert{}, fmt.Errorf("fail to unmarshal: %v", err)
+		}
+	} else {
+		conditions := d.Get("conditions").(*schema.Set).List()[0].(map[string]interface{})
+		params = expandKibanaAlertConditions(conditions)
+	}
 
 	alert := kibana.Alert{
 		Name:        d.Get("name").(string),
@@ -311,24 +779,28 @@ func resourceElasticsearchPostKibanaAlert(d *schema.ResourceData, meta interface
 		Throttle:    d.Get("throttle").(string),
 		Enabled:     d.Get("enabled").(bool),
 		Consumer:    d.Get("consumer").(string),
-		Params:      expandKibanaAlertConditions(conditions),
+		Params:      params,
 		Actions:     actions,
 	}
 
-	version, _ := resourceElasticsearchKibanaGetVersion(meta)
+	version, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return alert, err
+	}
 	if version.GreaterThanOrEqual(notifyWhenKibanaVersion) {
 		alert.NotifyWhen = d.Get("notify_when").(string)
+	} else if _, ok := d.GetOk("notify_when"); ok {
+		return alert, fmt.Errorf("Kibana Alert notify_when only available from Kibana >= 7.11, got version %s", version.String())
 	}
-
-	var id string
-	switch client := kibanaClient.(type) {
-	case *elastic7.Client:
-		id, err = kibanaPostAlert(client, spaceID, alert)
-	default:
-		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	if version.LessThan(actionFrequencyKibanaVersion) {
+		for _, action := range alert.Actions {
+			if action.Frequency != nil {
+				return alert, fmt.Errorf("Kibana Alert action frequency only available from Kibana >= 8.6, got version %s", version.String())
+			}
+		}
 	}
 
-	return id, err
+	return alert, nil
 }
 
 func expandKibanaActionsList(resourcesArray []interface{}) ([]kibana.AlertAction, error) {
@@ -344,12 +816,98 @@ func expandKibanaActionsList(resourcesArray []interface{}) ([]kibana.AlertAction
 			ActionTypeId: data["action_type_id"].(string),
 			Params:       data["params"].(map[string]interface{}),
 		}
+		if paramsJSON := data["params_json"].(string); paramsJSON != "" {
+			var params map[string]interface{}
+			if err := json.Unmarshal([]byte(paramsJSON), &params); err != nil {
+				return actions, fmt.Errorf("fail to unmarshal params_json of action %s: %v", action.ID, err)
+			}
+			action.Params = params
+		}
+		if frequency, ok := data["frequency"].([]interface{}); ok && len(frequency) > 0 && frequency[0] != nil {
+			f := frequency[0].(map[string]interface{})
+			action.Frequency = &kibana.AlertActionFrequency{
+				Summary:    f["summary"].(bool),
+				NotifyWhen: f["notify_when"].(string),
+				Throttle:   f["throttle"].(string),
+			}
+		}
 		actions = append(actions, action)
 	}
 
 	return actions, nil
 }
 
+// kibanaAlertActionsWithJSONParams returns the actions of the state which are
+// configured with params_json, by ID and group
+func kibanaAlertActionsWithJSONParams(resourcesArray []interface{}) map[string]bool {
+	result := make(map[string]bool)
+	for _, resource := range resourcesArray {
+		if data, ok := resource.(map[string]interface{}); ok && data["params_json"].(string) != "" {
+			result[data["id"].(string)+"/"+data["group"].(string)] = true
+		}
+	}
+	return result
+}
+
+func flattenKibanaAlertActions(actions []kibana.AlertAction, jsonParams map[string]bool) ([]map[string]interface{}, error) {
+	result := make([]map[string]interface{}, 0, len(actions))
+	for _, action := range actions {
+		a := map[string]interface{}{
+			"id":             action.ID,
+			"group":          action.Group,
+			"action_type_id": action.ActionTypeId,
+		}
+		// nested params can't be represented in the flat params map
+		if jsonParams[action.ID+"/"+action.Group] || !kibanaAlertParamsAreFlat(action.Params) {
+			params := action.Params
+			if params == nil {
+				params = make(map[string]interface{})
+			}
+			paramsJSON, err := json.Marshal(params)
+			if err != nil {
+				return nil, err
+			}
+			a["params_json"] = string(paramsJSON)
+		} else {
+			a["params"] = action.Params
+		}
+		if action.Frequency != nil {
+			a["frequency"] = []map[string]interface{}{
+				{
+					"summary":     action.Frequency.Summary,
+					"notify_when": action.Frequency.NotifyWhen,
+					"throttle":    normalizeKibanaDuration(action.Frequency.Throttle),
+				},
+			}
+		}
+		result = append(result, a)
+	}
+
+	return result, nil
+}
+
+func kibanaAlertParamsAreFlat(params map[string]interface{}) bool {
+	for _, v := range params {
+		if _, ok := v.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// kibanaAlertUsesParamsJSON returns whether the params of the alert are read
+// to params_json rather than conditions, on import the conditions are only
+// read for the index threshold alerts
+func kibanaAlertUsesParamsJSON(d *schema.ResourceData, alert kibana.Alert) bool {
+	if _, ok := d.GetOk("params_json"); ok {
+		return true
+	}
+	if d.Get("conditions").(*schema.Set).Len() > 0 {
+		return false
+	}
+	return alert.AlertTypeID != ".index-threshold"
+}
+
 func expandKibanaAlertConditions(raw map[string]interface{}) map[string]interface{} {
 	conditions := make(map[string]interface{})
 
@@ -375,6 +933,73 @@ func expandKibanaAlertConditions(raw map[string]interface{}) map[string]interfac
 	return conditions
 }
 
+// normalizeKibanaDuration returns the duration in its largest exact unit,
+// e.g. `60s` is returned as `1m`, so equivalent durations returned by Kibana
+// are stored the same way. Other values are returned as is.
+func normalizeKibanaDuration(value string) string {
+	duration, ok := parseKibanaDuration(value)
+	if !ok || duration == 0 {
+		return value
+	}
+	for _, unit := range kibanaDurationUnits {
+		if duration%unit.duration == 0 {
+			return fmt.Sprintf("%d%s", duration/unit.duration, unit.name)
+		}
+	}
+	return value
+}
+
+// parseKibanaDuration parses the durations of Kibana, which unlike go
+// durations can be in days
+func parseKibanaDuration(value string) (time.Duration, bool) {
+	matches := kibanaDurationRegexp.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, false
+	}
+	for _, unit := range kibanaDurationUnits {
+		if unit.name == matches[2] {
+			return time.Duration(n) * unit.duration, true
+		}
+	}
+	return 0, false
+}
+
+// kibanaAlertTagHash hashes the tags case-insensitively, so tags returned with
+// a different casing don't produce a diff
+func kibanaAlertTagHash(v interface{}) int {
+	return schema.HashString(strings.ToLower(v.(string)))
+}
+
+// normalizeKibanaAlertTags sorts the tags returned by Kibana and keeps the
+// casing of the configured tags
+func normalizeKibanaAlertTags(tags []string, configured *schema.Set) []string {
+	casing := make(map[string]string)
+	for _, tag := range configured.List() {
+		casing[strings.ToLower(tag.(string))] = tag.(string)
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		key := strings.ToLower(tag)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if configuredTag, ok := casing[key]; ok {
+			tag = configuredTag
+		}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
+
 func flattenKibanaAlertConditions(raw map[string]interface{}) []map[string]interface{} {
 	conditions := make(map[string]interface{})
 
@@ -401,7 +1026,179 @@ func flattenKibanaAlertConditions(raw map[string]interface{}) []map[string]inter
 }
 
 func resourceElasticsearchPutKibanaAlert(d *schema.ResourceData, meta interface{}) error {
-	return nil
+	id := d.Id()
+	spaceID := d.Get("space_id").(string)
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	alert, err := kibanaAlertFromResourceData(d, meta)
+	if err != nil {
+		return err
+	}
+	alert.Version = d.Get("version").(string)
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		err = kibanaPutAlert(client, id, spaceID, alert, elasticVersion, d.Get("overwrite_on_conflict").(bool))
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("snooze_schedule") {
+		err = resourceElasticsearchKibanaAlertUpdateSnoozeSchedules(d, meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchKibanaAlertRead(d, meta)
+}
+
+// resourceElasticsearchKibanaAlertUpdateSnoozeSchedules replaces the snooze
+// schedules of the alert with the configured ones
+func resourceElasticsearchKibanaAlertUpdateSnoozeSchedules(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+	spaceID := d.Get("space_id").(string)
+
+	schedules, err := expandKibanaAlertSnoozeSchedules(d.Get("snooze_schedule").([]interface{}))
+	if err != nil {
+		return err
+	}
+
+	err = checkKibanaAlertSnoozeScheduleVersion(len(schedules), meta)
+	if err != nil {
+		return err
+	}
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(snoozeScheduleKibanaVersion) {
+		return nil
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		var alert kibana.Alert
+		alert, err = kibanaGetAlert(client, id, spaceID, elasticVersion)
+		if err != nil {
+			return err
+		}
+
+		var scheduleIDs []string
+		for _, schedule := range alert.SnoozeSchedule {
+			scheduleIDs = append(scheduleIDs, schedule.ID)
+		}
+		if len(scheduleIDs) > 0 {
+			err = kibanaUnsnoozeAlert(client, id, spaceID, scheduleIDs)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, schedule := range schedules {
+			err = kibanaSnoozeAlert(client, id, spaceID, schedule)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
+	}
+
+	return err
+}
+
+func expandKibanaAlertSnoozeSchedules(resourcesArray []interface{}) ([]kibana.AlertSnoozeSchedule, error) {
+	schedules := make([]kibana.AlertSnoozeSchedule, 0, len(resourcesArray))
+	for _, resource := range resourcesArray {
+		data, ok := resource.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Error asserting data: %+v, %T", resource, resource)
+		}
+
+		duration, err := time.ParseDuration(data["duration"].(string))
+		if err != nil {
+			return nil, err
+		}
+
+		rruleData := data["rrule"].([]interface{})[0].(map[string]interface{})
+		rrule := kibana.AlertSnoozeRRule{
+			Dtstart:   rruleData["dtstart"].(string),
+			Tzid:      rruleData["tzid"].(string),
+			Interval:  rruleData["interval"].(int),
+			Count:     rruleData["count"].(int),
+			Until:     rruleData["until"].(string),
+			Byweekday: expandStringList(rruleData["byweekday"].([]interface{})),
+		}
+		for i, freq := range snoozeScheduleFrequencies {
+			if freq == rruleData["freq"].(string) {
+				f := i
+				rrule.Freq = &f
+			}
+		}
+
+		schedules = append(schedules, kibana.AlertSnoozeSchedule{
+			Duration: duration.Milliseconds(),
+			RRule:    rrule,
+		})
+	}
+
+	return schedules, nil
+}
+
+func flattenKibanaAlertSnoozeSchedules(schedules []kibana.AlertSnoozeSchedule) []map[string]interface{} {
+	result := make([]map[string]interface{}, 0, len(schedules))
+	for _, schedule := range schedules {
+		freq := ""
+		if f := schedule.RRule.Freq; f != nil && *f >= 0 && *f < len(snoozeScheduleFrequencies) {
+			freq = snoozeScheduleFrequencies[*f]
+		}
+
+		result = append(result, map[string]interface{}{
+			"id":       schedule.ID,
+			"duration": (time.Duration(schedule.Duration) * time.Millisecond).String(),
+			"rrule": []map[string]interface{}{
+				{
+					"dtstart":   schedule.RRule.Dtstart,
+					"tzid":      schedule.RRule.Tzid,
+					"freq":      freq,
+					"interval":  schedule.RRule.Interval,
+					"count":     schedule.RRule.Count,
+					"until":     schedule.RRule.Until,
+					"byweekday": schedule.RRule.Byweekday,
+				},
+			},
+		})
+	}
+	return result
+}
+
+func validateKibanaAlertSnoozeDuration(v interface{}, k string) (ws []string, errors []error) {
+	duration, err := time.ParseDuration(v.(string))
+	if err != nil {
+		errors = append(errors, fmt.Errorf("%q must be a duration, e.g. 2h: %v", k, err))
+	} else if duration < time.Minute {
+		errors = append(errors, fmt.Errorf("%q must be at least 1m, got %s", k, v))
+	}
+	return
 }
 
 func resourceElasticsearchKibanaGetVersion(meta interface{}) (*version.Version, error) {
@@ -410,9 +1207,9 @@ func resourceElasticsearchKibanaGetVersion(meta interface{}) (*version.Version,
 		return nil, err
 	}
 
-	switch client := esClient.(type) {
+	switch esClient.(type) {
 	case *elastic7.Client:
-		return elastic7GetVersion(client)
+		return esVersionFromConf(meta.(*ProviderConf))
 	default:
 		return nil, fmt.Errorf("Kibana Alert endpoint only available from ElasticSearch >= 7.7, got version < 7.0.0")
 	}
@@ -431,10 +1228,155 @@ func resourceElasticsearchKibanaAlertCheckVersion(meta interface{}) error {
 	return err
 }
 
-func kibanaGetAlert(client *elastic7.Client, id, spaceID string) (kibana.Alert, error) {
-	path, err := uritemplates.Expand("/api/alerts/alert/{id}", map[string]string{
-		"id": id,
+// kibanaGetConnectorTypes returns the sorted IDs of the enabled connector
+// types
+func kibanaGetConnectorTypes(client *elastic7.Client, elasticVersion *version.Version) ([]string, error) {
+	connectorTypes, err := kibanaListConnectorTypes(client, "", elasticVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(connectorTypes))
+	for _, connectorType := range connectorTypes {
+		if connectorType.Enabled {
+			ids = append(ids, connectorType.ID)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// kibanaListConnectorTypes returns the connector types of a space, the action
+// types of the Kibana versions before 7.13 are returned with camel case keys
+func kibanaListConnectorTypes(client *elastic7.Client, spaceID string, elasticVersion *version.Version) ([]kibana.ConnectorType, error) {
+	template := "/api/actions/connector_types"
+	legacy := elasticVersion.LessThan(connectorTypesKibanaVersion)
+	if legacy {
+		template = "/api/actions/list_action_types"
+	}
+	path, err := kibanaSpacePath(spaceID, template, map[string]string{})
+	if err != nil {
+		return nil, fmt.Errorf("error building URL path for connector types: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	var connectorTypes []kibana.ConnectorType
+	if !legacy {
+		if err := json.Unmarshal(res.Body, &connectorTypes); err != nil {
+			return nil, fmt.Errorf("error unmarshalling connector types body: %+v: %+v", err, res.Body)
+		}
+		return connectorTypes, nil
+	}
+
+	var actionTypes []struct {
+		ID                     string `json:"id"`
+		Name                   string `json:"name"`
+		Enabled                bool   `json:"enabled"`
+		EnabledInConfig        bool   `json:"enabledInConfig"`
+		EnabledInLicense       bool   `json:"enabledInLicense"`
+		MinimumLicenseRequired string `json:"minimumLicenseRequired"`
+	}
+	if err := json.Unmarshal(res.Body, &actionTypes); err != nil {
+		return nil, fmt.Errorf("error unmarshalling connector types body: %+v: %+v", err, res.Body)
+	}
+	for _, actionType := range actionTypes {
+		connectorTypes = append(connectorTypes, kibana.ConnectorType(actionType))
+	}
+	return connectorTypes, nil
+}
+
+// kibanaSpacePath expands the path of an API, prefixed by the space when it
+// isn't the default space
+func kibanaSpacePath(spaceID, template string, values map[string]string) (string, error) {
+	if spaceID != "" {
+		template = "/s/{space_id}" + template
+		values["space_id"] = spaceID
+	}
+	return uritemplates.Expand(template, values)
+}
+
+// kibanaSpaceDescription describes the space in the error messages
+func kibanaSpaceDescription(spaceID string) string {
+	if spaceID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in space %q", spaceID)
+}
+
+// resourceElasticsearchKibanaAlertImport accepts the `space_id/alert_id` ID
+// for the alerts which aren't in the default space
+func resourceElasticsearchKibanaAlertImport(ctx context.Context, d *schema.ResourceData, meta interface{}) ([]*schema.ResourceData, error) {
+	if parts := strings.SplitN(d.Id(), "/", 2); len(parts) == 2 {
+		if parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("unexpected format of ID (%s), expected space_id/alert_id", d.Id())
+		}
+		d.SetId(parts[1])
+		if err := d.Set("space_id", parts[0]); err != nil {
+			return nil, err
+		}
+	}
+	return []*schema.ResourceData{d}, nil
+}
+
+// kibanaAlertPath returns the path of the alerting rule API from Kibana 7.13,
+// the legacy alerts API before
+func kibanaAlertPath(spaceID, alertTemplate, ruleTemplate string, values map[string]string, elasticVersion *version.Version) (string, error) {
+	if elasticVersion.LessThan(ruleAPIKibanaVersion) {
+		return kibanaSpacePath(spaceID, alertTemplate, values)
+	}
+	return kibanaSpacePath(spaceID, ruleTemplate, values)
+}
+
+// kibanaUnmarshalAlert reads an alert, or a rule from Kibana 7.13
+func kibanaUnmarshalAlert(body json.RawMessage, elasticVersion *version.Version) (kibana.Alert, error) {
+	if elasticVersion.LessThan(ruleAPIKibanaVersion) {
+		var alert kibana.Alert
+		err := json.Unmarshal(body, &alert)
+		return alert, err
+	}
+	var rule kibana.Rule
+	err := json.Unmarshal(body, &rule)
+	return rule.Alert(), err
+}
+
+// kibanaMarshalAlert writes the body creating an alert, or a rule from Kibana
+// 7.13 with its snake cased fields, e.g. rule_type_id instead of alertTypeId
+func kibanaMarshalAlert(alert kibana.Alert, elasticVersion *version.Version) ([]byte, error) {
+	if elasticVersion.LessThan(ruleAPIKibanaVersion) {
+		return json.Marshal(alert)
+	}
+	return json.Marshal(kibana.NewRule(alert))
+}
+
+// kibanaMarshalAlertUpdate writes the body updating an alert, or a rule from
+// Kibana 7.13, without the fields which can't be updated
+func kibanaMarshalAlertUpdate(alert kibana.Alert, elasticVersion *version.Version) ([]byte, error) {
+	if elasticVersion.LessThan(ruleAPIKibanaVersion) {
+		return json.Marshal(kibana.AlertUpdate{
+			Name:       alert.Name,
+			Tags:       alert.Tags,
+			Schedule:   alert.Schedule,
+			Throttle:   alert.Throttle,
+			NotifyWhen: alert.NotifyWhen,
+			Params:     alert.Params,
+			Actions:    alert.Actions,
+		})
+	}
+	return json.Marshal(kibana.NewRuleUpdate(alert))
+}
+
+func kibanaGetAlert(client *elastic7.Client, id, spaceID string, elasticVersion *version.Version) (kibana.Alert, error) {
+	path, err := kibanaAlertPath(spaceID, "/api/alerts/alert/{id}", "/api/alerting/rule/{id}", map[string]string{
+		"id": id,
+	}, elasticVersion)
 	if err != nil {
 		return kibana.Alert{}, fmt.Errorf("error building URL path for alert: %+v", err)
 	}
@@ -451,21 +1393,85 @@ func kibanaGetAlert(client *elastic7.Client, id, spaceID string) (kibana.Alert,
 		return kibana.Alert{}, err
 	}
 
-	alert := new(kibana.Alert)
-	if err := json.Unmarshal(body, alert); err != nil {
-		return *alert, fmt.Errorf("error unmarshalling alert body: %+v: %+v", err, body)
+	alert, err := kibanaUnmarshalAlert(body, elasticVersion)
+	if err != nil {
+		return alert, fmt.Errorf("error unmarshalling alert body: %+v: %+v", err, body)
+	}
+
+	return alert, nil
+}
+
+func kibanaFindAlerts(client *elastic7.Client, spaceID, name string, elasticVersion *version.Version) ([]kibana.Alert, error) {
+	params := url.Values{}
+	params.Set("search_fields", "name")
+	params.Set("search", name)
+
+	return kibanaFindAlertsWithParams(client, spaceID, params, elasticVersion)
+}
+
+// kibanaFindAlertsByFilter returns the alerts matching a KQL filter on the
+// alert saved objects, e.g. `alert.attributes.tags:maintenance`
+func kibanaFindAlertsByFilter(client *elastic7.Client, spaceID, filter string, elasticVersion *version.Version) ([]kibana.Alert, error) {
+	params := url.Values{}
+	params.Set("filter", filter)
+
+	return kibanaFindAlertsWithParams(client, spaceID, params, elasticVersion)
+}
+
+func kibanaFindAlertsWithParams(client *elastic7.Client, spaceID string, params url.Values, elasticVersion *version.Version) ([]kibana.Alert, error) {
+	var alerts []kibana.Alert
+
+	path, err := kibanaAlertPath(spaceID, "/api/alerts/_find", "/api/alerting/rules/_find", map[string]string{}, elasticVersion)
+	if err != nil {
+		return alerts, fmt.Errorf("error building URL path for alerts: %+v", err)
 	}
+	params.Set("per_page", "100")
+
+	for page := 1; ; page++ {
+		params.Set("page", strconv.Itoa(page))
+
+		res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   path,
+			Params: params,
+		})
+		if err != nil {
+			return alerts, err
+		}
+
+		var data []kibana.Alert
+		var total int
+		if elasticVersion.LessThan(ruleAPIKibanaVersion) {
+			response := new(kibana.AlertsFindResponse)
+			if err := json.Unmarshal(res.Body, response); err != nil {
+				return alerts, fmt.Errorf("error unmarshalling alerts body: %+v: %+v", err, res.Body)
+			}
+			data, total = response.Data, response.Total
+		} else {
+			response := new(kibana.RulesFindResponse)
+			if err := json.Unmarshal(res.Body, response); err != nil {
+				return alerts, fmt.Errorf("error unmarshalling rules body: %+v: %+v", err, res.Body)
+			}
+			for _, rule := range response.Data {
+				data = append(data, rule.Alert())
+			}
+			total = response.Total
+		}
 
-	return *alert, nil
+		alerts = append(alerts, data...)
+		if len(data) == 0 || len(alerts) >= total {
+			return alerts, nil
+		}
+	}
 }
 
-func kibanaPostAlert(client *elastic7.Client, spaceID string, alert kibana.Alert) (string, error) {
-	path, err := uritemplates.Expand("/api/alerts/alert", map[string]string{})
+func kibanaPostAlert(client *elastic7.Client, spaceID string, alert kibana.Alert, elasticVersion *version.Version) (string, error) {
+	path, err := kibanaAlertPath(spaceID, "/api/alerts/alert", "/api/alerting/rule", map[string]string{}, elasticVersion)
 	if err != nil {
 		return "", fmt.Errorf("error building URL path for alert: %+v", err)
 	}
 
-	body, err := json.Marshal(alert)
+	body, err := kibanaMarshalAlert(alert, elasticVersion)
 	if err != nil {
 		log.Printf("[INFO] kibanaPostAlert: %+v %+v %+v", path, alert, err)
 		return "", fmt.Errorf("Body Error: %s", err)
@@ -483,21 +1489,180 @@ func kibanaPostAlert(client *elastic7.Client, spaceID string, alert kibana.Alert
 		return "", err
 	}
 
-	if err := json.Unmarshal(res.Body, &alert); err != nil {
+	alert, err = kibanaUnmarshalAlert(res.Body, elasticVersion)
+	if err != nil {
 		return "", fmt.Errorf("error unmarshalling alert body: %+v: %+v", err, body)
 	}
 
 	return alert.ID, nil
 }
 
-func kibanaDeleteAlert(client *elastic7.Client, id, spaceID string) error {
-	path, err := uritemplates.Expand("/api/alerts/alert/{id}", map[string]string{
+func kibanaPutAlert(client *elastic7.Client, id, spaceID string, alert kibana.Alert, elasticVersion *version.Version, overwriteOnConflict bool) error {
+	path, err := kibanaAlertPath(spaceID, "/api/alerts/alert/{id}", "/api/alerting/rule/{id}", map[string]string{
+		"id": id,
+	}, elasticVersion)
+	if err != nil {
+		return fmt.Errorf("error building URL path for alert: %+v", err)
+	}
+
+	body, err := kibanaMarshalAlertUpdate(alert, elasticVersion)
+	if err != nil {
+		log.Printf("[INFO] kibanaPutAlert: %+v %+v %+v", path, alert, err)
+		return fmt.Errorf("Body Error: %s", err)
+	}
+
+	// the update routes don't check the version of the alert, it is compared
+	// to the current one so that a change made since the alert was read, e.g.
+	// by another run or in the Kibana UI, is only overwritten on demand
+	if alert.Version != "" {
+		current, err := kibanaGetAlert(client, id, spaceID, elasticVersion)
+		if err != nil {
+			return err
+		}
+		if current.Version != alert.Version {
+			if !overwriteOnConflict {
+				return fmt.Errorf("alert %s%s was changed since it was read (version %s, current version %s), refresh it and plan again, or set overwrite_on_conflict to overwrite the change", id, kibanaSpaceDescription(spaceID), alert.Version, current.Version)
+			}
+
+			changedFields, err := kibanaAlertChangedFields(current, body, elasticVersion)
+			if err != nil {
+				return err
+			}
+			log.Printf("[WARN] Alert (%s) version %s conflicts, overwriting the fields %v of the current version %s", id, alert.Version, changedFields, current.Version)
+		}
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   string(body[:]),
+	})
+	if err != nil {
+		log.Printf("[INFO] kibanaPutAlert: %+v %+v %+v", path, alert, string(body[:]))
+		return err
+	}
+
+	return nil
+}
+
+// kibanaAlertChangedFields returns the fields of the update body which differ
+// from the current alert, they are overwritten by the update
+func kibanaAlertChangedFields(current kibana.Alert, body []byte, elasticVersion *version.Version) ([]string, error) {
+	currentBody, err := kibanaMarshalAlertUpdate(current, elasticVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var currentFields, fields map[string]interface{}
+	if err := json.Unmarshal(currentBody, &currentFields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	for field, value := range fields {
+		if !reflect.DeepEqual(value, currentFields[field]) {
+			changed = append(changed, field)
+		}
+	}
+	for field := range currentFields {
+		if _, ok := fields[field]; !ok {
+			changed = append(changed, field)
+		}
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+func kibanaSetAlertEnabled(client *elastic7.Client, id, spaceID string, enabled bool, elasticVersion *version.Version) error {
+	action := "_disable"
+	if enabled {
+		action = "_enable"
+	}
+	path, err := kibanaAlertPath(spaceID, "/api/alerts/alert/{id}/{action}", "/api/alerting/rule/{id}/{action}", map[string]string{
+		"id":     id,
+		"action": action,
+	}, elasticVersion)
+	if err != nil {
+		return fmt.Errorf("error building URL path for alert: %+v", err)
+	}
+
+	log.Printf("[INFO] kibanaSetAlertEnabled: %s", path)
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+	})
+	if err != nil {
+		return fmt.Errorf("error changing the enabled state of alert %s: %+v", id, err)
+	}
+
+	return nil
+}
+
+// kibanaSnoozeAlert adds a snooze schedule with the internal API used by the
+// Kibana UI, the public snooze schedule API (POST
+// /api/alerting/rule/{id}/snooze_schedule) only exists from Kibana 8.17 while
+// the snooze schedules are available from Kibana 8.4
+func kibanaSnoozeAlert(client *elastic7.Client, id, spaceID string, schedule kibana.AlertSnoozeSchedule) error {
+	path, err := kibanaSpacePath(spaceID, "/internal/alerting/rule/{id}/_snooze", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for alert: %+v", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"snooze_schedule": schedule,
+	})
+	if err != nil {
+		return fmt.Errorf("Body Error: %s", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   string(body[:]),
+	})
+
+	return err
+}
+
+// kibanaUnsnoozeAlert removes snooze schedules with the internal API, see
+// kibanaSnoozeAlert
+func kibanaUnsnoozeAlert(client *elastic7.Client, id, spaceID string, scheduleIDs []string) error {
+	path, err := kibanaSpacePath(spaceID, "/internal/alerting/rule/{id}/_unsnooze", map[string]string{
 		"id": id,
 	})
 	if err != nil {
 		return fmt.Errorf("error building URL path for alert: %+v", err)
 	}
 
+	body, err := json.Marshal(map[string]interface{}{
+		"schedule_ids": scheduleIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("Body Error: %s", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   string(body[:]),
+	})
+
+	return err
+}
+
+func kibanaDeleteAlert(client *elastic7.Client, id, spaceID string, elasticVersion *version.Version) error {
+	path, err := kibanaAlertPath(spaceID, "/api/alerts/alert/{id}", "/api/alerting/rule/{id}", map[string]string{
+		"id": id,
+	}, elasticVersion)
+	if err != nil {
+		return fmt.Errorf("error building URL path for alert: %+v", err)
+	}
+
 	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
 		Method: "DELETE",
 		Path:   path,
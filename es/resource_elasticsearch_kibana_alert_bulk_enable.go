@@ -0,0 +1,189 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var bulkEnableKibanaVersion, _ = version.NewVersion("8.5.0")
+
+func resourceElasticsearchKibanaAlertBulkEnable() *schema.Resource {
+	return &schema.Resource{
+		Description: "Enables or disables at once the Kibana alerts matching a filter, or given by their IDs, e.g. for the duration of a maintenance window. Changing the `triggers` switches the alerts again, e.g. when they were switched back in the Kibana UI. The resource doesn't own the alerts: destroying it doesn't restore their previous state.",
+		Create:      resourceElasticsearchKibanaAlertBulkEnableCreate,
+		Read:        resourceElasticsearchKibanaAlertBulkEnableRead,
+		Delete:      resourceElasticsearchKibanaAlertBulkEnableDelete,
+		Schema: map[string]*schema.Schema{
+			"filter": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"filter", "ids"},
+				Description:  "A KQL filter on the alerts saved objects, e.g. `alert.attributes.tags:maintenance`.",
+			},
+			"ids": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{"filter", "ids"},
+				Elem:         &schema.Schema{Type: schema.TypeString},
+				Description:  "The IDs of the alerts.",
+			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Whether the matching alerts are enabled or disabled.",
+			},
+			"space_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The ID of the Kibana space of the alerts, the default space when not set.",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values which run the action again when changed, e.g. the start of the maintenance window.",
+			},
+			"affected_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of alerts which were enabled or disabled.",
+			},
+		},
+	}
+}
+
+func resourceElasticsearchKibanaAlertBulkEnableCreate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	filter := d.Get("filter").(string)
+	ids := expandStringList(d.Get("ids").(*schema.Set).List())
+	enabled := d.Get("enabled").(bool)
+	spaceID := d.Get("space_id").(string)
+
+	var count int
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		if elasticVersion.LessThan(bulkEnableKibanaVersion) {
+			count, err = kibanaEnableAlertsOneByOne(client, spaceID, filter, ids, enabled, elasticVersion)
+		} else {
+			count, err = kibanaBulkEnableAlerts(client, spaceID, filter, ids, enabled)
+		}
+	default:
+		err = fmt.Errorf("Kibana Alert endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	d.SetId(resource.UniqueId())
+	ds := &resourceDataSetter{d: d}
+	ds.set("affected_count", count)
+	return ds.err
+}
+
+// resourceElasticsearchKibanaAlertBulkEnableRead doesn't check the state of
+// the alerts, they can be switched again by other means until the triggers
+// change
+func resourceElasticsearchKibanaAlertBulkEnableRead(d *schema.ResourceData, meta interface{}) error {
+	return nil
+}
+
+func resourceElasticsearchKibanaAlertBulkEnableDelete(d *schema.ResourceData, meta interface{}) error {
+	d.SetId("")
+	return nil
+}
+
+func kibanaBulkEnableAlerts(client *elastic7.Client, spaceID, filter string, ids []string, enabled bool) (int, error) {
+	template := "/api/alerting/rules/_bulk_disable"
+	if enabled {
+		template = "/api/alerting/rules/_bulk_enable"
+	}
+	path, err := kibanaSpacePath(spaceID, template, map[string]string{})
+	if err != nil {
+		return 0, fmt.Errorf("error building URL path for alerts: %+v", err)
+	}
+
+	body := make(map[string]interface{})
+	if filter != "" {
+		body["filter"] = filter
+	} else {
+		body["ids"] = ids
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PATCH",
+		Path:   path,
+		Body:   body,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var response struct {
+		Total  int `json:"total"`
+		Errors []struct {
+			Message string `json:"message"`
+			Rule    struct {
+				ID string `json:"id"`
+			} `json:"rule"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return 0, fmt.Errorf("error unmarshalling bulk enable body: %+v: %+v", err, res.Body)
+	}
+	if len(response.Errors) > 0 {
+		return 0, fmt.Errorf("error changing the enabled state of alert %s: %s", response.Errors[0].Rule.ID, response.Errors[0].Message)
+	}
+
+	return response.Total, nil
+}
+
+// kibanaEnableAlertsOneByOne is the fallback of kibanaBulkEnableAlerts for the
+// Kibana versions without the bulk endpoints
+func kibanaEnableAlertsOneByOne(client *elastic7.Client, spaceID, filter string, ids []string, enabled bool, elasticVersion *version.Version) (int, error) {
+	if filter != "" {
+		alerts, err := kibanaFindAlertsByFilter(client, spaceID, filter, elasticVersion)
+		if err != nil {
+			return 0, err
+		}
+		ids = make([]string, 0, len(alerts))
+		for _, alert := range alerts {
+			// only the alerts in the other state are affected
+			if alert.Enabled != enabled {
+				ids = append(ids, alert.ID)
+			}
+		}
+	}
+
+	for _, id := range ids {
+		if err := kibanaSetAlertEnabled(client, id, spaceID, enabled, elasticVersion); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(ids), nil
+}
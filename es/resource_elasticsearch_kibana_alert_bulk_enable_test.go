@@ -0,0 +1,169 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchKibanaAlertBulkEnable(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertBulkEnable(false, "start"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert_bulk_enable.test", "affected_count", "1"),
+					testCheckElasticsearchKibanaAlertEnabled("elasticsearch_kibana_alert.test", false),
+				),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertBulkEnable(true, "end"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert_bulk_enable.test", "affected_count", "1"),
+					testCheckElasticsearchKibanaAlertEnabled("elasticsearch_kibana_alert.test", true),
+				),
+			},
+		},
+	})
+}
+
+func TestKibanaBulkEnableAlertsSpace(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != "PATCH" || r.URL.Path != "/s/ops/api/alerting/rules/_bulk_disable" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"total": 2, "errors": []}`)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esClient, err := getClient(&ProviderConf{rawUrl: ts.URL, parsedUrl: parsedUrl, esVersion: "8.5.0"})
+	if err != nil {
+		t.Fatalf("getClient returned an error: %+v", err)
+	}
+
+	count, err := kibanaBulkEnableAlerts(esClient.(*elastic7.Client), "ops", "alert.attributes.tags:maintenance", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 affected alerts, got %d", count)
+	}
+}
+
+func testCheckElasticsearchKibanaAlertEnabled(name string, enabled bool) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		meta := testAccKibanaProvider.Meta()
+
+		elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+		if err != nil {
+			return err
+		}
+		esClient, err := getKibanaClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			alert, err := kibanaGetAlert(client, rs.Primary.ID, "", elasticVersion)
+			if err != nil {
+				return err
+			}
+			if alert.Enabled != enabled {
+				return fmt.Errorf("expected alert %s enabled to be %t, got %t", rs.Primary.ID, enabled, alert.Enabled)
+			}
+		default:
+			return errors.New("Kibana Alerts only supported on ES >= 7.7")
+		}
+
+		return nil
+	}
+}
+
+func testAccElasticsearchKibanaAlertBulkEnable(enabled bool, window string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  tags = ["terraform-maintenance"]
+  schedule {
+    interval = "1m"
+  }
+  conditions {
+    aggregation_type     = "avg"
+    term_size            = 6
+    threshold_comparator = ">"
+    time_window_size     = 5
+    time_window_unit     = "m"
+    group_by             = "top"
+    threshold            = [1000]
+    index                = [".test-index"]
+    time_field           = "@timestamp"
+    aggregation_field    = "sheet.version"
+    term_field           = "name.keyword"
+  }
+
+  lifecycle {
+    ignore_changes = [enabled]
+  }
+}
+
+resource "elasticsearch_kibana_alert_bulk_enable" "test" {
+  filter  = "alert.attributes.tags:terraform-maintenance"
+  enabled = %t
+  triggers = {
+    window = "%s"
+  }
+
+  depends_on = [elasticsearch_kibana_alert.test]
+}
+`, enabled, window)
+}
@@ -5,8 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
 	"testing"
 
+	"github.com/hashicorp/go-version"
 	elastic7 "github.com/olivere/elastic/v7"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
@@ -63,11 +70,599 @@ func TestAccElasticsearchKibanaAlert(t *testing.T) {
 					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
 				),
 			},
+			{
+				Config: testAccElasticsearchKibanaAlertMultipleActionsV77(defaultActionID),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "actions.#", "2"),
+				),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertJSONParamsV77(defaultActionID),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "actions.#", "1"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_importBasic(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertNoActionsV77,
+			},
+			{
+				ResourceName:      "elasticsearch_kibana_alert.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate_action_types",
+				},
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_importActions(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	var defaultActionID string
+	if allowed {
+		defaultActionID, err = testKibanaAlertCreateAction()
+		if err != nil {
+			t.Errorf("error creating action fixture: %+v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertMultipleActionsV77(defaultActionID),
+			},
+			{
+				ResourceName:      "elasticsearch_kibana_alert.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate_action_types",
+				},
+				ImportStateCheck: func(states []*terraform.InstanceState) error {
+					if len(states) != 1 {
+						return fmt.Errorf("expected 1 imported alert, got %d", len(states))
+					}
+					if count := states[0].Attributes["actions.#"]; count != "2" {
+						return fmt.Errorf("expected 2 imported actions, got %s", count)
+					}
+					return nil
+				},
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_importDurations(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertDurations,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "schedule.0.interval", "1m"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "throttle", "1h"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_kibana_alert.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate_action_types",
+				},
+			},
+			{
+				// the equivalent durations of the configuration don't produce a diff
+				Config:   testAccElasticsearchKibanaAlertDurations,
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_scheduleDrift(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertDurations,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "schedule.0.interval", "1m"),
+					testCheckElasticsearchKibanaAlertSetInterval("elasticsearch_kibana_alert.test", "5m"),
+				),
+				// the interval changed outside of terraform is planned back
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertDurations,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "schedule.0.interval", "1m"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_snoozeSchedule(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if elasticVersion.LessThan(snoozeScheduleKibanaVersion) {
+				t.Skip("Kibana Alert snooze schedules only supported on Kibana >= 8.4")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertSnoozeSchedule,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "snooze_schedule.#", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "snooze_schedule.0.rrule.0.freq", "weekly"),
+					resource.TestCheckResourceAttrSet("elasticsearch_kibana_alert.test", "snooze_schedule.0.id"),
+				),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertNoActionsV77,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "snooze_schedule.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_disabled(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertEnabled(false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertEnabled("elasticsearch_kibana_alert.test", false),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "enabled", "false"),
+				),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertEnabled(true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertEnabled("elasticsearch_kibana_alert.test", true),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_tags(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertTags(`["team-b", "Team-A"]`),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "tags.#", "2"),
+				),
+			},
+			{
+				// reordered tags with a different casing don't produce a diff
+				Config:   testAccElasticsearchKibanaAlertTags(`["team-a", "TEAM-B"]`),
+				PlanOnly: true,
+			},
+			{
+				Config:      testAccElasticsearchKibanaAlertTags(`["team-a", " "]`),
+				ExpectError: regexp.MustCompile("expected \"tags.*\" to not be an empty string or whitespace"),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_validateActionTypes(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	var defaultActionID string
+	if allowed {
+		defaultActionID, err = testKibanaAlertCreateAction()
+		if err != nil {
+			t.Errorf("error creating action fixture: %+v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchKibanaAlertValidateActionTypes(defaultActionID, ".unknown"),
+				ExpectError: regexp.MustCompile("action_type_id \".unknown\" is not an available connector type, expected one of .*\\.index"),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertValidateActionTypes(defaultActionID, ".index"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+				),
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchKibanaAlertExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No kibana alert ID is set")
+		}
+
+		meta := testAccKibanaProvider.Meta()
+
+		elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+		if err != nil {
+			return err
+		}
+		esClient, err := getKibanaClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = kibanaGetAlert(client, rs.Primary.ID, rs.Primary.Attributes["space_id"], elasticVersion)
+		default:
+			err = errors.New("Kibana Alerts only supported on ES >= 7.7")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+// testCheckElasticsearchKibanaAlertSetInterval changes the schedule interval
+// of the alert outside of terraform
+func testCheckElasticsearchKibanaAlertSetInterval(name, interval string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+
+		meta := testAccKibanaProvider.Meta()
+
+		elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+		if err != nil {
+			return err
+		}
+		esClient, err := getKibanaClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		client, ok := esClient.(*elastic7.Client)
+		if !ok {
+			return errors.New("Kibana Alerts only supported on ES >= 7.7")
+		}
+		spaceID := rs.Primary.Attributes["space_id"]
+		alert, err := kibanaGetAlert(client, rs.Primary.ID, spaceID, elasticVersion)
+		if err != nil {
+			return err
+		}
+		alert.Schedule.Interval = interval
+		return kibanaPutAlert(client, rs.Primary.ID, spaceID, alert, elasticVersion, false)
+	}
+}
+
+func testCheckElasticsearchKibanaAlertDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_kibana_alert" {
+			continue
+		}
+
+		meta := testAccKibanaProvider.Meta()
+
+		elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+		if err != nil {
+			return err
+		}
+		esClient, err := getKibanaClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = kibanaGetAlert(client, rs.Primary.ID, rs.Primary.Attributes["space_id"], elasticVersion)
+		default:
+			err = errors.New("Kibana Alerts only supported on ES >= 7.7")
+		}
+
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("kibana alert %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func TestAccElasticsearchKibanaAlert_space(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	spaceID := "terraform-test"
+	if allowed {
+		if err := testKibanaAlertCreateSpace(spaceID); err != nil {
+			t.Errorf("error creating space fixture: %+v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertSpace(spaceID),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "space_id", spaceID),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_kibana_alert.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateIdFunc: func(s *terraform.State) (string, error) {
+					return spaceID + "/" + s.RootModule().Resources["elasticsearch_kibana_alert.test"].Primary.ID, nil
+				},
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate_action_types",
+				},
+			},
 		},
 	})
 }
 
-func TestAccElasticsearchKibanaAlert_importBasic(t *testing.T) {
+func TestAccElasticsearchKibanaAlert_notifyWhen(t *testing.T) {
 	provider := Provider()
 	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
 	if diags.HasError() {
@@ -75,49 +670,134 @@ func TestAccElasticsearchKibanaAlert_importBasic(t *testing.T) {
 	}
 	meta := provider.Meta()
 
-	esClient, err := getClient(meta.(*ProviderConf))
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
 	if err != nil {
 		t.Skipf("err: %s", err)
 	}
 
-	var allowed bool
-	switch esClient.(type) {
-	case *elastic7.Client:
-		allowed = true
-	default:
-		allowed = false
+	steps := []resource.TestStep{
+		{
+			Config:      testAccElasticsearchKibanaAlertNotifyWhen("onThrottleInterval", ""),
+			ExpectError: regexp.MustCompile(`throttle is required when notify_when is "onThrottleInterval"`),
+		},
+		{
+			Config:      testAccElasticsearchKibanaAlertNotifyWhen("onActiveAlert", "1h"),
+			ExpectError: regexp.MustCompile(`throttle "1h" can't be set when notify_when is "onActiveAlert"`),
+		},
+		{
+			Config: testAccElasticsearchKibanaAlertNotifyWhen("onThrottleInterval", "1h"),
+			Check: resource.ComposeTestCheckFunc(
+				testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+				resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "throttle", "1h"),
+			),
+		},
+		{
+			Config: testAccElasticsearchKibanaAlertV711,
+			Check: resource.ComposeTestCheckFunc(
+				testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+				resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "notify_when", "onActionGroupChange"),
+			),
+		},
+	}
+	if elasticVersion.LessThan(notifyWhenKibanaVersion) {
+		steps = []resource.TestStep{
+			{
+				Config:      testAccElasticsearchKibanaAlertV711,
+				ExpectError: regexp.MustCompile("notify_when only available from Kibana >= 7.11"),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertNoActionsV77,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+				),
+			},
+			{
+				// notify_when isn't read back, it doesn't produce a diff
+				Config:   testAccElasticsearchKibanaAlertNoActionsV77,
+				PlanOnly: true,
+			},
+		}
 	}
+
 	resource.Test(t, resource.TestCase{
 		PreCheck: func() {
 			testAccPreCheck(t)
-			if !allowed {
+			if elasticVersion.LessThan(minimalKibanaVersion) {
 				t.Skip("Kibana Alerts only supported on ES >= 7.7")
 			}
 		},
 		Providers:    testAccKibanaProviders,
 		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps:        steps,
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_ruleAPI(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var defaultActionID string
+	if !elasticVersion.LessThan(ruleAPIKibanaVersion) {
+		defaultActionID, err = testKibanaAlertCreateAction()
+		if err != nil {
+			t.Errorf("error creating action fixture: %+v", err)
+		}
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if elasticVersion.LessThan(ruleAPIKibanaVersion) {
+				t.Skip("Kibana alerting rule API only supported on Kibana >= 7.13")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
 		Steps: []resource.TestStep{
 			{
-				Config: testAccElasticsearchKibanaAlertNoActionsV77,
+				Config: testAccElasticsearchKibanaAlertV77(defaultActionID),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertRule("elasticsearch_kibana_alert.test", 1),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "alert_type_id", ".index-threshold"),
+				),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertMultipleActionsV77(defaultActionID),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertRule("elasticsearch_kibana_alert.test", 2),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "actions.#", "2"),
+				),
 			},
 			{
 				ResourceName:      "elasticsearch_kibana_alert.test",
 				ImportState:       true,
 				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate_action_types",
+				},
 			},
 		},
 	})
 }
 
-func testCheckElasticsearchKibanaAlertExists(name string) resource.TestCheckFunc {
+// testCheckElasticsearchKibanaAlertRule checks the alert is returned by the
+// alerting rule API with its actions
+func testCheckElasticsearchKibanaAlertRule(name string, actions int) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
 		if !ok {
 			return fmt.Errorf("Not found: %s", name)
 		}
-		if rs.Primary.ID == "" {
-			return fmt.Errorf("No kibana alert ID is set")
-		}
 
 		meta := testAccKibanaProvider.Meta()
 
@@ -128,47 +808,423 @@ func testCheckElasticsearchKibanaAlertExists(name string) resource.TestCheckFunc
 
 		switch client := esClient.(type) {
 		case *elastic7.Client:
-			_, err = kibanaGetAlert(client, rs.Primary.ID, "")
-		default:
-			err = errors.New("Kibana Alerts only supported on ES >= 7.7")
-		}
+			res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+				Method: "GET",
+				Path:   fmt.Sprintf("/api/alerting/rule/%s", rs.Primary.ID),
+			})
+			if err != nil {
+				return err
+			}
 
-		if err != nil {
-			return err
+			var rule kibana.Rule
+			if err := json.Unmarshal(res.Body, &rule); err != nil {
+				return err
+			}
+			if rule.RuleTypeID != rs.Primary.Attributes["alert_type_id"] {
+				return fmt.Errorf("expected rule %s type to be %s, got %s", rs.Primary.ID, rs.Primary.Attributes["alert_type_id"], rule.RuleTypeID)
+			}
+			if len(rule.Actions) != actions {
+				return fmt.Errorf("expected rule %s to have %d actions, got %d", rs.Primary.ID, actions, len(rule.Actions))
+			}
+		default:
+			return errors.New("Kibana Alerts only supported on ES >= 7.7")
 		}
 
 		return nil
 	}
 }
 
-func testCheckElasticsearchKibanaAlertDestroy(s *terraform.State) error {
-	for _, rs := range s.RootModule().Resources {
-		if rs.Type != "elasticsearch_kibana_alert" {
-			continue
+func TestAccElasticsearchKibanaAlert_validateConditions(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Alerts only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchKibanaAlertConditions("greater", "m", "[1000]"),
+				ExpectError: regexp.MustCompile(`expected .*threshold_comparator to be one of`),
+			},
+			{
+				Config:      testAccElasticsearchKibanaAlertConditions(">", "minutes", "[1000]"),
+				ExpectError: regexp.MustCompile(`expected .*time_window_unit to be one of`),
+			},
+			{
+				Config:      testAccElasticsearchKibanaAlertConditions("between", "m", "[1000]"),
+				ExpectError: regexp.MustCompile(`threshold_comparator "between" takes 2 threshold values, got 1`),
+			},
+			{
+				Config:      testAccElasticsearchKibanaAlertConditions(">", "m", "[10, 1000]"),
+				ExpectError: regexp.MustCompile(`threshold_comparator ">" takes 1 threshold values, got 2`),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertConditions("notBetween", "h", "[10, 1000]"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchKibanaAlert_paramsJSON(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	// the elasticsearch query alert type
+	esQueryKibanaVersion, _ := version.NewVersion("7.11.0")
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if elasticVersion.LessThan(esQueryKibanaVersion) {
+				t.Skip("Kibana Elasticsearch query alerts only supported on Kibana >= 7.11")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaAlertDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaAlertParamsJSON(100),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "alert_type_id", ".es-query"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_alert.test", "conditions.#", "0"),
+				),
+			},
+			{
+				Config: testAccElasticsearchKibanaAlertParamsJSON(50),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestMatchResourceAttr("elasticsearch_kibana_alert.test", "params_json", regexp.MustCompile(`"size":50`)),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_kibana_alert.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate_action_types",
+				},
+			},
+		},
+	})
+}
+
+func TestKibanaSpacePath(t *testing.T) {
+	path, err := kibanaSpacePath("", "/api/alerts/alert/{id}", map[string]string{"id": "abc"})
+	if err != nil || path != "/api/alerts/alert/abc" {
+		t.Errorf("expected the default space path /api/alerts/alert/abc, got %q (%v)", path, err)
+	}
+	path, err = kibanaSpacePath("team-a", "/api/alerts/alert/{id}", map[string]string{"id": "abc"})
+	if err != nil || path != "/s/team-a/api/alerts/alert/abc" {
+		t.Errorf("expected the space path /s/team-a/api/alerts/alert/abc, got %q (%v)", path, err)
+	}
+}
+
+func TestKibanaAlertPath(t *testing.T) {
+	legacyVersion, _ := version.NewVersion("7.12.1")
+	path, err := kibanaAlertPath("", "/api/alerts/alert/{id}", "/api/alerting/rule/{id}", map[string]string{"id": "abc"}, legacyVersion)
+	if err != nil || path != "/api/alerts/alert/abc" {
+		t.Errorf("expected the alerts API path /api/alerts/alert/abc, got %q (%v)", path, err)
+	}
+	path, err = kibanaAlertPath("team-a", "/api/alerts/alert/{id}", "/api/alerting/rule/{id}", map[string]string{"id": "abc"}, ruleAPIKibanaVersion)
+	if err != nil || path != "/s/team-a/api/alerting/rule/abc" {
+		t.Errorf("expected the rule API path /s/team-a/api/alerting/rule/abc, got %q (%v)", path, err)
+	}
+}
+
+func TestKibanaUnmarshalAlert(t *testing.T) {
+	legacyVersion, _ := version.NewVersion("7.12.1")
+	alert, err := kibanaUnmarshalAlert(json.RawMessage(`{
+		"id": "abc",
+		"alertTypeId": ".index-threshold",
+		"notifyWhen": "onActiveAlert",
+		"scheduledTaskId": "ghi",
+		"actions": [{"id": "def", "group": "threshold met", "actionTypeId": ".index"}],
+		"executionStatus": {"status": "ok", "lastExecutionDate": "2021-05-25T00:00:00.000Z"}
+	}`), legacyVersion)
+	if err != nil {
+		t.Fatalf("error unmarshalling alert: %+v", err)
+	}
+	checkKibanaUnmarshalledAlert(t, alert)
+
+	alert, err = kibanaUnmarshalAlert(json.RawMessage(`{
+		"id": "abc",
+		"rule_type_id": ".index-threshold",
+		"notify_when": "onActiveAlert",
+		"scheduled_task_id": "ghi",
+		"actions": [{"id": "def", "group": "threshold met", "connector_type_id": ".index"}],
+		"execution_status": {"status": "ok", "last_execution_date": "2021-05-25T00:00:00.000Z"}
+	}`), ruleAPIKibanaVersion)
+	if err != nil {
+		t.Fatalf("error unmarshalling rule: %+v", err)
+	}
+	checkKibanaUnmarshalledAlert(t, alert)
+
+	// the connector type is returned by Kibana but rejected in the requests
+	body, err := json.Marshal(kibana.NewRule(alert))
+	if err != nil {
+		t.Fatalf("error marshalling rule: %+v", err)
+	}
+	var rule map[string]interface{}
+	if err := json.Unmarshal(body, &rule); err != nil {
+		t.Fatalf("error unmarshalling rule: %+v", err)
+	}
+	if rule["rule_type_id"] != ".index-threshold" {
+		t.Errorf("expected rule_type_id .index-threshold in %s", body)
+	}
+	if _, ok := rule["actions"].([]interface{})[0].(map[string]interface{})["connector_type_id"]; ok {
+		t.Errorf("expected no connector_type_id in the actions of %s", body)
+	}
+	if _, ok := rule["scheduled_task_id"]; ok {
+		t.Errorf("expected no scheduled_task_id in %s", body)
+	}
+}
+
+func TestKibanaPutAlertConflict(t *testing.T) {
+	var puts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			fmt.Fprint(w, `{"id": "abc", "name": "terraform-alert", "rule_type_id": ".index-threshold", "version": "WzIsMV0="}`)
+		case "PUT":
+			puts++
+			fmt.Fprint(w, `{"id": "abc"}`)
 		}
+	}))
+	defer ts.Close()
 
-		meta := testAccKibanaProvider.Meta()
+	client, err := elastic7.NewClient(elastic7.SetURL(ts.URL), elastic7.SetSniff(false), elastic7.SetHealthcheck(false))
+	if err != nil {
+		t.Fatalf("error creating client: %+v", err)
+	}
 
-		esClient, err := getKibanaClient(meta.(*ProviderConf))
-		if err != nil {
-			return err
+	alert := kibana.Alert{Name: "terraform-alert", Schedule: kibana.AlertSchedule{Interval: "1m"}, Version: "WzEsMV0="}
+	err = kibanaPutAlert(client, "abc", "", alert, ruleAPIKibanaVersion, false)
+	if err == nil || !strings.Contains(err.Error(), "overwrite_on_conflict") {
+		t.Fatalf("expected the conflict to fail the update, got %+v", err)
+	}
+	if puts != 0 {
+		t.Errorf("expected the alert not to be updated, got %d updates", puts)
+	}
+
+	if err := kibanaPutAlert(client, "abc", "", alert, ruleAPIKibanaVersion, true); err != nil {
+		t.Fatalf("expected the change to be overwritten, got %+v", err)
+	}
+	if puts != 1 {
+		t.Errorf("expected the alert to be updated once, got %d updates", puts)
+	}
+
+	alert.Version = "WzIsMV0="
+	if err := kibanaPutAlert(client, "abc", "", alert, ruleAPIKibanaVersion, false); err != nil {
+		t.Fatalf("expected the alert at the current version to be updated, got %+v", err)
+	}
+	if puts != 2 {
+		t.Errorf("expected the alert to be updated, got %d updates", puts)
+	}
+}
+
+func TestKibanaAlertChangedFields(t *testing.T) {
+	current := kibana.Alert{Name: "terraform-alert", Tags: []string{"ops"}, Schedule: kibana.AlertSchedule{Interval: "5m"}}
+	alert := kibana.Alert{Name: "terraform-alert", Tags: []string{"ops"}, Schedule: kibana.AlertSchedule{Interval: "1m"}}
+	body, err := kibanaMarshalAlertUpdate(alert, ruleAPIKibanaVersion)
+	if err != nil {
+		t.Fatalf("error marshalling alert: %+v", err)
+	}
+
+	changed, err := kibanaAlertChangedFields(current, body, ruleAPIKibanaVersion)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !reflect.DeepEqual(changed, []string{"schedule"}) {
+		t.Errorf("expected only the schedule to be overwritten, got %v", changed)
+	}
+}
+
+func TestValidateKibanaAlertThrottle(t *testing.T) {
+	for _, test := range []struct {
+		notifyWhen string
+		throttle   string
+		valid      bool
+	}{
+		{"onThrottleInterval", "1h", true},
+		{"onThrottleInterval", "", false},
+		{"onActiveAlert", "", true},
+		{"onActiveAlert", "10m", false},
+		{"onActionGroupChange", "", true},
+		{"onActionGroupChange", "10m", false},
+	} {
+		err := validateKibanaAlertThrottle(test.notifyWhen, test.throttle)
+		if test.valid != (err == nil) {
+			t.Errorf("expected the throttle %q with notify_when %s to be valid %t, got %v", test.throttle, test.notifyWhen, test.valid, err)
 		}
+	}
+}
 
-		switch client := esClient.(type) {
-		case *elastic7.Client:
-			_, err = kibanaGetAlert(client, rs.Primary.ID, "")
-		default:
-			err = errors.New("Kibana Alerts only supported on ES >= 7.7")
+func TestCheckKibanaAlertSnoozeScheduleVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		esVersion string
+		schedules int
+		valid     bool
+	}{
+		{"8.3.0", 0, true},
+		{"8.3.0", 1, false},
+		{"8.4.0", 1, true},
+	} {
+		meta := &ProviderConf{rawUrl: ts.URL, parsedUrl: parsedUrl, esVersion: test.esVersion}
+		err := checkKibanaAlertSnoozeScheduleVersion(test.schedules, meta)
+		if test.valid != (err == nil) {
+			t.Errorf("expected %d snooze schedules on Kibana %s to be valid %t, got %v", test.schedules, test.esVersion, test.valid, err)
 		}
+	}
+}
+
+func TestKibanaMarshalAlert(t *testing.T) {
+	legacyVersion, _ := version.NewVersion("7.12.1")
+	kibana8Version, _ := version.NewVersion("8.0.0")
+	alert := kibana.Alert{
+		Name:        "terraform-alert",
+		Tags:        []string{"tag"},
+		AlertTypeID: ".index-threshold",
+		Schedule:    kibana.AlertSchedule{Interval: "1m"},
+		NotifyWhen:  "onActiveAlert",
+		Enabled:     true,
+		Consumer:    "alerts",
+		Params:      map[string]interface{}{"index": []string{"logs"}, "threshold": []int{10}},
+		Actions:     []kibana.AlertAction{{ID: "def", Group: "threshold met", ActionTypeId: ".index"}},
+	}
 
+	for _, test := range []struct {
+		name       string
+		version    *version.Version
+		update     bool
+		present    []string
+		notPresent []string
+	}{
+		{"legacy create", legacyVersion, false, []string{"alertTypeId", "notifyWhen", "consumer", "enabled"}, []string{"rule_type_id", "notify_when"}},
+		{"legacy update", legacyVersion, true, []string{"notifyWhen"}, []string{"alertTypeId", "consumer", "enabled", "rule_type_id"}},
+		{"rule create", ruleAPIKibanaVersion, false, []string{"rule_type_id", "notify_when", "consumer", "enabled"}, []string{"alertTypeId", "notifyWhen"}},
+		{"rule update", kibana8Version, true, []string{"notify_when"}, []string{"rule_type_id", "consumer", "enabled", "alertTypeId", "notifyWhen"}},
+	} {
+		var body []byte
+		var err error
+		if test.update {
+			body, err = kibanaMarshalAlertUpdate(alert, test.version)
+		} else {
+			body, err = kibanaMarshalAlert(alert, test.version)
+		}
 		if err != nil {
-			return nil // should be not found error
+			t.Fatalf("%s: error marshalling alert: %+v", test.name, err)
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(body, &fields); err != nil {
+			t.Fatalf("%s: error unmarshalling body: %+v", test.name, err)
+		}
+		for _, field := range test.present {
+			if _, ok := fields[field]; !ok {
+				t.Errorf("%s: expected the field %s in %s", test.name, field, body)
+			}
+		}
+		for _, field := range test.notPresent {
+			if _, ok := fields[field]; ok {
+				t.Errorf("%s: expected no field %s in %s", test.name, field, body)
+			}
 		}
+		// the params are passed as is to the alert type by both APIs
+		if params, ok := fields["params"].(map[string]interface{}); !ok || fmt.Sprint(params["index"]) != "[logs]" {
+			t.Errorf("%s: expected the params to be passed as is in %s", test.name, body)
+		}
+	}
+}
 
-		return fmt.Errorf("kibana alert %q still exists", rs.Primary.ID)
+func checkKibanaUnmarshalledAlert(t *testing.T, alert kibana.Alert) {
+	if alert.ID != "abc" || alert.AlertTypeID != ".index-threshold" || alert.NotifyWhen != "onActiveAlert" {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+	if len(alert.Actions) != 1 || alert.Actions[0].ActionTypeId != ".index" {
+		t.Errorf("unexpected alert actions %+v", alert.Actions)
+	}
+	if alert.ExecutionStatus == nil || alert.ExecutionStatus.LastExecutionDate != "2021-05-25T00:00:00.000Z" {
+		t.Errorf("unexpected alert execution status %+v", alert.ExecutionStatus)
+	}
+	if alert.ScheduledTaskID != "ghi" {
+		t.Errorf("unexpected alert scheduled task ID %q", alert.ScheduledTaskID)
+	}
+}
+
+// testKibanaAlertCreateSpace creates the space if it doesn't exist yet
+func testKibanaAlertCreateSpace(spaceID string) error {
+	diags := testAccKibanaProvider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		return diagnosticsAsError{diags}
 	}
+	meta := testAccKibanaProvider.Meta()
 
-	return nil
+	esClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/api/spaces/space",
+			Body:   fmt.Sprintf(`{"id":%q,"name":%q}`, spaceID, spaceID),
+		})
+		if e, ok := err.(*elastic7.Error); ok && e.Status == 409 {
+			return nil
+		}
+		return err
+	default:
+		return errors.New("Kibana Alerts only supported on ES >= 7.7")
+	}
 }
 
 func testKibanaAlertCreateAction() (string, error) {
@@ -238,6 +1294,84 @@ resource "elasticsearch_kibana_alert" "test" {
 `, actionID)
 }
 
+func testAccElasticsearchKibanaAlertMultipleActionsV77(actionID string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+  actions {
+  	id = "%s"
+  	action_type_id = ".index"
+  	group = "threshold met"
+  	params = {
+  		level = "warning"
+  		message = "alert '{{alertName}}' is active for group '{{context.group}}'"
+  	}
+  }
+  actions {
+  	id = "%s"
+  	action_type_id = ".index"
+  	group = "threshold met"
+  	params = {
+  		level = "info"
+  		message = "alert '{{alertName}}' recovered for group '{{context.group}}'"
+  	}
+  }
+}
+`, actionID, actionID)
+}
+
+func testAccElasticsearchKibanaAlertJSONParamsV77(actionID string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+  actions {
+  	id = "%s"
+  	action_type_id = ".index"
+  	group = "threshold met"
+  	params_json = jsonencode({
+  		documents = [{
+  			alert_name = "{{alertName}}"
+  			group = "{{context.group}}"
+  			value = "{{context.value}}"
+  		}]
+  	})
+  }
+}
+`, actionID)
+}
+
 var testAccElasticsearchKibanaAlertNoActionsV77 = `
 resource "elasticsearch_kibana_alert" "test" {
   name = "terraform-alert"
@@ -260,25 +1394,260 @@ resource "elasticsearch_kibana_alert" "test" {
 }
 `
 
-// var testAccElasticsearchKibanaAlertV711 = `
-// resource "elasticsearch_kibana_alert" "test" {
-//   name = "terraform-alert"
-//   notify_when = "onActionGroupChange"
-//   schedule {
-//   	interval = "1m"
-//   }
-//   conditions {
-//     aggregation_type = "avg"
-//     term_size = 6
-//     threshold_comparator = ">"
-//     time_window_size = 5
-//     time_window_unit = "m"
-//     group_by = "top"
-//     threshold = [1000]
-//     index = [".test-index"]
-//     time_field = "@timestamp"
-//     aggregation_field = "sheet.version"
-//     term_field = "name.keyword"
-//   }
-// }
-// `
+func testAccElasticsearchKibanaAlertSpace(spaceID string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name     = "terraform-alert"
+  space_id = "%s"
+  schedule {
+    interval = "1m"
+  }
+  conditions {
+    aggregation_type     = "avg"
+    term_size            = 6
+    threshold_comparator = ">"
+    time_window_size     = 5
+    time_window_unit     = "m"
+    group_by             = "top"
+    threshold            = [1000]
+    index                = [".test-index"]
+    time_field           = "@timestamp"
+    aggregation_field    = "sheet.version"
+    term_field           = "name.keyword"
+  }
+}
+`, spaceID)
+}
+
+func testAccElasticsearchKibanaAlertEnabled(enabled bool) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  enabled = %t
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+}
+`, enabled)
+}
+
+func testAccElasticsearchKibanaAlertTags(tags string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  tags = %s
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+}
+`, tags)
+}
+
+var testAccElasticsearchKibanaAlertDurations = `
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  throttle = "60m"
+  schedule {
+  	interval = "60s"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+}
+`
+
+var testAccElasticsearchKibanaAlertSnoozeSchedule = `
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+  snooze_schedule {
+    duration = "2h"
+    rrule {
+      dtstart   = "2030-01-06T02:00:00Z"
+      freq      = "weekly"
+      interval  = 1
+      byweekday = ["SU"]
+    }
+  }
+}
+`
+
+var testAccElasticsearchKibanaAlertV711 = `
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  notify_when = "onActionGroupChange"
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+}
+`
+
+func testAccElasticsearchKibanaAlertNotifyWhen(notifyWhen, throttle string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name        = "terraform-alert"
+  notify_when = %q
+  throttle    = %q
+  schedule {
+  	interval = "1m"
+  }
+  conditions {
+    aggregation_type = "avg"
+    term_size = 6
+    threshold_comparator = ">"
+    time_window_size = 5
+    time_window_unit = "m"
+    group_by = "top"
+    threshold = [1000]
+    index = [".test-index"]
+    time_field = "@timestamp"
+    aggregation_field = "sheet.version"
+    term_field = "name.keyword"
+  }
+}
+`, notifyWhen, throttle)
+}
+
+func testAccElasticsearchKibanaAlertValidateActionTypes(actionID string, actionTypeID string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name                  = "terraform-alert"
+  validate_action_types = true
+  schedule {
+    interval = "1m"
+  }
+  conditions {
+    aggregation_type     = "avg"
+    term_size            = 6
+    threshold_comparator = ">"
+    time_window_size     = 5
+    time_window_unit     = "m"
+    group_by             = "top"
+    threshold            = [1000]
+    index                = [".test-index"]
+    time_field           = "@timestamp"
+    aggregation_field    = "sheet.version"
+    term_field           = "name.keyword"
+  }
+  actions {
+    id             = "%s"
+    action_type_id = "%s"
+    group          = "threshold met"
+    params = {
+      level   = "info"
+      message = "alert '{{alertName}}' is active for group '{{context.group}}'"
+    }
+  }
+}
+`, actionID, actionTypeID)
+}
+
+func testAccElasticsearchKibanaAlertConditions(comparator, unit, threshold string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+  schedule {
+    interval = "1m"
+  }
+  conditions {
+    aggregation_type     = "avg"
+    term_size            = 6
+    threshold_comparator = "%s"
+    time_window_size     = 5
+    time_window_unit     = "%s"
+    group_by             = "top"
+    threshold            = %s
+    index                = [".test-index"]
+    time_field           = "@timestamp"
+    aggregation_field    = "sheet.version"
+    term_field           = "name.keyword"
+  }
+}
+`, comparator, unit, threshold)
+}
+
+func testAccElasticsearchKibanaAlertParamsJSON(size int) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_alert" "test" {
+  name          = "terraform-alert-es-query"
+  alert_type_id = ".es-query"
+  schedule {
+    interval = "1m"
+  }
+  params_json = jsonencode({
+    index               = [".test-index"]
+    timeField           = "@timestamp"
+    esQuery             = jsonencode({ query = { match_all = {} } })
+    size                = %d
+    thresholdComparator = ">"
+    threshold           = [0]
+    timeWindowSize      = 5
+    timeWindowUnit      = "m"
+  })
+}
+`, size)
+}
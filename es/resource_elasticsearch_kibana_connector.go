@@ -0,0 +1,372 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+func resourceElasticsearchKibanaConnector() *schema.Resource {
+	return &schema.Resource{
+		Description: "Connectors store the configuration and the secrets used by the actions of the Kibana alerts to integrate with other services, e.g. a webhook or an index. The name, config and secrets are updated in place so that the alerts referencing the connector by ID stay valid. For more see the [docs](https://www.elastic.co/guide/en/kibana/current/action-types.html).",
+		Create:      resourceElasticsearchKibanaConnectorCreate,
+		Read:        resourceElasticsearchKibanaConnectorRead,
+		Update:      resourceElasticsearchKibanaConnectorUpdate,
+		Delete:      resourceElasticsearchKibanaConnectorDelete,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "The name of the connector.",
+			},
+			"connector_type_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The connector type, e.g. `.webhook`, `.index` or `.slack`.",
+			},
+			"config": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "{}",
+				DiffSuppressFunc: suppressEquivalentJson,
+				ValidateFunc:     validation.StringIsJSON,
+				Description:      "The JSON configuration of the connector, specific to the connector type.",
+			},
+			// Kibana replaces the secrets on each update, they are kept in the
+			// state to be sent again when only the name or the config change
+			"secrets": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				DiffSuppressFunc: suppressEquivalentJson,
+				ValidateFunc:     validation.StringIsJSON,
+				Description:      "The JSON secrets of the connector, specific to the connector type, e.g. the credentials of a webhook. Kibana never returns them, changes made outside of Terraform are not detected.",
+			},
+			"secrets_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "A SHA-256 hash of the normalized JSON `secrets` last sent to Kibana, e.g. to trigger the replacement of the resources depending on the secrets without exposing them.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceElasticsearchKibanaConnectorCreate(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaConnectorCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	connector, err := kibanaConnectorFromResourceData(d)
+	if err != nil {
+		return err
+	}
+	spaceID := ""
+
+	var id string
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		id, err = kibanaPostConnector(client, spaceID, connector, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Connector endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[INFO] Kibana Connector (%s) created", id)
+	d.SetId(id)
+
+	if err := d.Set("secrets_hash", kibanaConnectorSecretsHash(d.Get("secrets"))); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchKibanaConnectorRead(d, meta)
+}
+
+func resourceElasticsearchKibanaConnectorRead(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaConnectorCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	id := d.Id()
+	spaceID := ""
+
+	var connector kibana.Connector
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		connector, err = kibanaGetConnector(client, id, spaceID, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Connector endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Kibana Connector (%s) not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	connectorTypeID := connector.ConnectorTypeID
+	if connectorTypeID == "" {
+		connectorTypeID = connector.ActionTypeID
+	}
+	config, err := json.Marshal(connector.Config)
+	if err != nil {
+		return err
+	}
+	if connector.Config == nil {
+		config = []byte("{}")
+	}
+
+	// the secrets are never returned, they are left as they are in the state
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", connector.Name)
+	ds.set("connector_type_id", connectorTypeID)
+	ds.set("config", string(config))
+	return ds.err
+}
+
+func resourceElasticsearchKibanaConnectorUpdate(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaConnectorCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	connector, err := kibanaConnectorFromResourceData(d)
+	if err != nil {
+		return err
+	}
+	spaceID := ""
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		err = kibanaPutConnector(client, d.Id(), spaceID, connector, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Connector endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := d.Set("secrets_hash", kibanaConnectorSecretsHash(d.Get("secrets"))); err != nil {
+		return err
+	}
+
+	return resourceElasticsearchKibanaConnectorRead(d, meta)
+}
+
+func resourceElasticsearchKibanaConnectorDelete(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaConnectorCheckVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	spaceID := ""
+
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		err = kibanaDeleteConnector(client, d.Id(), spaceID, elasticVersion)
+	default:
+		err = fmt.Errorf("Kibana Connector endpoint only available from Kibana >= 7.7, got version < 7.0.0")
+	}
+	if err != nil && !elastic7.IsNotFound(err) {
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// resourceElasticsearchKibanaConnectorCheckVersion returns the version used
+// to pick the connector or the legacy action endpoints
+func resourceElasticsearchKibanaConnectorCheckVersion(meta interface{}) (*version.Version, error) {
+	err := resourceElasticsearchKibanaAlertCheckVersion(meta)
+	if err != nil {
+		return nil, err
+	}
+	return resourceElasticsearchKibanaGetVersion(meta)
+}
+
+// kibanaConnectorSecretsHash returns the hash of the normalized secrets, so
+// that equivalent JSON strings have the same hash, the missing secrets are
+// sent as an empty object
+func kibanaConnectorSecretsHash(v interface{}) string {
+	if v.(string) == "" {
+		v = "{}"
+	}
+	secrets, err := structure.NormalizeJsonString(v)
+	if err != nil {
+		secrets = v.(string)
+	}
+	return hashSum(secrets)
+}
+
+func kibanaConnectorFromResourceData(d *schema.ResourceData) (kibana.Connector, error) {
+	connector := kibana.Connector{
+		Name:            d.Get("name").(string),
+		ConnectorTypeID: d.Get("connector_type_id").(string),
+	}
+
+	if err := json.Unmarshal([]byte(d.Get("config").(string)), &connector.Config); err != nil {
+		return connector, fmt.Errorf("fail to unmarshal config: %v", err)
+	}
+	if secrets, ok := d.GetOk("secrets"); ok {
+		if err := json.Unmarshal([]byte(secrets.(string)), &connector.Secrets); err != nil {
+			return connector, fmt.Errorf("fail to unmarshal secrets: %v", err)
+		}
+	}
+	if connector.Config == nil {
+		connector.Config = make(map[string]interface{})
+	}
+	if connector.Secrets == nil {
+		connector.Secrets = make(map[string]interface{})
+	}
+
+	return connector, nil
+}
+
+// kibanaConnectorPath returns the path of the connectors, or of the actions
+// for the Kibana versions before the connectors API
+func kibanaConnectorPath(id, spaceID string, elasticVersion *version.Version) (string, error) {
+	template := "/api/actions/connector"
+	if elasticVersion.LessThan(connectorTypesKibanaVersion) {
+		template = "/api/actions/action"
+	}
+	if id != "" {
+		template += "/{id}"
+	}
+
+	path, err := kibanaSpacePath(spaceID, template, map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error building URL path for connector: %+v", err)
+	}
+	return path, nil
+}
+
+func kibanaPostConnector(client *elastic7.Client, spaceID string, connector kibana.Connector, elasticVersion *version.Version) (string, error) {
+	path, err := kibanaConnectorPath("", spaceID, elasticVersion)
+	if err != nil {
+		return "", err
+	}
+
+	if elasticVersion.LessThan(connectorTypesKibanaVersion) {
+		connector.ActionTypeID, connector.ConnectorTypeID = connector.ConnectorTypeID, ""
+	}
+
+	body, err := json.Marshal(connector)
+	if err != nil {
+		return "", fmt.Errorf("Body Error: %s", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Body:   string(body),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var created kibana.Connector
+	if err := json.Unmarshal(res.Body, &created); err != nil {
+		return "", fmt.Errorf("error unmarshalling connector body: %+v: %+v", err, res.Body)
+	}
+
+	return created.ID, nil
+}
+
+func kibanaGetConnector(client *elastic7.Client, id, spaceID string, elasticVersion *version.Version) (kibana.Connector, error) {
+	path, err := kibanaConnectorPath(id, spaceID, elasticVersion)
+	if err != nil {
+		return kibana.Connector{}, err
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return kibana.Connector{}, err
+	}
+
+	var connector kibana.Connector
+	if err := json.Unmarshal(res.Body, &connector); err != nil {
+		return connector, fmt.Errorf("error unmarshalling connector body: %+v: %+v", err, res.Body)
+	}
+
+	return connector, nil
+}
+
+func kibanaPutConnector(client *elastic7.Client, id, spaceID string, connector kibana.Connector, elasticVersion *version.Version) error {
+	path, err := kibanaConnectorPath(id, spaceID, elasticVersion)
+	if err != nil {
+		return err
+	}
+
+	update := kibana.ConnectorUpdate{
+		Name:    connector.Name,
+		Config:  connector.Config,
+		Secrets: connector.Secrets,
+	}
+	body, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("Body Error: %s", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "PUT",
+		Path:   path,
+		Body:   string(body),
+	})
+	return err
+}
+
+func kibanaDeleteConnector(client *elastic7.Client, id, spaceID string, elasticVersion *version.Version) error {
+	path, err := kibanaConnectorPath(id, spaceID, elasticVersion)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	return err
+}
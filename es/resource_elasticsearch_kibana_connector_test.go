@@ -0,0 +1,236 @@
+package es
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchKibanaConnector(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	// We use the elasticsearch version to check compatibilty, it'll connect to
+	// kibana below
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	var connectorID string
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana Connectors only supported on ES >= 7.7")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaConnectorDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaConnector("terraform-connector", "changeme"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaConnectorExists("elasticsearch_kibana_connector.test", &connectorID),
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_connector.test", "connector_type_id", ".webhook"),
+				),
+			},
+			{
+				// rotating the password updates the connector in place, the
+				// alert keeps referencing it
+				Config: testAccElasticsearchKibanaConnector("terraform-connector", "rotated"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaConnectorSameID("elasticsearch_kibana_connector.test", &connectorID),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_connector.test", "secrets_hash", kibanaConnectorSecretsHash(`{"password":"rotated","user":"terraform"}`)),
+					testCheckElasticsearchKibanaAlertExists("elasticsearch_kibana_alert.test"),
+					resource.TestCheckResourceAttrPair("elasticsearch_kibana_alert.test", "actions.0.id", "elasticsearch_kibana_connector.test", "id"),
+				),
+			},
+			{
+				// renaming the connector sends the secrets of the state again
+				Config: testAccElasticsearchKibanaConnector("terraform-connector-renamed", "rotated"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchKibanaConnectorSameID("elasticsearch_kibana_connector.test", &connectorID),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_connector.test", "name", "terraform-connector-renamed"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_connector.test", "secrets_hash", kibanaConnectorSecretsHash(`{"password":"rotated","user":"terraform"}`)),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_kibana_connector.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"secrets",
+					"secrets_hash",
+				},
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchKibanaConnectorExists(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No kibana connector ID is set")
+		}
+
+		meta := testAccKibanaProvider.Meta()
+
+		elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+		if err != nil {
+			return err
+		}
+		esClient, err := getKibanaClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = kibanaGetConnector(client, rs.Primary.ID, "", elasticVersion)
+		default:
+			err = errors.New("Kibana Connectors only supported on ES >= 7.7")
+		}
+
+		if err != nil {
+			return err
+		}
+
+		*id = rs.Primary.ID
+		return nil
+	}
+}
+
+func testCheckElasticsearchKibanaConnectorSameID(name string, id *string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID != *id {
+			return fmt.Errorf("kibana connector was recreated, ID changed from %q to %q", *id, rs.Primary.ID)
+		}
+
+		return nil
+	}
+}
+
+func testCheckElasticsearchKibanaConnectorDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_kibana_connector" {
+			continue
+		}
+
+		meta := testAccKibanaProvider.Meta()
+
+		elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+		if err != nil {
+			return err
+		}
+		esClient, err := getKibanaClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = kibanaGetConnector(client, rs.Primary.ID, "", elasticVersion)
+		default:
+			err = errors.New("Kibana Connectors only supported on ES >= 7.7")
+		}
+
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("kibana connector %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccElasticsearchKibanaConnector(name, password string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_kibana_connector" "test" {
+  name              = "%s"
+  connector_type_id = ".webhook"
+  config = jsonencode({
+    url     = "http://example.com/hook"
+    method  = "post"
+    hasAuth = true
+  })
+  secrets = jsonencode({
+    user     = "terraform"
+    password = "%s"
+  })
+}
+
+resource "elasticsearch_kibana_alert" "test" {
+  name = "terraform-connector-alert"
+  schedule {
+    interval = "1m"
+  }
+  conditions {
+    aggregation_type     = "avg"
+    term_size            = 6
+    threshold_comparator = ">"
+    time_window_size     = 5
+    time_window_unit     = "m"
+    group_by             = "top"
+    threshold            = [1000]
+    index                = [".test-index"]
+    time_field           = "@timestamp"
+    aggregation_field    = "sheet.version"
+    term_field           = "name.keyword"
+  }
+  actions {
+    id             = elasticsearch_kibana_connector.test.id
+    action_type_id = ".webhook"
+    group          = "threshold met"
+    params = {
+      body = "alert '{{alertName}}' is active"
+    }
+  }
+}
+`, name, password)
+}
+
+func TestKibanaConnectorSecretsHash(t *testing.T) {
+	hash := kibanaConnectorSecretsHash(`{"user": "terraform", "password": "secret"}`)
+
+	if hash != kibanaConnectorSecretsHash(`{"password":"secret","user":"terraform"}`) {
+		t.Errorf("Expected equivalent secrets to have the same hash")
+	}
+	if hash == kibanaConnectorSecretsHash(`{"password":"rotated","user":"terraform"}`) {
+		t.Errorf("Expected rotated secrets to have a different hash")
+	}
+	if kibanaConnectorSecretsHash("") != kibanaConnectorSecretsHash("{}") {
+		t.Errorf("Expected missing secrets to have the hash of an empty object")
+	}
+	if len(hash) != 64 {
+		t.Errorf("Expected a SHA-256 hash, got %s", hash)
+	}
+}
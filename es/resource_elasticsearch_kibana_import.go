@@ -0,0 +1,345 @@
+package es
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+var createNewCopiesKibanaVersion, _ = version.NewVersion("7.9.0")
+
+func resourceElasticsearchKibanaImport() *schema.Resource {
+	return &schema.Resource{
+		Description: "Imports Kibana saved objects from NDJSON, e.g. the `ndjson` of the `elasticsearch_kibana_export` data source of another Kibana instance. The objects are imported again when one of them is deleted outside of Terraform or when the NDJSON changes, destroying the resource deletes the objects created by the import. The existing objects overwritten with `conflict_resolution` `overwrite` are not tracked, they are left in Kibana on destroy.",
+		Create:      resourceElasticsearchKibanaImportCreate,
+		Read:        resourceElasticsearchKibanaImportRead,
+		Delete:      resourceElasticsearchKibanaImportDelete,
+		Schema: map[string]*schema.Schema{
+			"ndjson": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "The saved objects to import, one JSON object per line.",
+			},
+			"space_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "The ID of the Kibana space to import the saved objects in, the default space when not set.",
+			},
+			"conflict_resolution": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Default:      "fail",
+				ValidateFunc: validation.StringInSlice([]string{"fail", "overwrite", "create_new_copies"}, false),
+				Description:  "How the objects which already exist are handled: `fail` the import, `overwrite` them, or `create_new_copies` of all the objects with new IDs, only available in Kibana >= 7.9.",
+			},
+			"success_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The number of imported saved objects.",
+			},
+			"imported_objects": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The saved objects created by the import, the overwritten existing objects are not included.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The type of the saved object.",
+						},
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the saved object in the NDJSON.",
+						},
+						"destination_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The ID of the imported saved object, it differs from `id` when new copies are created.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func resourceElasticsearchKibanaImportCreate(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	conflictResolution := d.Get("conflict_resolution").(string)
+	if conflictResolution == "create_new_copies" && elasticVersion.LessThan(createNewCopiesKibanaVersion) {
+		return fmt.Errorf("conflict_resolution create_new_copies is only available from Kibana >= 7.9, got version %s", elasticVersion.String())
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	spaceID := d.Get("space_id").(string)
+
+	var response kibana.SavedObjectsImportResponse
+	switch client := kibanaClient.(type) {
+	case *elastic7.Client:
+		response, err = kibanaImportSavedObjects(client, spaceID, d.Get("ndjson").(string), conflictResolution)
+	default:
+		err = fmt.Errorf("Kibana saved objects import endpoint only available from ElasticSearch >= 7.0, got version < 7.0.0")
+	}
+	if err != nil {
+		return err
+	}
+
+	importedObjects := flattenKibanaImportedObjects(response.SuccessResults)
+
+	// the objects without errors are imported anyway, they are tracked so
+	// that the tainted resource deletes them
+	d.SetId(resource.UniqueId())
+	ds := &resourceDataSetter{d: d}
+	ds.set("success_count", response.SuccessCount)
+	ds.set("imported_objects", importedObjects)
+	if ds.err != nil {
+		return ds.err
+	}
+
+	if !response.Success {
+		return kibanaImportErrors(response.Errors, spaceID)
+	}
+	return nil
+}
+
+// resourceElasticsearchKibanaImportRead checks that the imported objects
+// still exist, the import is planned again otherwise
+func resourceElasticsearchKibanaImportRead(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	spaceID := d.Get("space_id").(string)
+
+	for _, raw := range d.Get("imported_objects").([]interface{}) {
+		object := raw.(map[string]interface{})
+		objectType := object["type"].(string)
+		id := object["destination_id"].(string)
+
+		switch client := kibanaClient.(type) {
+		case *elastic7.Client:
+			err = kibanaGetSavedObject(client, objectType, id, spaceID, elasticVersion)
+		default:
+			err = fmt.Errorf("Kibana saved objects endpoint only available from ElasticSearch >= 7.0, got version < 7.0.0")
+		}
+		if err != nil {
+			if elastic7.IsNotFound(err) {
+				log.Printf("[WARN] Kibana saved object %s (%s) not found, removing the import (%s) from state", objectType, id, d.Id())
+				d.SetId("")
+				return nil
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+func resourceElasticsearchKibanaImportDelete(d *schema.ResourceData, meta interface{}) error {
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	spaceID := d.Get("space_id").(string)
+
+	for _, raw := range d.Get("imported_objects").([]interface{}) {
+		object := raw.(map[string]interface{})
+		objectType := object["type"].(string)
+		id := object["destination_id"].(string)
+
+		switch client := kibanaClient.(type) {
+		case *elastic7.Client:
+			err = kibanaDeleteSavedObject(client, objectType, id, spaceID, elasticVersion)
+		default:
+			err = fmt.Errorf("Kibana saved objects endpoint only available from ElasticSearch >= 7.0, got version < 7.0.0")
+		}
+		if err != nil && !elastic7.IsNotFound(err) {
+			return err
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// flattenKibanaImportedObjects keeps the objects created by the import, the
+// objects which existed before and were overwritten aren't owned by the
+// resource and must not be deleted with it
+func flattenKibanaImportedObjects(results []kibana.SavedObjectsImportSuccess) []map[string]interface{} {
+	objects := make([]map[string]interface{}, 0, len(results))
+	for _, result := range results {
+		if result.Overwrite {
+			continue
+		}
+		destinationID := result.DestinationID
+		if destinationID == "" {
+			destinationID = result.ID
+		}
+		objects = append(objects, map[string]interface{}{
+			"type":           result.Type,
+			"id":             result.ID,
+			"destination_id": destinationID,
+		})
+	}
+	return objects
+}
+
+// kibanaImportErrors describes the objects which failed to be imported, with
+// the missing references to add to the NDJSON
+func kibanaImportErrors(importErrors []kibana.SavedObjectsImportError, spaceID string) error {
+	var messages []string
+	for _, importError := range importErrors {
+		message := fmt.Sprintf("%s %s: %s", importError.Type, importError.ID, importError.Error.Type)
+		switch importError.Error.Type {
+		case "conflict":
+			message += ", the object already exists, set conflict_resolution to overwrite it or to create a new copy"
+		case "missing_references":
+			var references []string
+			for _, reference := range importError.Error.References {
+				references = append(references, fmt.Sprintf("%s %s", reference.Type, reference.ID))
+			}
+			message += fmt.Sprintf(" %s, export them too, e.g. with include_references_deep", strings.Join(references, ", "))
+		default:
+			if importError.Error.Message != "" {
+				message += " " + importError.Error.Message
+			}
+		}
+		messages = append(messages, message)
+	}
+	return fmt.Errorf("fail to import %d Kibana saved objects%s:\n%s", len(importErrors), kibanaSpaceDescription(spaceID), strings.Join(messages, "\n"))
+}
+
+func kibanaImportSavedObjects(client *elastic7.Client, spaceID, ndjson, conflictResolution string) (kibana.SavedObjectsImportResponse, error) {
+	path, err := kibanaSpacePath(spaceID, "/api/saved_objects/_import", map[string]string{})
+	if err != nil {
+		return kibana.SavedObjectsImportResponse{}, fmt.Errorf("error building URL path for saved objects import: %+v", err)
+	}
+
+	params := url.Values{}
+	switch conflictResolution {
+	case "overwrite":
+		params.Set("overwrite", "true")
+	case "create_new_copies":
+		params.Set("createNewCopies", "true")
+	}
+
+	// the endpoint only accepts the NDJSON as a file upload
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	file, err := writer.CreateFormFile("file", "export.ndjson")
+	if err != nil {
+		return kibana.SavedObjectsImportResponse{}, err
+	}
+	if _, err := file.Write([]byte(ndjson)); err != nil {
+		return kibana.SavedObjectsImportResponse{}, err
+	}
+	if err := writer.Close(); err != nil {
+		return kibana.SavedObjectsImportResponse{}, err
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method:      "POST",
+		Path:        path,
+		Params:      params,
+		Body:        body.String(),
+		ContentType: writer.FormDataContentType(),
+	})
+	if err != nil {
+		return kibana.SavedObjectsImportResponse{}, fmt.Errorf("error importing saved objects%s: %+v", kibanaSpaceDescription(spaceID), err)
+	}
+
+	var response kibana.SavedObjectsImportResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return response, fmt.Errorf("error unmarshalling saved objects import body: %+v: %+v", err, res.Body)
+	}
+
+	return response, nil
+}
+
+// kibanaGetSavedObject checks that a saved object exists, the alerts and the
+// connectors are hidden types which are only available from their own APIs
+func kibanaGetSavedObject(client *elastic7.Client, objectType, id, spaceID string, elasticVersion *version.Version) error {
+	switch objectType {
+	case "alert":
+		_, err := kibanaGetAlert(client, id, spaceID, elasticVersion)
+		return err
+	case "action":
+		_, err := kibanaGetConnector(client, id, spaceID, elasticVersion)
+		return err
+	}
+
+	path, err := kibanaSpacePath(spaceID, "/api/saved_objects/{type}/{id}", map[string]string{
+		"type": objectType,
+		"id":   id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for saved object: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	return err
+}
+
+func kibanaDeleteSavedObject(client *elastic7.Client, objectType, id, spaceID string, elasticVersion *version.Version) error {
+	switch objectType {
+	case "alert":
+		return kibanaDeleteAlert(client, id, spaceID, elasticVersion)
+	case "action":
+		return kibanaDeleteConnector(client, id, spaceID, elasticVersion)
+	}
+
+	path, err := kibanaSpacePath(spaceID, "/api/saved_objects/{type}/{id}", map[string]string{
+		"type": objectType,
+		"id":   id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for saved object: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	return err
+}
@@ -0,0 +1,159 @@
+package es
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	elastic7 "github.com/olivere/elastic/v7"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+
+	"github.com/phillbaker/terraform-provider-elasticsearch/kibana"
+)
+
+func TestAccElasticsearchKibanaImport(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+
+	var allowed bool
+	switch esClient.(type) {
+	case *elastic7.Client:
+		allowed = true
+	default:
+		allowed = false
+	}
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if !allowed {
+				t.Skip("Kibana saved objects import only supported on ES >= 7.0")
+			}
+		},
+		Providers:    testAccKibanaProviders,
+		CheckDestroy: testCheckElasticsearchKibanaImportDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchKibanaImport,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_kibana_import.test", "success_count", "1"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_import.test", "imported_objects.0.type", "index-pattern"),
+					resource.TestCheckResourceAttr("elasticsearch_kibana_import.test", "imported_objects.0.destination_id", "terraform-test-import"),
+				),
+			},
+			{
+				// the index pattern already exists
+				Config:      testAccElasticsearchKibanaImport + testAccElasticsearchKibanaImportConflict,
+				ExpectError: regexp.MustCompile("index-pattern terraform-test-import: conflict"),
+			},
+		},
+	})
+}
+
+func TestKibanaImportErrors(t *testing.T) {
+	err := kibanaImportErrors([]kibana.SavedObjectsImportError{
+		{
+			Type:  "dashboard",
+			ID:    "abc",
+			Error: kibana.SavedObjectsImportErrorDetails{Type: "conflict"},
+		},
+		{
+			Type: "visualization",
+			ID:   "def",
+			Error: kibana.SavedObjectsImportErrorDetails{
+				Type:       "missing_references",
+				References: []kibana.SavedObjectReference{{Type: "index-pattern", ID: "ghi"}},
+			},
+		},
+	}, "")
+
+	if !strings.Contains(err.Error(), "fail to import 2 Kibana saved objects") {
+		t.Errorf("unexpected error %q", err)
+	}
+	if !strings.Contains(err.Error(), "dashboard abc: conflict, the object already exists") {
+		t.Errorf("the conflict isn't described in %q", err)
+	}
+	if !strings.Contains(err.Error(), "visualization def: missing_references index-pattern ghi") {
+		t.Errorf("the missing references aren't described in %q", err)
+	}
+}
+
+func TestFlattenKibanaImportedObjects(t *testing.T) {
+	objects := flattenKibanaImportedObjects([]kibana.SavedObjectsImportSuccess{
+		{Type: "dashboard", ID: "abc"},
+		{Type: "index-pattern", ID: "def", Overwrite: true},
+		{Type: "visualization", ID: "ghi", DestinationID: "jkl"},
+	})
+
+	expected := []map[string]interface{}{
+		{"type": "dashboard", "id": "abc", "destination_id": "abc"},
+		{"type": "visualization", "id": "ghi", "destination_id": "jkl"},
+	}
+	if !reflect.DeepEqual(objects, expected) {
+		t.Errorf("expected %v, got %v", expected, objects)
+	}
+}
+
+func testCheckElasticsearchKibanaImportDestroy(s *terraform.State) error {
+	meta := testAccKibanaProvider.Meta()
+
+	elasticVersion, err := resourceElasticsearchKibanaGetVersion(meta)
+	if err != nil {
+		return err
+	}
+
+	kibanaClient, err := getKibanaClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_kibana_import" {
+			continue
+		}
+
+		switch client := kibanaClient.(type) {
+		case *elastic7.Client:
+			err = kibanaGetSavedObject(client, "index-pattern", "terraform-test-import", "", elasticVersion)
+		default:
+			err = nil
+		}
+
+		if err == nil {
+			return fmt.Errorf("Kibana saved object %q still exists", "terraform-test-import")
+		}
+	}
+
+	return nil
+}
+
+var testAccElasticsearchKibanaImport = `
+resource "elasticsearch_kibana_import" "test" {
+  ndjson = jsonencode({
+    type       = "index-pattern"
+    id         = "terraform-test-import"
+    attributes = { title = "terraform-test-*" }
+    references = []
+  })
+}
+`
+
+var testAccElasticsearchKibanaImportConflict = `
+resource "elasticsearch_kibana_import" "conflict" {
+  ndjson = elasticsearch_kibana_import.test.ndjson
+}
+`
@@ -0,0 +1,229 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func resourceElasticsearchScript() *schema.Resource {
+	return &schema.Resource{
+		Description: "Provides an Elasticsearch stored script resource, e.g. a Painless script referenced by the script processors of ingest pipelines or a Mustache search template. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/create-stored-script-api.html) for more details.",
+		Create:      resourceElasticsearchScriptCreate,
+		Read:        resourceElasticsearchScriptRead,
+		Update:      resourceElasticsearchScriptUpdate,
+		Delete:      resourceElasticsearchScriptDelete,
+		Schema: map[string]*schema.Schema{
+			"script_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "The ID of the stored script, used to reference it.",
+			},
+			"lang": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "painless",
+				ValidateFunc: validation.StringInSlice([]string{"painless", "expression", "mustache", "java"}, false),
+				Description:  "The language of the script: `painless`, `expression`, `mustache` or `java`. Defaults to `painless`.",
+			},
+			"source": {
+				Type:     schema.TypeString,
+				Required: true,
+				// the JSON sources of search templates are stored without
+				// formatting
+				DiffSuppressFunc: suppressEquivalentJson,
+				ValidateFunc:     validation.StringIsNotWhiteSpace,
+				Description:      "The source of the script, or of the search template for `mustache`.",
+			},
+			"params": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJson,
+				ValidateFunc:     validation.StringIsJSON,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+				Description: "A JSON string of the parameters of the script.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceElasticsearchScriptCreate(d *schema.ResourceData, meta interface{}) error {
+	id := d.Get("script_id").(string)
+	err := resourceElasticsearchPutScript(id, d, meta)
+	if err != nil {
+		return fmt.Errorf("error creating the script %s: %+v", id, err)
+	}
+	d.SetId(id)
+	return resourceElasticsearchScriptRead(d, meta)
+}
+
+func resourceElasticsearchScriptRead(d *schema.ResourceData, meta interface{}) error {
+	script, err := getStoredScript(d.Id(), meta)
+	if err != nil {
+		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+			log.Printf("[WARN] Script (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("script_id", d.Id())
+	ds.set("lang", script.Lang)
+	ds.set("source", script.Source)
+	// the parameters are only returned by some versions, the configured ones
+	// are kept otherwise
+	if len(script.Params) > 0 {
+		params, err := json.Marshal(script.Params)
+		if err != nil {
+			return err
+		}
+		ds.set("params", string(params))
+	}
+	return ds.err
+}
+
+func resourceElasticsearchScriptUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchPutScript(d.Id(), d, meta)
+	if err != nil {
+		return fmt.Errorf("error updating the script %s: %+v", d.Id(), err)
+	}
+	return resourceElasticsearchScriptRead(d, meta)
+}
+
+func resourceElasticsearchScriptDelete(d *schema.ResourceData, meta interface{}) error {
+	err := deleteStoredScript(d.Id(), meta)
+	if err != nil {
+		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceElasticsearchPutScript(id string, d *schema.ResourceData, meta interface{}) error {
+	script, err := storedScriptFromResourceData(d)
+	if err != nil {
+		return err
+	}
+	return putStoredScript(id, script, meta)
+}
+
+// putStoredScript, getStoredScript and deleteStoredScript are shared with the
+// search template resource, which stores mustache scripts
+func putStoredScript(id string, script *StoredScript, meta interface{}) error {
+	body := map[string]interface{}{
+		"script": script,
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PutScript().Id(id).BodyJson(body).Do(context.TODO())
+	case *elastic6.Client:
+		_, err = client.PutScript().Id(id).BodyJson(body).Do(context.TODO())
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+	return err
+}
+
+func getStoredScript(id string, meta interface{}) (*StoredScript, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		return elastic7GetScript(client, id)
+	case *elastic6.Client:
+		return elastic6GetScript(client, id)
+	default:
+		return nil, errors.New("Elasticsearch version not supported")
+	}
+}
+
+func deleteStoredScript(id string, meta interface{}) error {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.DeleteScript().Id(id).Do(context.TODO())
+	case *elastic6.Client:
+		_, err = client.DeleteScript().Id(id).Do(context.TODO())
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+	return err
+}
+
+func storedScriptFromResourceData(d *schema.ResourceData) (*StoredScript, error) {
+	script := &StoredScript{
+		Lang:   d.Get("lang").(string),
+		Source: d.Get("source").(string),
+	}
+	if params := d.Get("params").(string); params != "" {
+		if err := json.Unmarshal([]byte(params), &script.Params); err != nil {
+			return nil, fmt.Errorf("error unmarshalling the script params: %+v", err)
+		}
+	}
+	return script, nil
+}
+
+func elastic7GetScript(client *elastic7.Client, id string) (*StoredScript, error) {
+	res, err := client.GetScript().Id(id).Do(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return storedScriptFromResponse(id, res.Found, res.Script)
+}
+
+func elastic6GetScript(client *elastic6.Client, id string) (*StoredScript, error) {
+	res, err := client.GetScript().Id(id).Do(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	return storedScriptFromResponse(id, res.Found, res.Script)
+}
+
+func storedScriptFromResponse(id string, found bool, body json.RawMessage) (*StoredScript, error) {
+	if !found {
+		return nil, &elastic7.Error{Status: http.StatusNotFound}
+	}
+	var script StoredScript
+	if err := json.Unmarshal(body, &script); err != nil {
+		return nil, fmt.Errorf("error unmarshalling the script %s: %+v: %s", id, err, body)
+	}
+	return &script, nil
+}
+
+type StoredScript struct {
+	Lang   string                 `json:"lang"`
+	Source string                 `json:"source"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
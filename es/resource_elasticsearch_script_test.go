@@ -0,0 +1,151 @@
+package es
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func TestAccElasticsearchScript(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchScriptDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchScript("ctx._source.count += params.increment"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchScriptExists("elasticsearch_script.test"),
+					resource.TestCheckResourceAttr("elasticsearch_script.test", "lang", "painless"),
+					resource.TestCheckResourceAttr("elasticsearch_script.test", "source", "ctx._source.count += params.increment"),
+				),
+			},
+			{
+				Config: testAccElasticsearchScript("ctx._source.count -= params.increment"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchScriptExists("elasticsearch_script.test"),
+					resource.TestCheckResourceAttr("elasticsearch_script.test", "source", "ctx._source.count -= params.increment"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_script.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config: testAccElasticsearchScriptSearchTemplate,
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchScriptExists("elasticsearch_script.template"),
+					resource.TestCheckResourceAttr("elasticsearch_script.template", "lang", "mustache"),
+				),
+			},
+		},
+	})
+}
+
+func TestStoredScriptFromResponse(t *testing.T) {
+	script, err := storedScriptFromResponse("test", true, json.RawMessage(`{"lang":"painless","source":"ctx._source.count++","options":{}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := &StoredScript{Lang: "painless", Source: "ctx._source.count++"}
+	if !reflect.DeepEqual(script, expected) {
+		t.Errorf("expected the script %+v, got %+v", expected, script)
+	}
+
+	_, err = storedScriptFromResponse("test", false, nil)
+	if !elastic7.IsNotFound(err) {
+		t.Errorf("expected a not found error, got %+v", err)
+	}
+}
+
+func testCheckElasticsearchScriptExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No script ID is set")
+		}
+
+		meta := testAccProvider.Meta()
+
+		var err error
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = elastic7GetScript(client, rs.Primary.ID)
+		case *elastic6.Client:
+			_, err = elastic6GetScript(client, rs.Primary.ID)
+		default:
+			return errors.New("Elasticsearch version not supported")
+		}
+		return err
+	}
+}
+
+func testCheckElasticsearchScriptDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_script" {
+			continue
+		}
+
+		meta := testAccProvider.Meta()
+
+		var err error
+		esClient, err := getClient(meta.(*ProviderConf))
+		if err != nil {
+			return err
+		}
+		switch client := esClient.(type) {
+		case *elastic7.Client:
+			_, err = elastic7GetScript(client, rs.Primary.ID)
+		case *elastic6.Client:
+			_, err = elastic6GetScript(client, rs.Primary.ID)
+		default:
+			return errors.New("Elasticsearch version not supported")
+		}
+
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Script %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccElasticsearchScript(source string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_script" "test" {
+  script_id = "terraform-test-increment"
+  source    = "%s"
+}
+`, source)
+}
+
+var testAccElasticsearchScriptSearchTemplate = `
+resource "elasticsearch_script" "template" {
+  script_id = "terraform-test-search-template"
+  lang      = "mustache"
+  source = jsonencode({
+    query = {
+      match = {
+        message = "{{query_string}}"
+      }
+    }
+  })
+}
+`
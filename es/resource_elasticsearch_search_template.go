@@ -0,0 +1,183 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/structure"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	elastic7 "github.com/olivere/elastic/v7"
+	elastic6 "gopkg.in/olivere/elastic.v6"
+)
+
+func resourceElasticsearchSearchTemplate() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Provides an Elasticsearch search template resource, a stored script of the `mustache` language rendering a search request. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/search-template.html) for more details.",
+		Create:        resourceElasticsearchSearchTemplateCreate,
+		Read:          resourceElasticsearchSearchTemplateRead,
+		Update:        resourceElasticsearchSearchTemplateUpdate,
+		Delete:        resourceElasticsearchSearchTemplateDelete,
+		CustomizeDiff: resourceElasticsearchSearchTemplateValidate,
+		Schema: map[string]*schema.Schema{
+			"template_id": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringIsNotWhiteSpace,
+				Description:  "The ID of the search template, used to reference it in search requests.",
+			},
+			"template": {
+				Type:             schema.TypeString,
+				Required:         true,
+				DiffSuppressFunc: suppressEquivalentJson,
+				ValidateFunc:     validation.StringIsJSON,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+				Description: "A JSON string of the search request with the Mustache variables, e.g. `{{query_string}}`.",
+			},
+			"params": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				DiffSuppressFunc: suppressEquivalentJson,
+				ValidateFunc:     validation.StringIsJSON,
+				StateFunc: func(v interface{}) string {
+					json, _ := structure.NormalizeJsonString(v)
+					return json
+				},
+				Description: "A JSON string of the parameters of the template, also used to render it when `validate` is set.",
+			},
+			"validate": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to render the template with its `params` when planning, malformed templates fail the plan instead of the searches using them.",
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+// resourceElasticsearchSearchTemplateValidate renders the template, the
+// rendering fails when the template or its output are malformed
+func resourceElasticsearchSearchTemplateValidate(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("validate").(bool) || !d.NewValueKnown("template") || !d.NewValueKnown("params") {
+		return nil
+	}
+
+	body := map[string]interface{}{
+		"source": d.Get("template").(string),
+	}
+	if params := d.Get("params").(string); params != "" {
+		body["params"] = json.RawMessage(params)
+	}
+
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		_, err = client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_render/template",
+			Body:   body,
+		})
+	case *elastic6.Client:
+		_, err = client.PerformRequest(ctx, elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   "/_render/template",
+			Body:   body,
+		})
+	default:
+		return errors.New("Elasticsearch version not supported")
+	}
+	if err != nil {
+		return fmt.Errorf("error rendering the search template %s: %+v", d.Get("template_id").(string), err)
+	}
+	return nil
+}
+
+func resourceElasticsearchSearchTemplateCreate(d *schema.ResourceData, meta interface{}) error {
+	id := d.Get("template_id").(string)
+	err := resourceElasticsearchPutSearchTemplate(id, d, meta)
+	if err != nil {
+		return fmt.Errorf("error creating the search template %s: %+v", id, err)
+	}
+	d.SetId(id)
+	return resourceElasticsearchSearchTemplateRead(d, meta)
+}
+
+func resourceElasticsearchSearchTemplateRead(d *schema.ResourceData, meta interface{}) error {
+	script, err := getStoredScript(d.Id(), meta)
+	if err != nil {
+		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+			log.Printf("[WARN] Search template (%s) not found, removing from state", d.Id())
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+	if script.Lang != "mustache" {
+		return fmt.Errorf("the stored script %s is a %s script, not a search template", d.Id(), script.Lang)
+	}
+
+	// the source is stored as a string, formatted as it was sent
+	template, err := structure.NormalizeJsonString(script.Source)
+	if err != nil {
+		log.Printf("[WARN] The source of the search template %s isn't JSON: %+v", d.Id(), err)
+		template = script.Source
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("template_id", d.Id())
+	ds.set("template", template)
+	if len(script.Params) > 0 {
+		params, err := json.Marshal(script.Params)
+		if err != nil {
+			return err
+		}
+		ds.set("params", string(params))
+	}
+	return ds.err
+}
+
+func resourceElasticsearchSearchTemplateUpdate(d *schema.ResourceData, meta interface{}) error {
+	err := resourceElasticsearchPutSearchTemplate(d.Id(), d, meta)
+	if err != nil {
+		return fmt.Errorf("error updating the search template %s: %+v", d.Id(), err)
+	}
+	return resourceElasticsearchSearchTemplateRead(d, meta)
+}
+
+func resourceElasticsearchSearchTemplateDelete(d *schema.ResourceData, meta interface{}) error {
+	err := deleteStoredScript(d.Id(), meta)
+	if err != nil {
+		if elastic7.IsNotFound(err) || elastic6.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceElasticsearchPutSearchTemplate(id string, d *schema.ResourceData, meta interface{}) error {
+	script := &StoredScript{
+		Lang:   "mustache",
+		Source: d.Get("template").(string),
+	}
+	if params := d.Get("params").(string); params != "" {
+		if err := json.Unmarshal([]byte(params), &script.Params); err != nil {
+			return fmt.Errorf("error unmarshalling the search template params: %+v", err)
+		}
+	}
+	return putStoredScript(id, script, meta)
+}
@@ -0,0 +1,98 @@
+package es
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestAccElasticsearchSearchTemplate(t *testing.T) {
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchSearchTemplateDestroy,
+		Steps: []resource.TestStep{
+			{
+				// the section is not closed
+				Config:      testAccElasticsearchSearchTemplate("{{#query_string}}"),
+				ExpectError: regexp.MustCompile("error rendering the search template"),
+			},
+			{
+				Config: testAccElasticsearchSearchTemplate("{{query_string}}"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckElasticsearchSearchTemplateExists("elasticsearch_search_template.test"),
+					resource.TestCheckResourceAttr("elasticsearch_search_template.test", "template", `{"query":{"match":{"message":"{{query_string}}"}}}`),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_search_template.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					// not returned from the API
+					"validate",
+				},
+			},
+		},
+	})
+}
+
+func testCheckElasticsearchSearchTemplateExists(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources[name]
+		if !ok {
+			return fmt.Errorf("Not found: %s", name)
+		}
+		if rs.Primary.ID == "" {
+			return fmt.Errorf("No search template ID is set")
+		}
+
+		script, err := getStoredScript(rs.Primary.ID, testAccProvider.Meta())
+		if err != nil {
+			return err
+		}
+		if script.Lang != "mustache" {
+			return fmt.Errorf("Search template %q is a %s script", rs.Primary.ID, script.Lang)
+		}
+		return nil
+	}
+}
+
+func testCheckElasticsearchSearchTemplateDestroy(s *terraform.State) error {
+	for _, rs := range s.RootModule().Resources {
+		if rs.Type != "elasticsearch_search_template" {
+			continue
+		}
+
+		_, err := getStoredScript(rs.Primary.ID, testAccProvider.Meta())
+		if err != nil {
+			return nil // should be not found error
+		}
+
+		return fmt.Errorf("Search template %q still exists", rs.Primary.ID)
+	}
+
+	return nil
+}
+
+func testAccElasticsearchSearchTemplate(variable string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_search_template" "test" {
+  template_id = "terraform-test-search-template"
+  validate    = true
+  template = jsonencode({
+    query = {
+      match = {
+        message = "%s"
+      }
+    }
+  })
+  params = jsonencode({
+    query_string = "hello world"
+  })
+}
+`, variable)
+}
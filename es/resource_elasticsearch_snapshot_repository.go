@@ -2,8 +2,13 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -29,13 +34,48 @@ func resourceElasticsearchSnapshotRepository() *schema.Resource {
 				Type:     schema.TypeMap,
 				Optional: true,
 			},
+			"source_only": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates to create a source-only repository wrapping a repository of `type`, which only stores the stored fields of the indices, e.g. `_source`, to take minimal size snapshots. The `settings` are passed to the delegate repository.",
+				Optional:    true,
+				Default:     false,
+			},
 		},
+		CustomizeDiff: customdiff.All(
+			resourceElasticsearchSnapshotRepositoryValidateSourceOnly,
+		),
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 	}
 }
 
+// the types which can't be wrapped in a source-only repository
+var sourceOnlyInvalidDelegateTypes = []string{"source", "url"}
+
+func resourceElasticsearchSnapshotRepositoryValidateSourceOnly(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.Get("source_only").(bool) {
+		return nil
+	}
+
+	repositoryType := d.Get("type").(string)
+	for _, t := range sourceOnlyInvalidDelegateTypes {
+		if repositoryType == t {
+			return fmt.Errorf("a repository of type %q can't be wrapped in a source-only repository", repositoryType)
+		}
+	}
+
+	settings := d.Get("settings").(map[string]interface{})
+	if _, ok := settings["delegate_type"]; ok {
+		return fmt.Errorf("delegate_type is set from type for source-only repositories, remove it from settings")
+	}
+	if _, ok := settings["location"]; repositoryType == "fs" && !ok && d.NewValueKnown("settings") {
+		return fmt.Errorf("the location setting is required for a source-only repository wrapping a fs repository")
+	}
+
+	return nil
+}
+
 func resourceElasticsearchSnapshotRepositoryCreate(d *schema.ResourceData, meta interface{}) error {
 	err := resourceElasticsearchSnapshotRepositoryUpdate(d, meta)
 	if err != nil {
@@ -68,10 +108,19 @@ func resourceElasticsearchSnapshotRepositoryRead(d *schema.ResourceData, meta in
 		return err
 	}
 
+	// a source-only repository is exposed as the repository it wraps
+	sourceOnly := false
+	if delegateType, ok := settings["delegate_type"].(string); repositoryType == "source" && ok {
+		sourceOnly = true
+		repositoryType = delegateType
+		delete(settings, "delegate_type")
+	}
+
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", id)
 	ds.set("type", repositoryType)
 	ds.set("settings", settings)
+	ds.set("source_only", sourceOnly)
 	return ds.err
 }
 
@@ -97,10 +146,17 @@ func resourceElasticsearchSnapshotRepositoryUpdate(d *schema.ResourceData, meta
 	repositoryType := d.Get("type").(string)
 	name := d.Get("name").(string)
 
-	var settings map[string]interface{}
+	settings := make(map[string]interface{})
 
 	if v, ok := d.GetOk("settings"); ok {
-		settings = v.(map[string]interface{})
+		for k, setting := range v.(map[string]interface{}) {
+			settings[k] = setting
+		}
+	}
+
+	if d.Get("source_only").(bool) {
+		settings["delegate_type"] = repositoryType
+		repositoryType = "source"
 	}
 
 	var err error
@@ -117,9 +173,99 @@ func resourceElasticsearchSnapshotRepositoryUpdate(d *schema.ResourceData, meta
 		return errors.New("Elasticsearch version not supported")
 	}
 
+	if err != nil && d.Get("type").(string) == "fs" && strings.Contains(err.Error(), "path.repo") {
+		return snapshotRepositoryPathRepoError(esClient, name, settings["location"], err)
+	}
+
 	return err
 }
 
+// snapshotRepositoryPathRepoError explains the rejection of a fs repository
+// whose location isn't registered in the path.repo setting, with the setting
+// of each node when it can be read
+func snapshotRepositoryPathRepoError(esClient interface{}, name string, location interface{}, err error) error {
+	message := fmt.Sprintf("the location %v of the fs repository %s must be registered in the path.repo setting of all the master and data nodes, set it in elasticsearch.yml and restart the nodes", location, name)
+
+	var body json.RawMessage
+	var nodesErr error
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, nodesErr = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_nodes/settings",
+		})
+		if nodesErr == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, nodesErr = client.PerformRequest(context.TODO(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_nodes/settings",
+		})
+		if nodesErr == nil {
+			body = res.Body
+		}
+	}
+
+	if body != nil {
+		if nodesPathRepo, decodeErr := snapshotRepositoryNodesPathRepo(body); decodeErr == nil {
+			nodes := make([]string, 0, len(nodesPathRepo))
+			for node, paths := range nodesPathRepo {
+				if len(paths) == 0 {
+					nodes = append(nodes, fmt.Sprintf("%s: not set", node))
+				} else {
+					nodes = append(nodes, fmt.Sprintf("%s: %s", node, strings.Join(paths, ", ")))
+				}
+			}
+			sort.Strings(nodes)
+			message = fmt.Sprintf("%s (path.repo of the nodes: %s)", message, strings.Join(nodes, "; "))
+		}
+	}
+
+	return fmt.Errorf("%s: %+v", message, err)
+}
+
+// snapshotRepositoryNodesPathRepo returns the path.repo setting of each node
+// from the body of the nodes settings, the setting is either a string or a
+// list of strings
+func snapshotRepositoryNodesPathRepo(body json.RawMessage) (map[string][]string, error) {
+	var response struct {
+		Nodes map[string]struct {
+			Name     string `json:"name"`
+			Settings struct {
+				Path struct {
+					Repo interface{} `json:"repo"`
+				} `json:"path"`
+			} `json:"settings"`
+		} `json:"nodes"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("error unmarshalling nodes settings body: %+v: %+v", err, body)
+	}
+
+	nodesPathRepo := make(map[string][]string, len(response.Nodes))
+	for id, node := range response.Nodes {
+		name := node.Name
+		if name == "" {
+			name = id
+		}
+		var paths []string
+		switch repo := node.Settings.Path.Repo.(type) {
+		case string:
+			paths = append(paths, repo)
+		case []interface{}:
+			for _, path := range repo {
+				paths = append(paths, fmt.Sprintf("%v", path))
+			}
+		}
+		nodesPathRepo[name] = paths
+	}
+
+	return nodesPathRepo, nil
+}
+
 func elastic7SnapshotCreateRepository(client *elastic7.Client, name string, repositoryType string, settings map[string]interface{}) error {
 	repo := elastic7.SnapshotRepositoryMetaData{
 		Type:     repositoryType,
@@ -2,8 +2,11 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -47,6 +50,66 @@ func TestAccElasticsearchSnapshotRepository_importBasic(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchSnapshotRepository_sourceOnly(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:  func() { testAccPreCheck(t) },
+		Providers: testAccXPackProviders,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccElasticsearchSnapshotRepositorySourceOnly,
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_snapshot_repository.test", "type", "fs"),
+					resource.TestCheckResourceAttr("elasticsearch_snapshot_repository.test", "source_only", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_snapshot_repository.test", "settings.location", "/tmp/elasticsearch-source"),
+				),
+			},
+			{
+				ResourceName:      "elasticsearch_snapshot_repository.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      testAccElasticsearchSnapshotRepositorySourceOnlyInvalid,
+				ExpectError: regexp.MustCompile(`a repository of type "url" can't be wrapped in a source-only repository`),
+			},
+		},
+	})
+}
+
+func TestAccElasticsearchSnapshotRepository_pathRepo(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		PreCheck:     func() { testAccPreCheck(t) },
+		Providers:    testAccProviders,
+		CheckDestroy: testCheckElasticsearchSnapshotRepositoryDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccElasticsearchSnapshotRepositoryPathRepo,
+				ExpectError: regexp.MustCompile(`must be registered in the path.repo setting`),
+			},
+		},
+	})
+}
+
+func TestSnapshotRepositoryNodesPathRepo(t *testing.T) {
+	body := json.RawMessage(`{"nodes": {
+		"a1": {"name": "node-1", "settings": {"path": {"repo": ["/tmp/elasticsearch", "/mnt/backups"]}}},
+		"b2": {"name": "node-2", "settings": {"path": {"repo": "/tmp/elasticsearch"}}},
+		"c3": {"name": "node-3", "settings": {}}
+	}}`)
+	nodesPathRepo, err := snapshotRepositoryNodesPathRepo(body)
+	if err != nil {
+		t.Fatalf("err: %+v", err)
+	}
+	expected := map[string][]string{
+		"node-1": {"/tmp/elasticsearch", "/mnt/backups"},
+		"node-2": {"/tmp/elasticsearch"},
+		"node-3": nil,
+	}
+	if !reflect.DeepEqual(nodesPathRepo, expected) {
+		t.Errorf("expected %v, got %v", expected, nodesPathRepo)
+	}
+}
+
 func testCheckElasticsearchSnapshotRepositoryExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -123,3 +186,38 @@ resource "elasticsearch_snapshot_repository" "test" {
   }
 }
 `
+
+var testAccElasticsearchSnapshotRepositorySourceOnly = `
+resource "elasticsearch_snapshot_repository" "test" {
+  name        = "terraform-test-source"
+  type        = "fs"
+  source_only = true
+
+  settings = {
+    location = "/tmp/elasticsearch-source"
+  }
+}
+`
+
+var testAccElasticsearchSnapshotRepositorySourceOnlyInvalid = `
+resource "elasticsearch_snapshot_repository" "test" {
+  name        = "terraform-test-source"
+  type        = "url"
+  source_only = true
+
+  settings = {
+    url = "file:/tmp/elasticsearch-source"
+  }
+}
+`
+
+var testAccElasticsearchSnapshotRepositoryPathRepo = `
+resource "elasticsearch_snapshot_repository" "test" {
+  name = "terraform-test-path-repo"
+  type = "fs"
+
+  settings = {
+    location = "/terraform-not-in-path-repo"
+  }
+}
+`
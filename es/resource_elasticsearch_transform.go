@@ -0,0 +1,837 @@
+package es
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
+	elastic7 "github.com/olivere/elastic/v7"
+)
+
+var transformMinimalVersion, _ = version.NewVersion("7.5.0")
+var transformRetentionPolicyMinimalVersion, _ = version.NewVersion("7.12.0")
+var transformDeduceMappingsMinimalVersion, _ = version.NewVersion("8.1.0")
+
+func resourceElasticsearchTransform() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Provides an Elasticsearch transform resource. Transforms convert existing indices into summarized indices, see the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/transforms.html) for more details.",
+		Create:        resourceElasticsearchTransformCreate,
+		Read:          resourceElasticsearchTransformRead,
+		Update:        resourceElasticsearchTransformUpdate,
+		Delete:        resourceElasticsearchTransformDelete,
+		CustomizeDiff: resourceElasticsearchTransformValidateDeduceMappings,
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Description: "Identifier for the transform.",
+				ForceNew:    true,
+				Required:    true,
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Description: "Free text description of the transform.",
+				Optional:    true,
+			},
+			"source": {
+				Type:        schema.TypeList,
+				Description: "The source of the data for the transform.",
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"indices": {
+							Type:        schema.TypeList,
+							Description: "The source indices for the transform.",
+							Required:    true,
+							MinItems:    1,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"query": {
+							Type:             schema.TypeString,
+							Description:      "A JSON string of a query clause that retrieves a subset of data from the source indices.",
+							Optional:         true,
+							Default:          `{"match_all":{}}`,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJson,
+						},
+					},
+				},
+			},
+			"dest": {
+				Type:        schema.TypeList,
+				Description: "The destination for the transform.",
+				Required:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"index": {
+							Type:        schema.TypeString,
+							Description: "The destination index for the transform.",
+							Required:    true,
+						},
+						"pipeline": {
+							Type:        schema.TypeString,
+							Description: "The unique identifier for an ingest pipeline.",
+							Optional:    true,
+						},
+						"mappings": {
+							Type:             schema.TypeString,
+							Description:      "A JSON string of the mappings of the destination index, which is created with them before the transform when it doesn't exist. It requires `deduce_mappings` to be `false` and isn't read back from the API.",
+							Optional:         true,
+							ForceNew:         true,
+							ValidateFunc:     validation.StringIsJSON,
+							DiffSuppressFunc: suppressEquivalentJson,
+						},
+					},
+				},
+			},
+			"pivot": {
+				Type:             schema.TypeString,
+				Description:      "A JSON string defining the `group_by` and `aggregations` of a pivot transform.",
+				Optional:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{"pivot", "latest"},
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+			},
+			"latest": {
+				Type:             schema.TypeString,
+				Description:      "A JSON string defining the `unique_key` and `sort` of a latest transform, available from ElasticSearch >= 7.12.",
+				Optional:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{"pivot", "latest"},
+				ValidateFunc:     validation.StringIsJSON,
+				DiffSuppressFunc: suppressEquivalentJson,
+			},
+			"frequency": {
+				Type:         schema.TypeString,
+				Description:  "The interval between checks for changes in the source indices when the transform is running continuously, e.g. `1m`.",
+				Optional:     true,
+				ValidateFunc: validateIndexDuration,
+			},
+			"sync": {
+				Type:        schema.TypeList,
+				Description: "Defines the properties transforms require to run continuously.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"time": {
+							Type:     schema.TypeList,
+							Required: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"field": {
+										Type:        schema.TypeString,
+										Description: "The date field that is used to identify new documents in the source.",
+										Required:    true,
+									},
+									"delay": {
+										Type:         schema.TypeString,
+										Description:  "The time delay between the current time and the latest input data time, defaults to `60s`.",
+										Optional:     true,
+										Default:      "60s",
+										ValidateFunc: validateIndexDuration,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"retention_policy": {
+				Type:        schema.TypeList,
+				Description: "Defines the retention of the documents of the destination index, the older documents are deleted. Available from ElasticSearch >= 7.12.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"time": {
+							Type:     schema.TypeList,
+							Required: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"field": {
+										Type:        schema.TypeString,
+										Description: "The date field of the destination index that is used to calculate the age of the documents.",
+										Required:    true,
+									},
+									"max_age": {
+										Type:         schema.TypeString,
+										Description:  "The maximum age of the documents in the destination index, e.g. `30d`, it must be at least `60s`.",
+										Required:     true,
+										ValidateFunc: validateIndexDuration,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			"settings": {
+				Type:        schema.TypeList,
+				Description: "The settings tuning the throughput of the transform, removed settings are reset to their defaults.",
+				Optional:    true,
+				MaxItems:    1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"max_page_search_size": {
+							Type:         schema.TypeInt,
+							Description:  "The initial page size of the composite aggregation of each checkpoint, between 10 and 65536, defaults to 500.",
+							Optional:     true,
+							ValidateFunc: validation.IntBetween(10, 65536),
+						},
+						"docs_per_second": {
+							Type:         schema.TypeFloat,
+							Description:  "The limit of input documents per second, throttling the transform. The transform isn't throttled by default.",
+							Optional:     true,
+							ValidateFunc: validation.FloatAtLeast(0),
+						},
+						"num_failure_retries": {
+							Type:         schema.TypeString,
+							Description:  "A stringified number of retries on a recoverable failure before the transform fails, between `-1` (infinite retries) and `100` (ElasticSearch >= 8.4). The default is the `xpack.transform.num_transform_failure_retries` cluster setting.",
+							Optional:     true,
+							ValidateFunc: validateTransformNumFailureRetries,
+						},
+					},
+				},
+			},
+			"deduce_mappings": {
+				Type:        schema.TypeBool,
+				Description: "Whether the transform deduces the mappings of the destination index from the source indices when it creates it. When `false`, the destination index must exist with explicit mappings, e.g. with `dest.mappings`. Disabling it is available from ElasticSearch >= 8.1.",
+				Optional:    true,
+				Default:     true,
+			},
+			"start": {
+				Type:        schema.TypeBool,
+				Description: "Whether to start the transform, it is started after its creation and started or stopped when the value changes. A batch transform stops by itself once it completes, this isn't read back from the API.",
+				Optional:    true,
+				Default:     false,
+			},
+			"headers": {
+				Type:        schema.TypeMap,
+				Description: "Extra HTTP headers sent when creating or updating the transform. The transform runs with the privileges of the user creating or updating it, use the `es-secondary-authorization` header, e.g. `ApiKey <key>`, to run it as another identity. These are not read back from the API.",
+				Optional:    true,
+				Sensitive:   true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func validateTransformNumFailureRetries(v interface{}, k string) (ws []string, errors []error) {
+	retries, err := strconv.Atoi(v.(string))
+	if err != nil || retries < -1 || retries > 100 {
+		errors = append(errors, fmt.Errorf("%q must be a stringified number between -1 and 100, got %s", k, v))
+	}
+	return
+}
+
+// resourceElasticsearchTransformValidateDeduceMappings rejects the mappings of
+// the destination index when the transform deduces them
+func resourceElasticsearchTransformValidateDeduceMappings(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if !d.NewValueKnown("deduce_mappings") || !d.NewValueKnown("dest") {
+		return nil
+	}
+	if mappings, ok := d.GetOk("dest.0.mappings"); ok && mappings.(string) != "" && d.Get("deduce_mappings").(bool) {
+		return fmt.Errorf("dest.0.mappings requires deduce_mappings to be false, the transform deduces the mappings of the destination index otherwise")
+	}
+	return nil
+}
+
+func resourceElasticsearchTransformCreate(d *schema.ResourceData, meta interface{}) error {
+	name := d.Get("name").(string)
+
+	body, err := transformFromResourceData(d, true)
+	if err != nil {
+		return err
+	}
+	if err := checkTransformRetentionPolicy(d, meta); err != nil {
+		return err
+	}
+	if err := checkTransformDeduceMappings(d, meta); err != nil {
+		return err
+	}
+
+	esClient, err := resourceElasticsearchTransformClient(meta)
+	if err != nil {
+		return err
+	}
+
+	if mappings, ok := d.GetOk("dest.0.mappings"); ok {
+		err = elastic7CreateTransformDestIndex(esClient, d.Get("dest.0.index").(string), mappings.(string))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = elastic7PutTransform(esClient, name, body, transformHeaders(d))
+	if err != nil {
+		return err
+	}
+	d.SetId(name)
+
+	if d.Get("start").(bool) {
+		err = elastic7StartTransform(esClient, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchTransformRead(d, meta)
+}
+
+func resourceElasticsearchTransformRead(d *schema.ResourceData, meta interface{}) error {
+	id := d.Id()
+
+	esClient, err := resourceElasticsearchTransformClient(meta)
+	if err != nil {
+		return err
+	}
+
+	transform, err := elastic7GetTransform(esClient, id)
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Transform (%s) not found, removing from state", id)
+			d.SetId("")
+			return nil
+		}
+		return err
+	}
+
+	ds := &resourceDataSetter{d: d}
+	ds.set("name", transform.ID)
+	ds.set("description", transform.Description)
+	ds.set("frequency", transform.Frequency)
+
+	if transform.Source != nil {
+		query := ""
+		if transform.Source.Query != nil {
+			queryJSON, err := json.Marshal(transform.Source.Query)
+			if err != nil {
+				return err
+			}
+			query = string(queryJSON)
+		}
+		ds.set("source", []map[string]interface{}{
+			{
+				"indices": transform.Source.Index,
+				"query":   query,
+			},
+		})
+	}
+
+	if transform.Dest != nil {
+		ds.set("dest", []map[string]interface{}{
+			{
+				"index":    transform.Dest.Index,
+				"pipeline": transform.Dest.Pipeline,
+				"mappings": d.Get("dest.0.mappings"),
+			},
+		})
+	}
+
+	if transform.Pivot != nil {
+		pivot, err := json.Marshal(transform.Pivot)
+		if err != nil {
+			return err
+		}
+		ds.set("pivot", string(pivot))
+	}
+	if transform.Latest != nil {
+		latest, err := json.Marshal(transform.Latest)
+		if err != nil {
+			return err
+		}
+		ds.set("latest", string(latest))
+	}
+
+	if transform.Sync != nil && transform.Sync.Time != nil {
+		ds.set("sync", []map[string]interface{}{
+			{
+				"time": []map[string]interface{}{
+					{
+						"field": transform.Sync.Time.Field,
+						"delay": transform.Sync.Time.Delay,
+					},
+				},
+			},
+		})
+	} else {
+		ds.set("sync", nil)
+	}
+
+	ds.set("retention_policy", flattenTransformRetentionPolicy(transform.RetentionPolicy))
+	ds.set("settings", flattenTransformSettings(transform.Settings))
+	// the setting is only returned when it was set
+	deduceMappings, ok := transform.Settings["deduce_mappings"].(bool)
+	ds.set("deduce_mappings", deduceMappings || !ok)
+
+	return ds.err
+}
+
+func resourceElasticsearchTransformUpdate(d *schema.ResourceData, meta interface{}) error {
+	body, err := transformFromResourceData(d, false)
+	if err != nil {
+		return err
+	}
+	if err := checkTransformRetentionPolicy(d, meta); err != nil {
+		return err
+	}
+	if err := checkTransformDeduceMappings(d, meta); err != nil {
+		return err
+	}
+
+	esClient, err := resourceElasticsearchTransformClient(meta)
+	if err != nil {
+		return err
+	}
+
+	err = elastic7UpdateTransform(esClient, d.Id(), body, transformHeaders(d))
+	if err != nil {
+		return err
+	}
+
+	if d.HasChange("start") {
+		if d.Get("start").(bool) {
+			err = elastic7StartTransform(esClient, d.Id())
+		} else {
+			err = elastic7StopTransform(esClient, d.Id())
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchTransformRead(d, meta)
+}
+
+func resourceElasticsearchTransformDelete(d *schema.ResourceData, meta interface{}) error {
+	esClient, err := resourceElasticsearchTransformClient(meta)
+	if err != nil {
+		return err
+	}
+
+	// a started transform can't be deleted
+	err = elastic7StopTransform(esClient, d.Id())
+	if err == nil {
+		err = elastic7DeleteTransform(esClient, d.Id())
+	}
+	if err != nil {
+		if elastic7.IsNotFound(err) {
+			log.Printf("[WARN] Transform (%s) not found, removing from state", d.Id())
+			return nil
+		}
+		return err
+	}
+
+	d.SetId("")
+	return nil
+}
+
+func resourceElasticsearchTransformClient(meta interface{}) (*elastic7.Client, error) {
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+		if err != nil {
+			return nil, err
+		}
+		if elasticVersion.LessThan(transformMinimalVersion) {
+			return nil, fmt.Errorf("transform endpoint only available from ElasticSearch >= 7.5, got version %s", elasticVersion.String())
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("transform endpoint only available from ElasticSearch >= 7.5, got version < 7.0.0")
+	}
+}
+
+// transformFromResourceData builds the body of the transform, the pivot and
+// latest definitions can only be set on creation
+func transformFromResourceData(d *schema.ResourceData, create bool) (Transform, error) {
+	transform := Transform{
+		Description: d.Get("description").(string),
+		Frequency:   d.Get("frequency").(string),
+	}
+
+	source := d.Get("source").([]interface{})[0].(map[string]interface{})
+	transform.Source = &TransformSource{
+		Index: expandStringList(source["indices"].([]interface{})),
+	}
+	if query, ok := source["query"].(string); ok && query != "" {
+		transform.Source.Query = optionalInterfaceJson(query)
+	}
+
+	dest := d.Get("dest").([]interface{})[0].(map[string]interface{})
+	transform.Dest = &TransformDest{
+		Index:    dest["index"].(string),
+		Pipeline: dest["pipeline"].(string),
+	}
+
+	if v, ok := d.GetOk("sync"); ok {
+		sync := v.([]interface{})[0].(map[string]interface{})
+		syncTime := sync["time"].([]interface{})[0].(map[string]interface{})
+		transform.Sync = &TransformSync{
+			Time: &TransformSyncTime{
+				Field: syncTime["field"].(string),
+				Delay: syncTime["delay"].(string),
+			},
+		}
+	}
+
+	if v, ok := d.GetOk("retention_policy"); ok {
+		retentionPolicy := v.([]interface{})[0].(map[string]interface{})
+		retentionTime := retentionPolicy["time"].([]interface{})[0].(map[string]interface{})
+		transform.RetentionPolicy = &TransformRetentionPolicy{
+			Time: &TransformRetentionPolicyTime{
+				Field:  retentionTime["field"].(string),
+				MaxAge: retentionTime["max_age"].(string),
+			},
+		}
+	} else if !create && d.HasChange("retention_policy") {
+		// a null retention policy removes it
+		transform.RetentionPolicy = json.RawMessage("null")
+	}
+
+	transform.Settings = expandTransformSettings(d, create)
+
+	if create {
+		if pivot, ok := d.GetOk("pivot"); ok {
+			transform.Pivot = optionalInterfaceJson(pivot.(string))
+		}
+		if latest, ok := d.GetOk("latest"); ok {
+			transform.Latest = optionalInterfaceJson(latest.(string))
+		}
+	}
+
+	return transform, nil
+}
+
+func checkTransformRetentionPolicy(d *schema.ResourceData, meta interface{}) error {
+	if _, ok := d.GetOk("retention_policy"); !ok {
+		return nil
+	}
+
+	elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(transformRetentionPolicyMinimalVersion) {
+		return fmt.Errorf("transform retention_policy only available from ElasticSearch >= 7.12, got version %s", elasticVersion.String())
+	}
+	return nil
+}
+
+func checkTransformDeduceMappings(d *schema.ResourceData, meta interface{}) error {
+	if d.Get("deduce_mappings").(bool) {
+		return nil
+	}
+
+	elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(transformDeduceMappingsMinimalVersion) {
+		return fmt.Errorf("transform deduce_mappings can only be disabled from ElasticSearch >= 8.1, got version %s", elasticVersion.String())
+	}
+	return nil
+}
+
+// flattenTransformRetentionPolicy reads the retention policy decoded from the
+// transform body
+func flattenTransformRetentionPolicy(v interface{}) []map[string]interface{} {
+	retentionPolicy, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	retentionTime, ok := retentionPolicy["time"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	return []map[string]interface{}{
+		{
+			"time": []map[string]interface{}{
+				{
+					"field":   retentionTime["field"],
+					"max_age": retentionTime["max_age"],
+				},
+			},
+		},
+	}
+}
+
+// expandTransformSettings returns the configured settings, on update the
+// removed settings are set to null which resets them to their defaults
+func expandTransformSettings(d *schema.ResourceData, create bool) map[string]interface{} {
+	o, n := d.GetChange("settings")
+	settings := transformSettingsFromList(n.([]interface{}))
+
+	if !create {
+		for key := range transformSettingsFromList(o.([]interface{})) {
+			if _, ok := settings[key]; !ok {
+				settings[key] = nil
+			}
+		}
+	}
+
+	// the setting has its own argument, it is only sent when it isn't the
+	// default or when it changes
+	deduceMappings := d.Get("deduce_mappings").(bool)
+	if !deduceMappings || (!create && d.HasChange("deduce_mappings")) {
+		settings["deduce_mappings"] = deduceMappings
+	}
+
+	if len(settings) == 0 {
+		return nil
+	}
+	return settings
+}
+
+// transformSettingsFromList returns the settings of the block with a value,
+// the zero values are the unset attributes
+func transformSettingsFromList(list []interface{}) map[string]interface{} {
+	settings := make(map[string]interface{})
+	if len(list) == 0 || list[0] == nil {
+		return settings
+	}
+	raw := list[0].(map[string]interface{})
+
+	if v, ok := raw["max_page_search_size"].(int); ok && v != 0 {
+		settings["max_page_search_size"] = v
+	}
+	if v, ok := raw["docs_per_second"].(float64); ok && v != 0 {
+		settings["docs_per_second"] = v
+	}
+	if v, ok := raw["num_failure_retries"].(string); ok && v != "" {
+		// validated by the schema
+		retries, _ := strconv.Atoi(v)
+		settings["num_failure_retries"] = retries
+	}
+	return settings
+}
+
+func flattenTransformSettings(settings map[string]interface{}) []map[string]interface{} {
+	if len(settings) == 0 {
+		return nil
+	}
+
+	flattened := make(map[string]interface{})
+	if v, ok := settings["max_page_search_size"].(float64); ok {
+		flattened["max_page_search_size"] = int(v)
+	}
+	if v, ok := settings["docs_per_second"].(float64); ok {
+		flattened["docs_per_second"] = v
+	}
+	if v, ok := settings["num_failure_retries"].(float64); ok {
+		flattened["num_failure_retries"] = strconv.Itoa(int(v))
+	}
+	if len(flattened) == 0 {
+		return nil
+	}
+	return []map[string]interface{}{flattened}
+}
+
+func transformHeaders(d *schema.ResourceData) http.Header {
+	headers := http.Header{}
+	for k, v := range d.Get("headers").(map[string]interface{}) {
+		headers.Set(k, v.(string))
+	}
+	return headers
+}
+
+func elastic7PutTransform(client *elastic7.Client, id string, transform Transform, headers http.Header) error {
+	path, err := uritemplates.Expand("/_transform/{id}", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method:  "PUT",
+		Path:    path,
+		Body:    transform,
+		Headers: headers,
+	})
+	return err
+}
+
+// elastic7CreateTransformDestIndex creates the destination index with its
+// mappings, an existing index is left as it is
+func elastic7CreateTransformDestIndex(client *elastic7.Client, index string, mappings string) error {
+	exists, err := client.IndexExists(index).Do(context.TODO())
+	if err != nil {
+		return err
+	}
+	if exists {
+		log.Printf("[INFO] Destination index %s of the transform already exists, its mappings are not updated", index)
+		return nil
+	}
+
+	_, err = client.CreateIndex(index).BodyJson(map[string]interface{}{
+		"mappings": json.RawMessage(mappings),
+	}).Do(context.TODO())
+	if err != nil {
+		return fmt.Errorf("error creating the destination index %s of the transform: %+v", index, err)
+	}
+	return nil
+}
+
+func elastic7UpdateTransform(client *elastic7.Client, id string, transform Transform, headers http.Header) error {
+	path, err := uritemplates.Expand("/_transform/{id}/_update", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method:  "POST",
+		Path:    path,
+		Body:    transform,
+		Headers: headers,
+	})
+	return err
+}
+
+func elastic7GetTransform(client *elastic7.Client, id string) (Transform, error) {
+	path, err := uritemplates.Expand("/_transform/{id}", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return Transform{}, fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return Transform{}, err
+	}
+
+	var response TransformsResponse
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return Transform{}, fmt.Errorf("error unmarshalling transform body: %+v: %+v", err, res.Body)
+	}
+	if len(response.Transforms) != 1 {
+		return Transform{}, fmt.Errorf("1 transform expected with id %q, found %d", id, len(response.Transforms))
+	}
+
+	return response.Transforms[0], nil
+}
+
+func elastic7StartTransform(client *elastic7.Client, id string) error {
+	path, err := uritemplates.Expand("/_transform/{id}/_start", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+	})
+	return err
+}
+
+// elastic7StopTransform waits for the transform to stop, stopping a stopped
+// transform succeeds
+func elastic7StopTransform(client *elastic7.Client, id string) error {
+	path, err := uritemplates.Expand("/_transform/{id}/_stop", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "POST",
+		Path:   path,
+		Params: url.Values{"wait_for_completion": []string{"true"}},
+	})
+	return err
+}
+
+func elastic7DeleteTransform(client *elastic7.Client, id string) error {
+	path, err := uritemplates.Expand("/_transform/{id}", map[string]string{
+		"id": id,
+	})
+	if err != nil {
+		return fmt.Errorf("error building URL path for transform: %+v", err)
+	}
+
+	_, err = client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: "DELETE",
+		Path:   path,
+	})
+	return err
+}
+
+type TransformsResponse struct {
+	Count      int         `json:"count"`
+	Transforms []Transform `json:"transforms"`
+}
+
+type Transform struct {
+	ID          string           `json:"id,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Source      *TransformSource `json:"source,omitempty"`
+	Dest        *TransformDest   `json:"dest,omitempty"`
+	Pivot       interface{}      `json:"pivot,omitempty"`
+	Latest      interface{}      `json:"latest,omitempty"`
+	Frequency   string           `json:"frequency,omitempty"`
+	Sync        *TransformSync   `json:"sync,omitempty"`
+	// a *TransformRetentionPolicy, null on update to remove it, it is decoded
+	// as a map
+	RetentionPolicy interface{} `json:"retention_policy,omitempty"`
+	// the values are nil to reset the settings to their defaults
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+type TransformSource struct {
+	Index []string    `json:"index"`
+	Query interface{} `json:"query,omitempty"`
+}
+
+type TransformDest struct {
+	Index    string `json:"index"`
+	Pipeline string `json:"pipeline,omitempty"`
+}
+
+type TransformSync struct {
+	Time *TransformSyncTime `json:"time,omitempty"`
+}
+
+type TransformSyncTime struct {
+	Field string `json:"field"`
+	Delay string `json:"delay,omitempty"`
+}
+
+type TransformRetentionPolicy struct {
+	Time *TransformRetentionPolicyTime `json:"time,omitempty"`
+}
+
+type TransformRetentionPolicyTime struct {
+	Field  string `json:"field"`
+	MaxAge string `json:"max_age"`
+}
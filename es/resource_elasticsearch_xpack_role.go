@@ -6,18 +6,32 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net/url"
+	"sort"
+	"strings"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/olivere/elastic/uritemplates"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
 )
 
+var roleAllowRestrictedIndicesMinimalVersion, _ = version.NewVersion("6.7.0")
+var resolveIndexMinimalVersion, _ = version.NewVersion("7.9.0")
+
+// the characters which can't be part of index and data stream names
+var invalidIndexNameCharacters = ` "\\<>|,#/`
+
 func resourceElasticsearchXpackRole() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchXpackRoleCreate,
-		Read:   resourceElasticsearchXpackRoleRead,
-		Update: resourceElasticsearchXpackRoleUpdate,
-		Delete: resourceElasticsearchXpackRoleDelete,
+		CreateContext: resourceElasticsearchXpackRoleCreateContext,
+		Read:          resourceElasticsearchXpackRoleRead,
+		UpdateContext: resourceElasticsearchXpackRoleUpdateContext,
+		Delete:        resourceElasticsearchXpackRoleDelete,
 
 		Schema: map[string]*schema.Schema{
 			"role_name": {
@@ -30,10 +44,12 @@ func resourceElasticsearchXpackRole() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"names": {
-							Type:     schema.TypeSet,
-							Required: true,
+							Type:        schema.TypeSet,
+							Required:    true,
+							Description: "The names of the indices, aliases and data streams, wildcards (`logs-*`) and Lucene regular expressions between slashes (`/logs-.*/`) are supported.",
 							Elem: &schema.Schema{
-								Type: schema.TypeString,
+								Type:         schema.TypeString,
+								ValidateFunc: validateXpackRoleIndexName,
 							},
 						},
 						"privileges": {
@@ -48,6 +64,12 @@ func resourceElasticsearchXpackRole() *schema.Resource {
 							Optional:         true,
 							DiffSuppressFunc: suppressEquivalentJson,
 						},
+						"allow_restricted_indices": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: "Whether the names can match the restricted indices, e.g. `.security`, only available from ElasticSearch 6.7. Defaults to `false`.",
+						},
 						"field_security": {
 							Type:     schema.TypeList,
 							MaxItems: 1,
@@ -80,22 +102,30 @@ func resourceElasticsearchXpackRole() *schema.Resource {
 				Elem: &schema.Resource{
 					Schema: map[string]*schema.Schema{
 						"application": {
-							Type:     schema.TypeString,
-							Required: true,
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringIsNotWhiteSpace,
+							Description:  "The name of the application, e.g. `kibana-.kibana` for the Kibana feature privileges.",
 						},
 						"privileges": {
 							Type:     schema.TypeSet,
-							Optional: true,
+							Required: true,
+							MinItems: 1,
 							Elem: &schema.Schema{
-								Type: schema.TypeString,
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringIsNotWhiteSpace,
 							},
+							Description: "The names of the application privileges, e.g. `feature_discover.read`.",
 						},
 						"resources": {
 							Type:     schema.TypeSet,
-							Optional: true,
+							Required: true,
+							MinItems: 1,
 							Elem: &schema.Schema{
-								Type: schema.TypeString,
+								Type:         schema.TypeString,
+								ValidateFunc: validation.StringIsNotWhiteSpace,
 							},
+							Description: "The resources the privileges are granted on, wildcards are supported, e.g. `space:*` for all the Kibana spaces.",
 						},
 					},
 				},
@@ -125,6 +155,24 @@ func resourceElasticsearchXpackRole() *schema.Resource {
 				Default:          "{}",
 				DiffSuppressFunc: suppressEquivalentJson,
 			},
+			"force_destroy": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates that the role should be deleted even if it is still referenced by role mappings.",
+				Default:     false,
+				Optional:    true,
+			},
+			"validate_indices": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates that the `names` of the `indices` are resolved, before applying the role, against the existing indices, aliases and data streams (ElasticSearch >= 7.9). A warning is shown for the names which match none of them, e.g. typos.",
+				Default:     false,
+				Optional:    true,
+			},
+			"clear_cache": {
+				Type:        schema.TypeBool,
+				Description: "A boolean that indicates to evict the role from the native roles cache of the cluster after it is created or updated, so the changes take effect immediately.",
+				Default:     false,
+				Optional:    true,
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -132,6 +180,151 @@ func resourceElasticsearchXpackRole() *schema.Resource {
 	}
 }
 
+// the names of the indices are checked before applying the role, warnings can
+// only be returned from the context functions
+func resourceElasticsearchXpackRoleCreateContext(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceElasticsearchXpackRoleIndicesWarnings(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	if err := resourceElasticsearchXpackRoleCreate(d, m); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+func resourceElasticsearchXpackRoleUpdateContext(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if d.HasChanges("indices", "validate_indices") {
+		diags = resourceElasticsearchXpackRoleIndicesWarnings(ctx, d, m)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	if err := resourceElasticsearchXpackRoleUpdate(d, m); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+	return diags
+}
+
+// resourceElasticsearchXpackRoleIndicesWarnings warns about the names of the
+// indices which match no index, alias or data stream, they may be created
+// later so this is not an error
+func resourceElasticsearchXpackRoleIndicesWarnings(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if !d.Get("validate_indices").(bool) {
+		return nil
+	}
+
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	client, ok := esClient.(*elastic7.Client)
+	if !ok {
+		return diag.Errorf("validate_indices only available from ElasticSearch >= 7.9, got version < 7.0.0")
+	}
+	elasticVersion, err := esVersionFromConf(m.(*ProviderConf))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	if elasticVersion.LessThan(resolveIndexMinimalVersion) {
+		return diag.Errorf("validate_indices only available from ElasticSearch >= 7.9, got version %s", elasticVersion.String())
+	}
+
+	var diags diag.Diagnostics
+	for _, name := range xpackRoleIndicesNames(d.Get("indices").(*schema.Set)) {
+		// the regular expressions can't be resolved
+		if strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/") {
+			continue
+		}
+		found, err := elastic7ResolveIndexFound(ctx, client, name)
+		if err != nil {
+			return append(diags, diag.FromErr(err)...)
+		}
+		if !found {
+			diags = append(diags, diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       fmt.Sprintf("Index name %q matches no index", name),
+				Detail:        fmt.Sprintf("The name %q matches no index, alias or data stream of the cluster, the role grants no privilege on it until one is created.", name),
+				AttributePath: cty.GetAttrPath("indices"),
+			})
+		}
+	}
+	return diags
+}
+
+// xpackRoleIndicesNames returns the sorted names of all the indices blocks
+func xpackRoleIndicesNames(indices *schema.Set) []string {
+	unique := make(map[string]bool)
+	for _, v := range indices.List() {
+		for _, name := range v.(map[string]interface{})["names"].(*schema.Set).List() {
+			unique[name.(string)] = true
+		}
+	}
+
+	names := make([]string, 0, len(unique))
+	for name := range unique {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// elastic7ResolveIndexFound returns whether the name, or the wildcard
+// expression, matches an index, an alias or a data stream
+func elastic7ResolveIndexFound(ctx context.Context, client *elastic7.Client, name string) (bool, error) {
+	path, err := uritemplates.Expand("/_resolve/index/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error building URL path for resolve index: %+v", err)
+	}
+
+	res, err := client.PerformRequest(ctx, elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+		Params: url.Values{"expand_wildcards": []string{"all"}},
+	})
+	if err != nil {
+		// a name without wildcards which matches nothing
+		if elastic7.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	var response struct {
+		Indices     []json.RawMessage `json:"indices"`
+		Aliases     []json.RawMessage `json:"aliases"`
+		DataStreams []json.RawMessage `json:"data_streams"`
+	}
+	if err := json.Unmarshal(res.Body, &response); err != nil {
+		return false, fmt.Errorf("error unmarshalling resolve index body: %+v: %+v", err, res.Body)
+	}
+	return len(response.Indices)+len(response.Aliases)+len(response.DataStreams) > 0, nil
+}
+
+// validateXpackRoleIndexName warns about the names which can't match any
+// index, Elasticsearch accepts them but the role grants nothing on them
+func validateXpackRoleIndexName(v interface{}, k string) (ws []string, errors []error) {
+	name := v.(string)
+	if strings.TrimSpace(name) == "" {
+		errors = append(errors, fmt.Errorf("%q must not be empty", k))
+		return
+	}
+	// any character can be part of a regular expression
+	if len(name) > 1 && strings.HasPrefix(name, "/") && strings.HasSuffix(name, "/") {
+		return
+	}
+
+	if strings.ContainsAny(name, invalidIndexNameCharacters) {
+		ws = append(ws, fmt.Sprintf("%q: the index name %q contains characters which can't be part of index names, e.g. a space or a comma, it matches no index", k, name))
+	} else if strings.ToLower(name) != name {
+		ws = append(ws, fmt.Sprintf("%q: the index name %q contains uppercase characters, index and data stream names are lowercase so it matches no index", k, name))
+	}
+	return
+}
+
 func resourceElasticsearchXpackRoleCreate(d *schema.ResourceData, m interface{}) error {
 	name := d.Get("role_name").(string)
 
@@ -143,6 +336,12 @@ func resourceElasticsearchXpackRoleCreate(d *schema.ResourceData, m interface{})
 	if err != nil {
 		return err
 	}
+	if d.Get("clear_cache").(bool) {
+		err = xpackClearRoleCache(m, name)
+		if err != nil {
+			return err
+		}
+	}
 	d.SetId(name)
 	return resourceElasticsearchXpackRoleRead(d, m)
 }
@@ -173,10 +372,11 @@ func resourceElasticsearchXpackRoleRead(d *schema.ResourceData, m interface{}) e
 		indices := make([]map[string]interface{}, 0, len(role.Indices))
 		for _, v := range role.Indices {
 			ip := map[string]interface{}{
-				"names":          v.Names,
-				"privileges":     v.Privileges,
-				"field_security": v.FieldSecurity,
-				"query":          v.Query,
+				"names":                    v.Names,
+				"privileges":               v.Privileges,
+				"field_security":           v.FieldSecurity,
+				"query":                    v.Query,
+				"allow_restricted_indices": v.AllowRestrictedIndices,
 			}
 			indices = append(indices, ip)
 		}
@@ -215,12 +415,30 @@ func resourceElasticsearchXpackRoleUpdate(d *schema.ResourceData, m interface{})
 	if err != nil {
 		return err
 	}
+	if d.Get("clear_cache").(bool) {
+		err = xpackClearRoleCache(m, name)
+		if err != nil {
+			return err
+		}
+	}
 	return resourceElasticsearchXpackRoleRead(d, m)
 }
 
 func resourceElasticsearchXpackRoleDelete(d *schema.ResourceData, m interface{}) error {
 
-	err := xpackDeleteRole(d, m, d.Id())
+	// check to see if role mappings still reference the role
+	mappings, err := xpackGetRoleMappingsReferencingRole(m, d.Id())
+	if err != nil {
+		return err
+	}
+	if len(mappings) > 0 {
+		if !d.Get("force_destroy").(bool) {
+			return fmt.Errorf("Role %s is still referenced by role mappings %v, set force_destroy to true to allow destroying.", d.Id(), mappings)
+		}
+		log.Printf("[WARN] Role %s is still referenced by role mappings %v, destroying anyway\n", d.Id(), mappings)
+	}
+
+	err = xpackDeleteRole(d, m, d.Id())
 	if err != nil {
 		log.Print("Error during destroy")
 		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
@@ -259,11 +477,17 @@ func buildPutRoleBody(d *schema.ResourceData, m interface{}) (string, error) {
 
 	var indicesBody []PutRoleIndicesPermissions
 	for _, indice := range indicesPrivileges {
+		if indice.AllowRestrictedIndices {
+			if err := checkRoleAllowRestrictedIndices(m); err != nil {
+				return "", err
+			}
+		}
 		putIndex := PutRoleIndicesPermissions{
-			Names:         indice.Names,
-			Privileges:    indice.Privileges,
-			FieldSecurity: indice.FieldSecurity,
-			Query:         optionalInterfaceJson(indice.Query.(string)),
+			Names:                  indice.Names,
+			Privileges:             indice.Privileges,
+			FieldSecurity:          indice.FieldSecurity,
+			Query:                  optionalInterfaceJson(indice.Query.(string)),
+			AllowRestrictedIndices: indice.AllowRestrictedIndices,
 		}
 		indicesBody = append(indicesBody, putIndex)
 	}
@@ -289,6 +513,49 @@ func buildPutRoleBody(d *schema.ResourceData, m interface{}) (string, error) {
 	return string(body[:]), err
 }
 
+func checkRoleAllowRestrictedIndices(m interface{}) error {
+	esVersion, err := esVersionFromConf(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if esVersion.LessThan(roleAllowRestrictedIndicesMinimalVersion) {
+		return fmt.Errorf("allow_restricted_indices is only available from ElasticSearch >= 6.7, got version %s", esVersion.String())
+	}
+	return nil
+}
+
+// xpackRoleAllowRestrictedIndices returns the allow_restricted_indices flag of
+// each indices permission of the role, the clients don't decode it
+func xpackRoleAllowRestrictedIndices(body json.RawMessage, name string) ([]bool, error) {
+	var roles map[string]struct {
+		Indices []struct {
+			AllowRestrictedIndices bool `json:"allow_restricted_indices"`
+		} `json:"indices"`
+	}
+	if err := json.Unmarshal(body, &roles); err != nil {
+		return nil, fmt.Errorf("error unmarshalling role body: %+v: %+v", err, body)
+	}
+
+	allowRestrictedIndices := make([]bool, 0, len(roles[name].Indices))
+	for _, indices := range roles[name].Indices {
+		allowRestrictedIndices = append(allowRestrictedIndices, indices.AllowRestrictedIndices)
+	}
+	return allowRestrictedIndices, nil
+}
+
+// setRoleAllowRestrictedIndices sets the allow_restricted_indices flags on the
+// flattened indices permissions, in the order of the response
+func setRoleAllowRestrictedIndices(indices []XPackSecurityIndicesPermissions, allowRestrictedIndices []bool) []XPackSecurityIndicesPermissions {
+	if len(allowRestrictedIndices) != len(indices) {
+		log.Printf("[WARN] Got %d allow_restricted_indices flags for %d indices permissions, the flags are ignored", len(allowRestrictedIndices), len(indices))
+		return indices
+	}
+	for i := range indices {
+		indices[i].AllowRestrictedIndices = allowRestrictedIndices[i]
+	}
+	return indices
+}
+
 func xpackPutRole(d *schema.ResourceData, m interface{}, name string, body string) error {
 	esClient, err := getClient(m.(*ProviderConf))
 	if err != nil {
@@ -334,6 +601,111 @@ func xpackDeleteRole(d *schema.ResourceData, m interface{}, name string) error {
 	}
 }
 
+func xpackClearRoleCache(m interface{}, name string) error {
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var path string
+		path, err = uritemplates.Expand("/_security/role/{name}/_clear_cache", map[string]string{
+			"name": name,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = client.PerformRequest(context.Background(), elastic7.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+		})
+	case *elastic6.Client:
+		var path string
+		path, err = uritemplates.Expand("/_xpack/security/role/{name}/_clear_cache", map[string]string{
+			"name": name,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = client.PerformRequest(context.Background(), elastic6.PerformRequestOptions{
+			Method: "POST",
+			Path:   path,
+		})
+	default:
+		err = errors.New("unhandled client type")
+	}
+
+	return err
+}
+
+// xpackGetRoleMappingsReferencingRole returns the names of the role mappings
+// granting the given role
+func xpackGetRoleMappingsReferencingRole(m interface{}, name string) ([]string, error) {
+	var (
+		body json.RawMessage
+		err  error
+	)
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.Background(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_security/role_mapping",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.Background(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_xpack/security/role_mapping",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = errors.New("unhandled client type")
+	}
+	if err != nil {
+		// no role mappings at all
+		if elasticErr, ok := err.(*elastic7.Error); ok && elastic7.IsNotFound(elasticErr) {
+			return nil, nil
+		}
+		if elasticErr, ok := err.(*elastic6.Error); ok && elastic6.IsNotFound(elasticErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return roleMappingsReferencingRole(body, name)
+}
+
+func roleMappingsReferencingRole(body json.RawMessage, name string) ([]string, error) {
+	var roleMappings map[string]struct {
+		Roles []string `json:"roles"`
+	}
+	if err := json.Unmarshal(body, &roleMappings); err != nil {
+		return nil, fmt.Errorf("error unmarshalling role mappings body: %+v: %+v", err, body)
+	}
+
+	mappings := make([]string, 0)
+	for mappingName, roleMapping := range roleMappings {
+		for _, role := range roleMapping.Roles {
+			if role == name {
+				mappings = append(mappings, mappingName)
+				break
+			}
+		}
+	}
+	sort.Strings(mappings)
+	return mappings, nil
+}
+
 func elastic6PutRole(client *elastic6.Client, name string, body string) error {
 	_, err := client.XPackSecurityPutRole(name).Body(body).Do(context.Background())
 	log.Printf("[INFO] put error: %+v", err)
@@ -347,11 +719,28 @@ func elastic7PutRole(client *elastic7.Client, name string, body string) error {
 }
 
 func elastic6GetRole(client *elastic6.Client, name string) (XPackSecurityRole, error) {
-	res, err := client.XPackSecurityGetRole(name).Do(context.Background())
+	path, err := uritemplates.Expand("/_xpack/security/role/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return XPackSecurityRole{}, err
+	}
+	res, err := client.PerformRequest(context.Background(), elastic6.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return XPackSecurityRole{}, err
+	}
+	var roles elastic6.XPackSecurityGetRoleResponse
+	if err := json.Unmarshal(res.Body, &roles); err != nil {
+		return XPackSecurityRole{}, err
+	}
+	allowRestrictedIndices, err := xpackRoleAllowRestrictedIndices(res.Body, name)
 	if err != nil {
 		return XPackSecurityRole{}, err
 	}
-	obj := (*res)[name]
+	obj := roles[name]
 	role := XPackSecurityRole{}
 	role.Name = name
 	role.Cluster = obj.Cluster
@@ -359,7 +748,7 @@ func elastic6GetRole(client *elastic6.Client, name string) (XPackSecurityRole, e
 	// if we have field security settings, we have to flatten them for tf
 	if len(obj.Indices) > 0 {
 		if data, err := flattenIndicesPermissionSetv6(obj.Indices); err == nil {
-			role.Indices = data
+			role.Indices = setRoleAllowRestrictedIndices(data, allowRestrictedIndices)
 		} else {
 			log.Printf("[INFO] Data: %+v", data)
 			return role, err
@@ -391,11 +780,28 @@ func elastic6GetRole(client *elastic6.Client, name string) (XPackSecurityRole, e
 }
 
 func elastic7GetRole(client *elastic7.Client, name string) (XPackSecurityRole, error) {
-	res, err := client.XPackSecurityGetRole(name).Do(context.Background())
+	path, err := uritemplates.Expand("/_security/role/{name}", map[string]string{
+		"name": name,
+	})
+	if err != nil {
+		return XPackSecurityRole{}, err
+	}
+	res, err := client.PerformRequest(context.Background(), elastic7.PerformRequestOptions{
+		Method: "GET",
+		Path:   path,
+	})
+	if err != nil {
+		return XPackSecurityRole{}, err
+	}
+	var roles elastic7.XPackSecurityGetRoleResponse
+	if err := json.Unmarshal(res.Body, &roles); err != nil {
+		return XPackSecurityRole{}, err
+	}
+	allowRestrictedIndices, err := xpackRoleAllowRestrictedIndices(res.Body, name)
 	if err != nil {
 		return XPackSecurityRole{}, err
 	}
-	obj := (*res)[name]
+	obj := roles[name]
 	role := XPackSecurityRole{}
 	role.Name = name
 	role.Cluster = obj.Cluster
@@ -403,7 +809,7 @@ func elastic7GetRole(client *elastic7.Client, name string) (XPackSecurityRole, e
 	// if we have field security settings, we have to flatten them for tf
 	if len(obj.Indices) > 0 {
 		if data, err := flattenIndicesPermissionSetv7(obj.Indices); err == nil {
-			role.Indices = data
+			role.Indices = setRoleAllowRestrictedIndices(data, allowRestrictedIndices)
 		} else {
 			log.Printf("Data: %v\n", data)
 
@@ -461,10 +867,11 @@ type PutRoleApplicationPrivileges struct {
 }
 
 type PutRoleIndicesPermissions struct {
-	Names         []string            `json:"names"`
-	Privileges    []string            `json:"privileges"`
-	FieldSecurity map[string][]string `json:"field_security,omitempty"`
-	Query         interface{}         `json:"query,omitempty"`
+	Names                  []string            `json:"names"`
+	Privileges             []string            `json:"privileges"`
+	FieldSecurity          map[string][]string `json:"field_security,omitempty"`
+	Query                  interface{}         `json:"query,omitempty"`
+	AllowRestrictedIndices bool                `json:"allow_restricted_indices,omitempty"`
 }
 
 type XPackSecurityRole struct {
@@ -486,8 +893,9 @@ type XPackSecurityApplicationPrivileges struct {
 
 // XPackSecurityIndicesPermissions is the indices permission object of Elasticsearch
 type XPackSecurityIndicesPermissions struct {
-	Names         []string                 `json:"names"`
-	Privileges    []string                 `json:"privileges"`
-	FieldSecurity []map[string]interface{} `json:"field_security"`
-	Query         string                   `json:"query"`
+	Names                  []string                 `json:"names"`
+	Privileges             []string                 `json:"privileges"`
+	FieldSecurity          []map[string]interface{} `json:"field_security"`
+	Query                  string                   `json:"query"`
+	AllowRestrictedIndices bool                     `json:"allow_restricted_indices"`
 }
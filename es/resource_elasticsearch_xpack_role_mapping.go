@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
@@ -51,7 +52,7 @@ func resourceElasticsearchXpackRoleMapping() *schema.Resource {
 				Optional:         true,
 				Default:          "{}",
 				DiffSuppressFunc: suppressEquivalentJson,
-				Description:      "Additional metadata that helps define which roles are assigned to each user. Keys beginning with `_` are reserved for system usage.",
+				Description:      "Additional metadata that helps define which roles are assigned to each user. Keys beginning with `_` are reserved for system usage, they are ignored on read unless they are configured.",
 			},
 		},
 		Importer: &schema.ResourceImporter{
@@ -93,15 +94,50 @@ func resourceElasticsearchXpackRoleMappingRead(d *schema.ResourceData, m interfa
 		return err
 	}
 
+	metadata, err := roleMappingMetadataWithoutReservedKeys(roleMapping.Metadata, d.Get("metadata").(string))
+	if err != nil {
+		return err
+	}
+
 	ds := &resourceDataSetter{d: d}
 	ds.set("role_mapping_name", roleMapping.Name)
 	ds.set("roles", roleMapping.Roles)
 	ds.set("enabled", roleMapping.Enabled)
 	ds.set("rules", roleMapping.Rules)
-	ds.set("metadata", roleMapping.Metadata)
+	ds.set("metadata", metadata)
 	return ds.err
 }
 
+// roleMappingMetadataWithoutReservedKeys removes the keys reserved for system
+// usage, starting with `_`, which are not in the configured metadata
+func roleMappingMetadataWithoutReservedKeys(metadata string, configured string) (string, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(metadata), &values); err != nil {
+		return "", fmt.Errorf("fail to unmarshal metadata: %v", err)
+	}
+	if values == nil {
+		return "{}", nil
+	}
+
+	var configuredValues map[string]interface{}
+	if configured != "" {
+		// the configuration may not be valid JSON on import
+		_ = json.Unmarshal([]byte(configured), &configuredValues)
+	}
+
+	for key := range values {
+		if _, ok := configuredValues[key]; !ok && strings.HasPrefix(key, "_") {
+			delete(values, key)
+		}
+	}
+
+	result, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
 func resourceElasticsearchXpackRoleMappingUpdate(d *schema.ResourceData, m interface{}) error {
 	name := d.Get("role_mapping_name").(string)
 
@@ -74,6 +74,65 @@ func TestAccElasticsearchXpackRoleMapping(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchXpackRoleMapping_disabledOutOfBand(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckRoleMappingDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleMappingResourceMetadata(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role_mapping.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role_mapping.test", "metadata", `{"team":"search"}`),
+				),
+			},
+			{
+				// the mapping disabled outside of Terraform is planned to be enabled again
+				PreConfig: func() {
+					body := `{"roles":["admin"],"enabled":false,"rules":{"field":{"username":"esadmin"}},"metadata":{"team":"search"}}`
+					if err := xpackPutRoleMapping(nil, testAccXPackProvider.Meta(), randomName, body); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config:             testAccRoleMappingResourceMetadata(randomName),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				// and enabled in place
+				Config: testAccRoleMappingResourceMetadata(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role_mapping.test", "id", randomName),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role_mapping.test", "enabled", "true"),
+				),
+			},
+		},
+	})
+}
+
+func TestRoleMappingMetadataWithoutReservedKeys(t *testing.T) {
+	metadata, err := roleMappingMetadataWithoutReservedKeys(`{"_reserved":true,"_managed":"x","team":"search"}`, `{"_managed":"x","team":"search"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata != `{"_managed":"x","team":"search"}` {
+		t.Errorf("unexpected metadata %s", metadata)
+	}
+
+	metadata, err = roleMappingMetadataWithoutReservedKeys(`null`, `{}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata != `{}` {
+		t.Errorf("unexpected metadata %s", metadata)
+	}
+}
+
 func testAccCheckRoleMappingDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_xpack_role_mapping" {
@@ -205,6 +264,21 @@ resource "elasticsearch_xpack_role_mapping" "test" {
 `, resourceName)
 }
 
+func testAccRoleMappingResourceMetadata(resourceName string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_xpack_role_mapping" "test" {
+  role_mapping_name = "%s"
+  roles             = ["admin"]
+  rules = jsonencode({
+    field = { username = "esadmin" }
+  })
+  metadata = jsonencode({
+    team = "search"
+  })
+}
+`, resourceName)
+}
+
 func TestAccRoleMappingResource_importBasic(t *testing.T) {
 	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
 
@@ -2,7 +2,10 @@ package es
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -10,6 +13,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
 )
 
@@ -144,14 +148,14 @@ func testAccRoleResource(resourceName string) string {
 	resource "elasticsearch_xpack_role" "test" {
 		role_name = "%s"
 		indices {
-			names 	   = ["testIndice"]
+			names 	   = ["test-indice"]
 			privileges = ["read"]
                         field_security {
                                        grant = ["testField", "testField2"]
                         }
 		}
 		indices {
-			names 	   = ["testIndice2"]
+			names 	   = ["test-indice-2"]
 			privileges = ["write"]
                         field_security {
                                        grant = ["*"]
@@ -180,11 +184,11 @@ func testAccRoleResource_Updated(resourceName string) string {
 	resource "elasticsearch_xpack_role" "test" {
 		role_name = "%s"
 		indices {
-			names 	 = ["testIndice"]
+			names 	 = ["test-indice"]
 			privileges = ["read"]
 		}
 		indices {
-			names 	 = ["testIndice2"]
+			names 	 = ["test-indice-2"]
 			privileges = ["write"]
 		}
 		cluster = [
@@ -215,11 +219,11 @@ func testAccRoleResource_Global(resourceName string) string {
 	resource "elasticsearch_xpack_role" "test" {
 		role_name = "%s"
 		indices {
-			names 	 = ["testIndice"]
+			names 	 = ["test-indice"]
 			privileges = ["read"]
 		}
 		indices {
-			names 	 = ["testIndice2"]
+			names 	 = ["test-indice-2"]
 			privileges = ["write"]
 		}
 		cluster = [
@@ -274,7 +278,350 @@ func TestAccRoleResource_importBasic(t *testing.T) {
 				ResourceName:      "elasticsearch_xpack_role.test",
 				ImportState:       true,
 				ImportStateVerify: true,
+				ImportStateVerifyIgnore: []string{
+					"force_destroy", // not returned from the API
+					"clear_cache",   // not returned from the API
+				},
 			},
 		},
 	})
 }
+
+func TestAccElasticsearchXpackRole_kibanaApplication(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleResourceKibanaApplication(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role.test", "applications.#", "1"),
+					resource.TestCheckTypeSetElemNestedAttrs("elasticsearch_xpack_role.test", "applications.*", map[string]string{
+						"application":  "kibana-.kibana",
+						"privileges.#": "2",
+						"resources.#":  "1",
+					}),
+					resource.TestCheckTypeSetElemAttr("elasticsearch_xpack_role.test", "applications.*.resources.*", "space:default"),
+					resource.TestCheckTypeSetElemAttr("elasticsearch_xpack_role.test", "applications.*.privileges.*", "feature_discover.read"),
+				),
+			},
+			{
+				// the order of the privileges doesn't change the plan
+				Config:   testAccRoleResourceKibanaApplicationReordered(randomName),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestBuildPutRoleBodyApplications(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceElasticsearchXpackRole().Schema, map[string]interface{}{
+		"role_name": "test",
+		"applications": []interface{}{
+			map[string]interface{}{
+				"application": "kibana-.kibana",
+				"privileges":  []interface{}{"feature_discover.read"},
+				"resources":   []interface{}{"space:default"},
+			},
+		},
+	})
+
+	body, err := buildPutRoleBody(d, &ProviderConf{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var role PutRoleBody
+	if err := json.Unmarshal([]byte(body), &role); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	expected := []PutRoleApplicationPrivileges{{
+		Application: "kibana-.kibana",
+		Privileges:  []string{"feature_discover.read"},
+		Resources:   []string{"space:default"},
+	}}
+	if !reflect.DeepEqual(role.Applications, expected) {
+		t.Errorf("expected the applications %+v, got %+v", expected, role.Applications)
+	}
+}
+
+func TestAccElasticsearchXpackRole_clearCache(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleResourceClearCache(randomName, "monitor"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role.test", "clear_cache", "true"),
+				),
+			},
+			{
+				Config: testAccRoleResourceClearCache(randomName, "all"),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role.test", "cluster.0", "all"),
+				),
+			},
+		},
+	})
+}
+
+func testAccRoleResourceClearCache(resourceName string, privilege string) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_xpack_role" "test" {
+		role_name   = "%s"
+		cluster     = ["%s"]
+		clear_cache = true
+	}
+	`, resourceName, privilege)
+}
+
+func TestAccElasticsearchXpackRole_referencedByRoleMapping(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleResourceWithRoleMapping(randomName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+				),
+			},
+			{
+				Config:      testAccRoleResourceRoleMappingOnly(randomName),
+				ExpectError: regexp.MustCompile("is still referenced by role mappings"),
+			},
+			{
+				Config: testAccRoleResourceWithRoleMapping(randomName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role.test", "force_destroy", "true"),
+				),
+			},
+			{
+				Config: testAccRoleResourceRoleMappingOnly(randomName),
+			},
+		},
+	})
+}
+
+func testAccRoleResourceWithRoleMapping(resourceName string, forceDestroy bool) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_xpack_role" "test" {
+		role_name     = "%s"
+		cluster       = ["monitor"]
+		force_destroy = %t
+	}
+	%s
+	`, resourceName, forceDestroy, testAccRoleResourceRoleMappingOnly(resourceName))
+}
+
+func testAccRoleResourceRoleMappingOnly(resourceName string) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_xpack_role_mapping" "test" {
+		role_mapping_name = "%s"
+		roles             = ["%s"]
+		rules             = <<-EOF
+		{
+			"field": {
+				"username": "*"
+			}
+		}
+		EOF
+	}
+	`, resourceName, resourceName)
+}
+
+func TestAccElasticsearchXpackRole_allowRestrictedIndices(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(roleAllowRestrictedIndicesMinimalVersion) {
+				t.Skip("allow_restricted_indices only supported on ES >= 6.7")
+			}
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleResourceAllowRestrictedIndices(randomName, true),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckTypeSetElemNestedAttrs("elasticsearch_xpack_role.test", "indices.*", map[string]string{
+						"allow_restricted_indices": "true",
+					}),
+				),
+			},
+			{
+				Config: testAccRoleResourceAllowRestrictedIndices(randomName, false),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckTypeSetElemNestedAttrs("elasticsearch_xpack_role.test", "indices.*", map[string]string{
+						"allow_restricted_indices": "false",
+					}),
+				),
+			},
+		},
+	})
+}
+
+func testAccRoleResourceAllowRestrictedIndices(resourceName string, allowRestrictedIndices bool) string {
+	return fmt.Sprintf(`
+	resource "elasticsearch_xpack_role" "test" {
+		role_name = "%s"
+		indices {
+			names                    = [".security*"]
+			privileges               = ["read"]
+			allow_restricted_indices = %t
+		}
+	}
+	`, resourceName, allowRestrictedIndices)
+}
+
+func TestAccElasticsearchXpackRole_validateIndices(t *testing.T) {
+	provider := Provider()
+	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
+	if diags.HasError() {
+		t.Skipf("err: %#v", diags)
+	}
+	meta := provider.Meta()
+	esVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		t.Skipf("err: %s", err)
+	}
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+			if esVersion.LessThan(resolveIndexMinimalVersion) {
+				t.Skip("validate_indices only supported on ES >= 7.9")
+			}
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckRoleDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccRoleResourceValidateIndices(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckRoleExists("elasticsearch_xpack_role.test"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_role.test", "validate_indices", "true"),
+					resource.TestCheckTypeSetElemNestedAttrs("elasticsearch_xpack_role.test", "indices.*", map[string]string{
+						"names.#": "2",
+					}),
+					resource.TestCheckTypeSetElemAttr("elasticsearch_xpack_role.test", "indices.*.names.*", "terraform-test-stream-*"),
+				),
+			},
+		},
+	})
+}
+
+func TestSetRoleAllowRestrictedIndices(t *testing.T) {
+	indices := []XPackSecurityIndicesPermissions{{Names: []string{".security"}}, {Names: []string{"logs-*"}}}
+	indices = setRoleAllowRestrictedIndices(indices, []bool{true, false})
+	if !indices[0].AllowRestrictedIndices || indices[1].AllowRestrictedIndices {
+		t.Errorf("expected the flags in the order of the response, got %+v", indices)
+	}
+
+	// the flags which don't match the permissions are ignored
+	indices = setRoleAllowRestrictedIndices([]XPackSecurityIndicesPermissions{{Names: []string{"logs-*"}}}, []bool{})
+	if len(indices) != 1 || indices[0].AllowRestrictedIndices {
+		t.Errorf("expected the permissions to be left as they are, got %+v", indices)
+	}
+}
+
+func TestValidateXpackRoleIndexName(t *testing.T) {
+	tests := []struct {
+		name     string
+		warnings int
+		errors   int
+	}{
+		{"logs-*", 0, 0},
+		{"terraform-test-stream-app", 0, 0},
+		{"/Logs-.*|Metrics-.*/", 0, 0},
+		{"Logs-*", 1, 0},
+		{"logs,metrics", 1, 0},
+		{"logs *", 1, 0},
+		{"", 0, 1},
+		{"  ", 0, 1},
+	}
+
+	for _, test := range tests {
+		ws, errs := validateXpackRoleIndexName(test.name, "names")
+		if len(ws) != test.warnings || len(errs) != test.errors {
+			t.Errorf("name %q: expected %d warnings and %d errors, got %v and %v", test.name, test.warnings, test.errors, ws, errs)
+		}
+	}
+}
+
+func testAccRoleResourceValidateIndices(resourceName string) string {
+	return testAccElasticsearchDataStream + fmt.Sprintf(`
+	resource "elasticsearch_xpack_role" "test" {
+		role_name        = "%s"
+		validate_indices = true
+		indices {
+			names      = ["terraform-test-stream-*", "terraform-test-missing"]
+			privileges = ["read"]
+		}
+
+		depends_on = [elasticsearch_data_stream.test]
+	}
+	`, resourceName)
+}
+
+func testAccRoleResourceKibanaApplication(resourceName string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_xpack_role" "test" {
+  role_name = "%s"
+
+  applications {
+    application = "kibana-.kibana"
+    privileges  = ["feature_discover.read", "feature_dashboard.read"]
+    resources   = ["space:default"]
+  }
+}
+`, resourceName)
+}
+
+func testAccRoleResourceKibanaApplicationReordered(resourceName string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_xpack_role" "test" {
+  role_name = "%s"
+
+  applications {
+    application = "kibana-.kibana"
+    privileges  = ["feature_dashboard.read", "feature_discover.read"]
+    resources   = ["space:default"]
+  }
+}
+`, resourceName)
+}
@@ -4,13 +4,20 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	elastic7 "github.com/olivere/elastic/v7"
 )
 
+var snapshotFeatureStatesMinimalVersion, _ = version.NewVersion("7.12.0")
+
 func resourceElasticsearchXpackSnapshotLifecyclePolicy() *schema.Resource {
 	return &schema.Resource{
 		Description: "Provides an Elasticsearch XPack snapshot lifecycle management policy. These automatically take snapshots and control how long they are retained. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/snapshot-lifecycle-management-api.html) for more details.",
@@ -29,8 +36,44 @@ func resourceElasticsearchXpackSnapshotLifecyclePolicy() *schema.Resource {
 				Type:             schema.TypeString,
 				Required:         true,
 				DiffSuppressFunc: diffSuppressSnapshotLifecyclePolicy,
-				ValidateFunc:     validation.StringIsJSON,
-				Description:      "See the policy definition defined in the [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/slm-api-put-policy.html#slm-api-put-request-body)",
+				ValidateFunc:     validation.All(validation.StringIsJSON, validateSnapshotLifecyclePolicySchedule),
+				Description:      "See the policy definition defined in the [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/slm-api-put-policy.html#slm-api-put-request-body). The `schedule` is validated as a cron expression when planning. The `config.metadata` attached to the snapshots is compared as JSON and the order of the `config.feature_states` (ElasticSearch >= 7.12) doesn't matter.",
+			},
+			"execute_on_create": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Whether to take a snapshot right after the creation of the policy, instead of waiting for its schedule.",
+			},
+			"next_execution": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The date of the next scheduled snapshot.",
+			},
+			"stats": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The statistics of the snapshots taken by the policy.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"snapshots_taken": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"snapshots_failed": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"snapshots_deleted": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"snapshot_deletion_failures": {
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
 			},
 		},
 		Importer: &schema.ResourceImporter{
@@ -39,19 +82,143 @@ func resourceElasticsearchXpackSnapshotLifecyclePolicy() *schema.Resource {
 	}
 }
 
+var (
+	cronMonths     = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+	cronDaysOfWeek = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+	// the special values of the day-of-month and day-of-week fields, e.g. the
+	// last day of the month or the third friday of the month
+	cronDayOfMonthSpecialRegexp = regexp.MustCompile(`^(L|LW|L-[0-9]{1,2}|[0-9]{1,2}W)$`)
+	cronDayOfWeekSpecialRegexp  = regexp.MustCompile(`^(?i)([1-7]|SUN|MON|TUE|WED|THU|FRI|SAT)(L|#[1-5])$`)
+)
+
+// validateSnapshotLifecyclePolicySchedule validates the schedule of the policy
+// body, a Quartz style cron expression with 6 or 7 fields, e.g. `0 30 1 * * ?`
+func validateSnapshotLifecyclePolicySchedule(v interface{}, k string) (ws []string, errors []error) {
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(v.(string)), &body); err != nil {
+		// reported by the JSON validation
+		return
+	}
+	schedule, ok := body["schedule"].(string)
+	if !ok {
+		return
+	}
+
+	if err := validateCronExpression(schedule); err != nil {
+		errors = append(errors, fmt.Errorf("%q contains an invalid schedule %q: %s", k, schedule, err))
+	}
+	return
+}
+
+func validateCronExpression(expression string) error {
+	fields := strings.Fields(expression)
+	if len(fields) != 6 && len(fields) != 7 {
+		return fmt.Errorf("expected 6 or 7 fields (seconds minutes hours day-of-month month day-of-week [year]), got %d", len(fields))
+	}
+
+	dayOfMonth, dayOfWeek := fields[3], fields[5]
+	if (dayOfMonth == "?") == (dayOfWeek == "?") {
+		return fmt.Errorf("exactly one of day-of-month and day-of-week must be `?`")
+	}
+
+	checks := []struct {
+		name     string
+		min, max int
+		names    []string
+		special  *regexp.Regexp
+	}{
+		{"seconds", 0, 59, nil, nil},
+		{"minutes", 0, 59, nil, nil},
+		{"hours", 0, 23, nil, nil},
+		{"day-of-month", 1, 31, nil, cronDayOfMonthSpecialRegexp},
+		{"month", 1, 12, cronMonths, nil},
+		{"day-of-week", 1, 7, cronDaysOfWeek, cronDayOfWeekSpecialRegexp},
+		{"year", 1970, 2199, nil, nil},
+	}
+	for i, field := range fields {
+		check := checks[i]
+		if field == "?" && check.special != nil {
+			continue
+		}
+		if check.special != nil && check.special.MatchString(field) {
+			continue
+		}
+		for _, part := range strings.Split(field, ",") {
+			if err := validateCronFieldPart(part, check.min, check.max, check.names); err != nil {
+				return fmt.Errorf("invalid %s field %q: %s", check.name, field, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateCronFieldPart validates a single value, a range or an increment of
+// a cron field, e.g. `5`, `MON-FRI` or `*/15`
+func validateCronFieldPart(part string, min, max int, names []string) error {
+	base := part
+	if i := strings.Index(part, "/"); i >= 0 {
+		base = part[:i]
+		step, err := strconv.Atoi(part[i+1:])
+		if err != nil || step <= 0 {
+			return fmt.Errorf("increment must be a positive number, got %q", part[i+1:])
+		}
+	}
+	if base == "*" {
+		return nil
+	}
+
+	bounds := strings.Split(base, "-")
+	if len(bounds) > 2 {
+		return fmt.Errorf("invalid range %q", base)
+	}
+	for _, bound := range bounds {
+		if err := validateCronValue(bound, min, max, names); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateCronValue(value string, min, max int, names []string) error {
+	for _, name := range names {
+		if strings.EqualFold(value, name) {
+			return nil
+		}
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", value)
+	}
+	if n < min || n > max {
+		return fmt.Errorf("%d is out of the range %d-%d", n, min, max)
+	}
+	return nil
+}
+
 func resourceElasticsearchXpackSnapshotLifecyclePolicyCreate(d *schema.ResourceData, meta interface{}) error {
 	err := resourceElasticsearchPutSnapshotLifecyclePolicy(d, meta)
 	if err != nil {
 		return err
 	}
 	d.SetId(d.Get("name").(string))
-	return nil
+
+	if d.Get("execute_on_create").(bool) {
+		err = resourceElasticsearchExecuteSnapshotLifecyclePolicy(d.Id(), meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return resourceElasticsearchXpackSnapshotLifecyclePolicyRead(d, meta)
 }
 
 func resourceElasticsearchXpackSnapshotLifecyclePolicyRead(d *schema.ResourceData, meta interface{}) error {
 	id := d.Id()
 
 	var result string
+	var info SnapshotLifecyclePolicyInfo
 	var err error
 	esClient, err := getClient(meta.(*ProviderConf))
 	if err != nil {
@@ -59,7 +226,7 @@ func resourceElasticsearchXpackSnapshotLifecyclePolicyRead(d *schema.ResourceDat
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
-		result, err = elastic7SnapshotGetLifecyclePolicy(client, id)
+		result, info, err = elastic7SnapshotGetLifecyclePolicy(client, id)
 	default:
 		err = errors.New("Snapshot Lifecycle Management is only supported by the elastic library >= v7!")
 	}
@@ -70,16 +237,24 @@ func resourceElasticsearchXpackSnapshotLifecyclePolicyRead(d *schema.ResourceDat
 	ds := &resourceDataSetter{d: d}
 	ds.set("name", d.Id())
 	ds.set("body", result)
+	ds.set("next_execution", info.NextExecution)
+	ds.set("stats", []interface{}{map[string]interface{}{
+		"snapshots_taken":            info.Stats.SnapshotsTaken,
+		"snapshots_failed":           info.Stats.SnapshotsFailed,
+		"snapshots_deleted":          info.Stats.SnapshotsDeleted,
+		"snapshot_deletion_failures": info.Stats.SnapshotDeletionFailures,
+	}})
 	return ds.err
 }
 
-func elastic7SnapshotGetLifecyclePolicy(client *elastic7.Client, id string) (string, error) {
+func elastic7SnapshotGetLifecyclePolicy(client *elastic7.Client, id string) (string, SnapshotLifecyclePolicyInfo, error) {
+	var info SnapshotLifecyclePolicyInfo
 	res, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
 		Method: http.MethodGet,
 		Path:   "/_slm/policy/" + id,
 	})
 	if err != nil {
-		return "", err
+		return "", info, err
 	}
 
 	// GET /_slm/policy/{id} returns a more unique object than other similar
@@ -90,27 +265,31 @@ func elastic7SnapshotGetLifecyclePolicy(client *elastic7.Client, id string) (str
 
 	// so we need to do our part to reduce this to just the policy object
 	// which is the equivalent of our "body" elsewhere
-	var resp map[string]interface{}
+	var resp map[string]json.RawMessage
 	if err := json.Unmarshal(res.Body, &resp); err != nil {
-		return "", err
+		return "", info, err
 	}
 
 	policy, ok := resp[id]
 	if !ok {
-		return "", errors.New("Snapshot Lifecycle Management unsuccessfully parsed")
+		return "", info, errors.New("Snapshot Lifecycle Management unsuccessfully parsed")
 	}
 
-	typedPolicy, ok := policy.(map[string]interface{})
-	if !ok {
-		return "", errors.New("Snapshot Lifecycle Management unsuccessfully parsed")
+	var typedPolicy map[string]interface{}
+	if err := json.Unmarshal(policy, &typedPolicy); err != nil {
+		return "", info, errors.New("Snapshot Lifecycle Management unsuccessfully parsed")
+	}
+	// the next execution and the stats are returned along with the policy
+	if err := json.Unmarshal(policy, &info); err != nil {
+		return "", info, err
 	}
 
 	tj, err := json.Marshal(typedPolicy["policy"])
 	if err != nil {
-		return "", err
+		return "", info, err
 	}
 
-	return string(tj), nil
+	return string(tj), info, nil
 }
 
 func resourceElasticsearchXpackSnapshotLifecyclePolicyUpdate(d *schema.ResourceData, meta interface{}) error {
@@ -158,6 +337,9 @@ func resourceElasticsearchPutSnapshotLifecyclePolicy(d *schema.ResourceData, met
 	}
 	switch client := esClient.(type) {
 	case *elastic7.Client:
+		if err = checkSnapshotLifecyclePolicyFeatureStates(body, meta); err != nil {
+			return err
+		}
 		err = elastic7SnapshotPutLifecyclePolicy(client, name, body)
 	default:
 		err = errors.New("resourceElasticsearchPutSnapshotLifecyclePolicy Snapshot Lifecycle Management is only supported by the elastic library >= v7!")
@@ -166,6 +348,52 @@ func resourceElasticsearchPutSnapshotLifecyclePolicy(d *schema.ResourceData, met
 	return err
 }
 
+func resourceElasticsearchExecuteSnapshotLifecyclePolicy(name string, meta interface{}) error {
+	var err error
+	esClient, err := getClient(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		err = elastic7SnapshotExecuteLifecyclePolicy(client, name)
+	default:
+		err = errors.New("Snapshot Lifecycle Management is only supported by the elastic library >= v7!")
+	}
+
+	return err
+}
+
+func elastic7SnapshotExecuteLifecyclePolicy(client *elastic7.Client, name string) error {
+	_, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
+		Method: http.MethodPost,
+		Path:   "/_slm/policy/" + name + "/_execute",
+	})
+	return err
+}
+
+// checkSnapshotLifecyclePolicyFeatureStates fails early when the policy body
+// sets feature states, which the older versions reject as an unknown field
+func checkSnapshotLifecyclePolicyFeatureStates(body string, meta interface{}) error {
+	var policy struct {
+		Config struct {
+			FeatureStates []interface{} `json:"feature_states"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(body), &policy); err != nil || policy.Config.FeatureStates == nil {
+		return nil
+	}
+
+	elasticVersion, err := esVersionFromConf(meta.(*ProviderConf))
+	if err != nil {
+		return err
+	}
+	if elasticVersion.LessThan(snapshotFeatureStatesMinimalVersion) {
+		return fmt.Errorf("snapshot lifecycle policy config.feature_states only available from ElasticSearch >= 7.12, got version %s", elasticVersion.String())
+	}
+	return nil
+}
+
 func elastic7SnapshotPutLifecyclePolicy(client *elastic7.Client, name string, body string) error {
 	_, err := client.PerformRequest(context.TODO(), elastic7.PerformRequestOptions{
 		Method: http.MethodPut,
@@ -174,3 +402,15 @@ func elastic7SnapshotPutLifecyclePolicy(client *elastic7.Client, name string, bo
 	})
 	return err
 }
+
+type SnapshotLifecyclePolicyInfo struct {
+	NextExecution string                       `json:"next_execution"`
+	Stats         SnapshotLifecyclePolicyStats `json:"stats"`
+}
+
+type SnapshotLifecyclePolicyStats struct {
+	SnapshotsTaken           int `json:"snapshots_taken"`
+	SnapshotsFailed          int `json:"snapshots_failed"`
+	SnapshotsDeleted         int `json:"snapshots_deleted"`
+	SnapshotDeletionFailures int `json:"snapshot_deletion_failures"`
+}
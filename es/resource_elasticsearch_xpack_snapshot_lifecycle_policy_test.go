@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -50,8 +52,15 @@ func TestAccElasticsearchXpackSnapshotLifecyclePolicy(t *testing.T) {
 				Config: testAccElasticsearchXpackSnapshotLifecyclePolicy,
 				Check: resource.ComposeTestCheckFunc(
 					testCheckElasticsearchXpackSnapshotLifecyclePolicyExists("elasticsearch_xpack_snapshot_lifecycle_policy.terraform-test"),
+					resource.TestCheckResourceAttrSet("elasticsearch_xpack_snapshot_lifecycle_policy.terraform-test", "next_execution"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_snapshot_lifecycle_policy.terraform-test", "stats.#", "1"),
 				),
 			},
+			{
+				// the same policy with the indices in another order
+				Config:   testAccElasticsearchXpackSnapshotLifecyclePolicyReordered,
+				PlanOnly: true,
+			},
 		},
 	})
 }
@@ -92,14 +101,121 @@ func TestAccElasticsearchXpackSnapshotLifecyclePolicy_importBasic(t *testing.T)
 				Config: testAccElasticsearchXpackSnapshotLifecyclePolicy,
 			},
 			{
-				ResourceName:      "elasticsearch_xpack_snapshot_lifecycle_policy.terraform-test",
-				ImportState:       true,
-				ImportStateVerify: true,
+				ResourceName:            "elasticsearch_xpack_snapshot_lifecycle_policy.terraform-test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"execute_on_create", "next_execution", "stats"},
 			},
 		},
 	})
 }
 
+func TestDiffSuppressSnapshotLifecyclePolicy(t *testing.T) {
+	old := `{"schedule":"0 30 1 * * ?","config":{"indices":["data-*","important"],"include_global_state":false}}`
+	for _, body := range []string{
+		`{"schedule":"0 30 1 * * ?","config":{"indices":["important","data-*"],"include_global_state":false}}`,
+		`{"schedule":"0 30 1 * * ?","config":{"indices":"important,data-*","include_global_state":false,"ignore_unavailable":false}}`,
+	} {
+		if !diffSuppressSnapshotLifecyclePolicy("body", old, body, nil) {
+			t.Errorf("expected %s to be equivalent to %s", body, old)
+		}
+	}
+
+	for _, body := range []string{
+		`{"schedule":"0 30 1 * * ?","config":{"indices":["data-*"],"include_global_state":false}}`,
+		`{"schedule":"0 30 1 * * ?","config":{"indices":["data-*","important"]}}`,
+	} {
+		if diffSuppressSnapshotLifecyclePolicy("body", old, body, nil) {
+			t.Errorf("expected %s to differ from %s", body, old)
+		}
+	}
+
+	// the metadata is compared as JSON and the feature states as a set
+	old = `{"config":{"feature_states":["security","kibana"],"metadata":{"owner":"terraform","team":"ops"}}}`
+	if !diffSuppressSnapshotLifecyclePolicy("body", old, `{"config":{"metadata":{"team":"ops","owner":"terraform"},"feature_states":["kibana","security"]}}`, nil) {
+		t.Error("expected the metadata and the feature states to be equivalent")
+	}
+	if diffSuppressSnapshotLifecyclePolicy("body", old, `{"config":{"feature_states":["security"],"metadata":{"owner":"terraform","team":"ops"}}}`, nil) {
+		t.Error("expected the feature states to differ")
+	}
+
+	// the exclusions depend on the order of the patterns
+	if diffSuppressSnapshotLifecyclePolicy("body", `{"config":{"indices":["*","-data-*"]}}`, `{"config":{"indices":["-data-*","*"]}}`, nil) {
+		t.Error("expected the order of the indices with exclusions to matter")
+	}
+}
+
+func TestValidateSnapshotLifecyclePolicySchedule(t *testing.T) {
+	for _, schedule := range []string{
+		"0 30 1 * * ?",
+		"0 0/15 * * * ?",
+		"0 0 2 ? * MON-FRI",
+		"0 0 2 ? * 6#3",
+		"0 0 2 L * ?",
+		"0 0 2 15W JAN,JUL ? 2030",
+		"*/30 * * * * ?",
+	} {
+		body := fmt.Sprintf(`{"schedule": %q}`, schedule)
+		if _, errs := validateSnapshotLifecyclePolicySchedule(body, "body"); len(errs) > 0 {
+			t.Errorf("expected %q to be valid, got %+v", schedule, errs)
+		}
+	}
+
+	for _, schedule := range []string{
+		"0 30 1 * *",
+		"0 30 1 * * *",
+		"0 30 1 ? * ?",
+		"0 60 1 * * ?",
+		"0 30 24 * * ?",
+		"0 30 1 32 * ?",
+		"0 30 1 ? FOO *",
+		"0 0/0 * * * ?",
+		"0 30 1 * * ? 1900",
+	} {
+		body := fmt.Sprintf(`{"schedule": %q}`, schedule)
+		if _, errs := validateSnapshotLifecyclePolicySchedule(body, "body"); len(errs) == 0 {
+			t.Errorf("expected %q to be invalid", schedule)
+		}
+	}
+}
+
+func TestElastic7SnapshotGetLifecyclePolicy(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != "GET" || r.URL.Path != "/_slm/policy/nightly" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"nightly": {"version": 1, "policy": {"schedule": "0 30 1 * * ?", "repository": "backups"}, "next_execution": "2021-06-02T01:30:00.000Z", "next_execution_millis": 1622597400000, "stats": {"policy": "nightly", "snapshots_taken": 3, "snapshots_failed": 1, "snapshots_deleted": 2, "snapshot_deletion_failures": 0}}}`)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esClient, err := getClient(&ProviderConf{rawUrl: ts.URL, parsedUrl: parsedUrl, esVersion: "7.10.2"})
+	if err != nil {
+		t.Fatalf("getClient returned an error: %+v", err)
+	}
+
+	body, info, err := elastic7SnapshotGetLifecyclePolicy(esClient.(*elastic7.Client), "nightly")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := `{"repository":"backups","schedule":"0 30 1 * * ?"}`; body != expected {
+		t.Errorf("expected body %s, got %s", expected, body)
+	}
+	expected := SnapshotLifecyclePolicyInfo{
+		NextExecution: "2021-06-02T01:30:00.000Z",
+		Stats:         SnapshotLifecyclePolicyStats{SnapshotsTaken: 3, SnapshotsFailed: 1, SnapshotsDeleted: 2},
+	}
+	if info != expected {
+		t.Errorf("expected %+v, got %+v", expected, info)
+	}
+}
+
 func testCheckElasticsearchXpackSnapshotLifecyclePolicyExists(name string) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		rs, ok := s.RootModule().Resources[name]
@@ -184,3 +300,25 @@ resource "elasticsearch_xpack_snapshot_lifecycle_policy" "terraform-test" {
 EOF
 }
 `
+
+var testAccElasticsearchXpackSnapshotLifecyclePolicyReordered = `
+resource "elasticsearch_xpack_snapshot_lifecycle_policy" "terraform-test" {
+  name = "terraformtest"
+  body = <<EOF
+{
+  "schedule": "0 30 1 * * ?",
+  "name": "<daily-snap-{now/d}>",
+  "repository": "terraform-test",
+  "config": {
+    "indices": ["important", "data-*"],
+    "include_global_state": false
+  },
+  "retention": {
+    "expire_after": "30d",
+    "min_count": 5,
+    "max_count": 50
+  }
+}
+EOF
+}
+`
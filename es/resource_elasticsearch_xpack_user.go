@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	elastic7 "github.com/olivere/elastic/v7"
 	elastic6 "gopkg.in/olivere/elastic.v6"
@@ -16,7 +18,7 @@ func resourceElasticsearchXpackUser() *schema.Resource {
 	return &schema.Resource{
 		Description: "Provides an Elasticsearch XPack user resource. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/security-api.html) for more details.",
 		Create:      resourceElasticsearchXpackUserCreate,
-		Read:        resourceElasticsearchXpackUserRead,
+		ReadContext: resourceElasticsearchXpackUserReadContext,
 		Update:      resourceElasticsearchXpackUserUpdate,
 		Delete:      resourceElasticsearchXpackUserDelete,
 
@@ -77,6 +79,12 @@ func resourceElasticsearchXpackUser() *schema.Resource {
 				DiffSuppressFunc: suppressEquivalentJson,
 				Description:      "Arbitrary metadata that you want to associate with the user",
 			},
+			"validate_roles": {
+				Type:        schema.TypeBool,
+				Default:     false,
+				Optional:    true,
+				Description: "A boolean that indicates that the roles of the user should be checked against the roles returned by the role API when reading the user, a warning is shown for the roles which don't exist, e.g. deleted out of band. The roles defined in the `roles.yml` files are not returned by the API and are reported as well. Defaults to `false`.",
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -99,6 +107,34 @@ func resourceElasticsearchXpackUserCreate(d *schema.ResourceData, m interface{})
 	return resourceElasticsearchXpackUserRead(d, m)
 }
 
+// the roles are checked after reading the user, warnings can only be
+// returned from the context functions
+func resourceElasticsearchXpackUserReadContext(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if err := resourceElasticsearchXpackUserRead(d, m); err != nil {
+		return diag.FromErr(err)
+	}
+	if d.Id() == "" || !d.Get("validate_roles").(bool) {
+		return nil
+	}
+
+	roles := expandStringList(d.Get("roles").(*schema.Set).List())
+	missing, err := xpackMissingRoles(m, roles)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	var diags diag.Diagnostics
+	for _, role := range missing {
+		diags = append(diags, diag.Diagnostic{
+			Severity:      diag.Warning,
+			Summary:       fmt.Sprintf("Role %q of the user %s doesn't exist", role, d.Id()),
+			Detail:        fmt.Sprintf("The role %q is not returned by the role API, it grants no privilege to the user while it doesn't exist. It may have been deleted out of band, or be defined in a roles.yml file, which the API doesn't return.", role),
+			AttributePath: cty.GetAttrPath("roles"),
+		})
+	}
+	return diags
+}
+
 func resourceElasticsearchXpackUserRead(d *schema.ResourceData, m interface{}) error {
 
 	user, err := xpackGetUser(d, m, d.Id())
@@ -241,6 +277,58 @@ func xpackDeleteUser(d *schema.ResourceData, m interface{}, name string) error {
 	}
 }
 
+// xpackMissingRoles returns the roles which are not returned by the role API
+// among the given ones, Elasticsearch keeps the references to the roles
+// deleted out of band
+func xpackMissingRoles(m interface{}, roles []string) ([]string, error) {
+	var (
+		body json.RawMessage
+		err  error
+	)
+	esClient, err := getClient(m.(*ProviderConf))
+	if err != nil {
+		return nil, err
+	}
+	switch client := esClient.(type) {
+	case *elastic7.Client:
+		var res *elastic7.Response
+		res, err = client.PerformRequest(context.Background(), elastic7.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_security/role",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	case *elastic6.Client:
+		var res *elastic6.Response
+		res, err = client.PerformRequest(context.Background(), elastic6.PerformRequestOptions{
+			Method: "GET",
+			Path:   "/_xpack/security/role",
+		})
+		if err == nil {
+			body = res.Body
+		}
+	default:
+		err = errors.New("unhandled client type")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var existing map[string]json.RawMessage
+	if err := json.Unmarshal(body, &existing); err != nil {
+		return nil, fmt.Errorf("error unmarshalling roles body: %+v: %+v", err, body)
+	}
+
+	var missing []string
+	for _, role := range roles {
+		if _, ok := existing[role]; !ok {
+			missing = append(missing, role)
+		}
+	}
+	return missing, nil
+}
+
 func elastic6PutUser(client *elastic6.Client, name string, body string) error {
 	_, err := client.XPackSecurityPutUser(name).Body(body).Do(context.Background())
 	log.Printf("[INFO] put error: %+v", err)
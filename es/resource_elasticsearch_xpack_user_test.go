@@ -3,6 +3,10 @@ package es
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"testing"
 
 	elastic7 "github.com/olivere/elastic/v7"
@@ -72,6 +76,40 @@ func TestAccElasticsearchXpackUser(t *testing.T) {
 	})
 }
 
+func TestAccElasticsearchXpackUser_validateRoles(t *testing.T) {
+	randomName := "test" + acctest.RandStringFromCharSet(10, acctest.CharSetAlpha)
+
+	resource.Test(t, resource.TestCase{
+		PreCheck: func() {
+			testAccPreCheck(t)
+		},
+		Providers:    testAccXPackProviders,
+		CheckDestroy: testAccCheckUserDestroy,
+		Steps: []resource.TestStep{
+			{
+				Config: testAccUserResource_ValidateRoles(randomName),
+				Check: resource.ComposeTestCheckFunc(
+					testCheckUserExists("elasticsearch_xpack_user.test"),
+					resource.TestCheckResourceAttr("elasticsearch_xpack_user.test", "roles.#", "2"),
+				),
+			},
+			{
+				// the role deleted out of band is recreated, the user only
+				// warns about it
+				PreConfig: func() {
+					err := xpackDeleteRole(nil, testAccXPackProvider.Meta(), randomName)
+					if err != nil {
+						t.Fatalf("error deleting role %s: %s", randomName, err)
+					}
+				},
+				Config:             testAccUserResource_ValidateRoles(randomName),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
 func testAccCheckUserDestroy(s *terraform.State) error {
 	for _, rs := range s.RootModule().Resources {
 		if rs.Type != "elasticsearch_xpack_user" {
@@ -236,6 +274,22 @@ resource "elasticsearch_xpack_user" "test" {
 `, resourceName)
 }
 
+func testAccUserResource_ValidateRoles(resourceName string) string {
+	return fmt.Sprintf(`
+resource "elasticsearch_xpack_role" "test" {
+	role_name = "%s"
+	cluster   = ["monitor"]
+}
+
+resource "elasticsearch_xpack_user" "test" {
+	username       = "%s"
+	password       = "secret"
+	roles          = ["superuser", elasticsearch_xpack_role.test.role_name]
+	validate_roles = true
+}
+`, resourceName, resourceName)
+}
+
 func TestAccUserResource_importBasic(t *testing.T) {
 	provider := Provider()
 	diags := provider.Configure(context.Background(), &terraform.ResourceConfig{})
@@ -274,8 +328,35 @@ func TestAccUserResource_importBasic(t *testing.T) {
 				ResourceName:            "elasticsearch_xpack_user.test",
 				ImportState:             true,
 				ImportStateVerify:       true,
-				ImportStateVerifyIgnore: []string{"password"}, // because ES doesn't return this field
+				ImportStateVerifyIgnore: []string{"password", "validate_roles"}, // because ES doesn't return these fields
 			},
 		},
 	})
 }
+
+func TestXpackMissingRoles(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method != "GET" || r.URL.Path != "/_security/role" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"superuser": {"cluster": ["all"]}, "monitoring": {"cluster": ["monitor"]}}`)
+	}))
+	defer ts.Close()
+
+	parsedUrl, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	meta := &ProviderConf{rawUrl: ts.URL, parsedUrl: parsedUrl, esVersion: "7.10.2"}
+
+	missing, err := xpackMissingRoles(meta, []string{"superuser", "deleted", "monitoring"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(missing, []string{"deleted"}) {
+		t.Errorf("expected the deleted role to be missing, got %v", missing)
+	}
+}
@@ -17,9 +17,10 @@ import (
 
 var xPackWatchSchema = map[string]*schema.Schema{
 	"watch_id": {
-		Type:     schema.TypeString,
-		Required: true,
-		ForceNew: true,
+		Type:        schema.TypeString,
+		Required:    true,
+		ForceNew:    true,
+		Description: "The ID of the watch.",
 	},
 	"body": {
 		Type:             schema.TypeString,
@@ -30,6 +31,7 @@ var xPackWatchSchema = map[string]*schema.Schema{
 			json, _ := structure.NormalizeJsonString(v)
 			return json
 		},
+		Description: "A JSON string of the watch definition: `trigger`, `input`, `condition`, `actions`, and optionally `transform` and `metadata`.",
 	},
 	"active": {
 		Type:        schema.TypeBool,
@@ -42,11 +44,12 @@ var xPackWatchSchema = map[string]*schema.Schema{
 
 func resourceElasticsearchXpackWatch() *schema.Resource {
 	return &schema.Resource{
-		Create: resourceElasticsearchWatchCreate,
-		Read:   resourceElasticsearchWatchRead,
-		Update: resourceElasticsearchWatchUpdate,
-		Delete: resourceElasticsearchWatchDelete,
-		Schema: xPackWatchSchema,
+		Description: "Provides an Elasticsearch Watcher watch resource, using the `/_xpack/watcher` API of ElasticSearch 6 and the `/_watcher` API from ElasticSearch 7. See the upstream [docs](https://www.elastic.co/guide/en/elasticsearch/reference/current/watcher-api-put-watch.html) for more details.",
+		Create:      resourceElasticsearchWatchCreate,
+		Read:        resourceElasticsearchWatchRead,
+		Update:      resourceElasticsearchWatchUpdate,
+		Delete:      resourceElasticsearchWatchDelete,
+		Schema:      xPackWatchSchema,
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
@@ -10,7 +10,6 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
-	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -143,6 +142,14 @@ For legacy index templates (ES < 7.8) or /_template endpoint on ES >= 7.8 see no
 */
 func normalizeComposableIndexTemplate(tpl map[string]interface{}) {
 	delete(tpl, "version")
+	// the data stream options are returned with their defaults, e.g. `hidden`
+	if dataStream, ok := tpl["data_stream"].(map[string]interface{}); ok {
+		for _, key := range []string{"hidden", "allow_custom_routing"} {
+			if value, ok := dataStream[key].(bool); ok && !value {
+				delete(dataStream, key)
+			}
+		}
+	}
 	if innerTpl, ok := tpl["template"]; ok {
 		if innerTplMap, ok := innerTpl.(map[string]interface{}); ok {
 			if settings, ok := innerTplMap["settings"]; ok {
@@ -202,6 +209,58 @@ func normalizeSnapshotLifecyclePolicy(pol map[string]interface{}) {
 			pol["policy"] = normalizedIndexLifecyclePolicy(policyMap)
 		}
 	}
+	if config, ok := pol["config"].(map[string]interface{}); ok {
+		normalizeSnapshotLifecyclePolicyConfig(config)
+	}
+}
+
+// normalizeSnapshotLifecyclePolicyConfig sorts the indices of the snapshot
+// config, which can be a comma separated string or a list returned in another
+// order, sorts the feature states and drops the options set to their defaults
+func normalizeSnapshotLifecyclePolicyConfig(config map[string]interface{}) {
+	var indices []string
+	switch v := config["indices"].(type) {
+	case string:
+		for _, index := range strings.Split(v, ",") {
+			indices = append(indices, strings.TrimSpace(index))
+		}
+	case []interface{}:
+		for _, index := range v {
+			indices = append(indices, fmt.Sprintf("%v", index))
+		}
+	}
+	if indices != nil {
+		// the exclusions only apply to the previous patterns
+		reorderable := true
+		for _, index := range indices {
+			if strings.HasPrefix(index, "-") {
+				reorderable = false
+			}
+		}
+		if reorderable {
+			sort.Strings(indices)
+		}
+		config["indices"] = indices
+	}
+
+	if v, ok := config["feature_states"].([]interface{}); ok {
+		featureStates := make([]string, 0, len(v))
+		for _, featureState := range v {
+			featureStates = append(featureStates, fmt.Sprintf("%v", featureState))
+		}
+		sort.Strings(featureStates)
+		config["feature_states"] = featureStates
+	}
+
+	for option, defaultValue := range map[string]bool{
+		"ignore_unavailable":   false,
+		"include_global_state": true,
+		"partial":              false,
+	} {
+		if value, ok := config[option].(bool); ok && value == defaultValue {
+			delete(config, option)
+		}
+	}
 }
 
 func normalizedIndexLifecyclePolicy(policy map[string]interface{}) map[string]interface{} {
@@ -213,6 +272,15 @@ func normalizedIndexLifecyclePolicy(policy map[string]interface{}) map[string]in
 	return f
 }
 
+func normalizedIndexSimilarity(similarity map[string]interface{}) map[string]interface{} {
+	f := flattenMap(similarity)
+	for k, v := range f {
+		f[k] = fmt.Sprintf("%v", v)
+	}
+
+	return f
+}
+
 func flattenMap(m map[string]interface{}) map[string]interface{} {
 	f := make(map[string]interface{})
 	for k, v := range m {
@@ -229,6 +297,27 @@ func flattenMap(m map[string]interface{}) map[string]interface{} {
 	return f
 }
 
+// unflattenMap is the inverse of flattenMap, it nests the values of the dotted
+// keys
+func unflattenMap(f map[string]interface{}) map[string]interface{} {
+	m := make(map[string]interface{})
+	for k, v := range f {
+		parts := strings.Split(k, ".")
+		current := m
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
+			}
+			current = next
+		}
+		current[parts[len(parts)-1]] = v
+	}
+
+	return m
+}
+
 func flattenIndicesFieldSecurity(rawSettings map[string]interface{}) []map[string]interface{} {
 	out := make([]map[string]interface{}, 0, 1)
 	out = append(out, rawSettings)
@@ -312,6 +401,18 @@ func expandStringList(resourcesArray []interface{}) []string {
 	return vs
 }
 
+// Takes the result of flatmap.Expand for an array of ints
+// and returns a []int
+func expandIntList(resourcesArray []interface{}) []int {
+	vs := make([]int, 0, len(resourcesArray))
+	for _, v := range resourcesArray {
+		if val, ok := v.(int); ok {
+			vs = append(vs, val)
+		}
+	}
+	return vs
+}
+
 func flattenStringList(list []string) []interface{} {
 	vs := make([]interface{}, 0, len(list))
 	for _, v := range list {
@@ -368,6 +469,9 @@ func expandIndicesPermissionSet(resourcesArray []interface{}) ([]PutRoleIndicesP
 				FieldSecurity: expandIndicesFieldSecurity(data["field_security"].([]interface{})),
 				Query:         data["query"].(string),
 			}
+			if allowRestrictedIndices, ok := data["allow_restricted_indices"].(bool); ok {
+				obj.AllowRestrictedIndices = allowRestrictedIndices
+			}
 			vperm = append(vperm, obj)
 		}
 	}
@@ -643,13 +747,17 @@ func readPathOrContent(poc string) (string, bool, error) {
 	return poc, false, nil
 }
 
-func elastic7GetVersion(client *elastic7.Client) (*version.Version, error) {
-	urls := reflect.ValueOf(client).Elem().FieldByName("urls")
-	versionString, err := client.ElasticsearchVersion(urls.Index(0).String())
-	if err != nil {
-		return nil, err
+// esVersionFromConf returns the version of the cluster as declared in the
+// provider configuration or as determined when building the client, without
+// issuing an extra request to the cluster.
+func esVersionFromConf(conf *ProviderConf) (*version.Version, error) {
+	if conf.esVersion == "" {
+		if _, err := getClient(conf); err != nil {
+			return nil, err
+		}
 	}
-	return version.NewVersion(versionString)
+
+	return version.NewVersion(conf.esVersion)
 }
 
 func toCamelCase(underScored string, startUpperCased bool) (camelCased string) {
@@ -0,0 +1,7 @@
+data "elasticsearch_index_stats" "logs" {
+  index = "logs-*"
+}
+
+output "logs_indexing_ops_per_busy_second" {
+  value = { for index in data.elasticsearch_index_stats.logs.indices : index.name => index.indexing_ops_per_busy_second }
+}
@@ -0,0 +1,3 @@
+data "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+}
@@ -0,0 +1,7 @@
+data "elasticsearch_kibana_alert" "test" {
+  name = "terraform-alert"
+}
+
+data "elasticsearch_kibana_alert_status" "test" {
+  alert_id = data.elasticsearch_kibana_alert.test.id
+}
@@ -0,0 +1,6 @@
+data "elasticsearch_kibana_connector_types" "all" {
+}
+
+locals {
+  enabled_connector_types = [for t in data.elasticsearch_kibana_connector_types.all.connector_types : t.id if t.enabled]
+}
@@ -0,0 +1,4 @@
+data "elasticsearch_kibana_export" "dashboards" {
+  types                   = ["dashboard"]
+  include_references_deep = true
+}
@@ -0,0 +1,9 @@
+data "elasticsearch_snapshot_repository_analyze" "backups" {
+  repository    = "backups"
+  blob_count    = 2000
+  max_blob_size = "2gb"
+
+  timeouts {
+    read = "10m"
+  }
+}
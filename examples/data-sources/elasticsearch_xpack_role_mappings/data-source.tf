@@ -0,0 +1,11 @@
+data "elasticsearch_xpack_role_mappings" "admins" {
+  role             = "superuser"
+  exclude_reserved = true
+}
+
+# import the existing mappings
+import {
+  for_each = toset(data.elasticsearch_xpack_role_mappings.admins.names)
+  to       = elasticsearch_xpack_role_mapping.admins[each.key]
+  id       = each.key
+}
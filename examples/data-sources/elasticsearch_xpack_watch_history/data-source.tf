@@ -0,0 +1,9 @@
+data "elasticsearch_xpack_watch_history" "disk_usage" {
+  watch_id = "disk_usage"
+  size     = 20
+  from     = "now-1d"
+}
+
+output "failed_executions" {
+  value = [for e in data.elasticsearch_xpack_watch_history.disk_usage.executions : e.execution_time if e.state == "failed"]
+}
@@ -0,0 +1,20 @@
+# A key which can only read the logs indices, e.g. for a dashboard application
+resource "elasticsearch_api_key" "logs_reader" {
+  name       = "logs-reader"
+  expiration = "90d"
+
+  role_descriptors = jsonencode({
+    "logs-reader" = {
+      cluster = []
+      indices = [{
+        names      = ["logs-*"]
+        privileges = ["read", "view_index_metadata"]
+      }]
+    }
+  })
+}
+
+output "logs_reader_api_key" {
+  value     = elasticsearch_api_key.logs_reader.encoded
+  sensitive = true
+}
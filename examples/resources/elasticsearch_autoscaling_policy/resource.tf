@@ -0,0 +1,9 @@
+resource "elasticsearch_autoscaling_policy" "hot" {
+  name  = "hot"
+  roles = ["data_hot", "data_content"]
+  deciders = jsonencode({
+    reactive_storage = {
+      forecast_window = "30m"
+    }
+  })
+}
@@ -0,0 +1,26 @@
+# Disable replica allocation during a rolling restart, re-enable it with
+# cluster_routing_allocation_enable = "all" once all the nodes are back
+resource "elasticsearch_cluster_settings" "global" {
+  cluster_routing_allocation_enable = "primaries"
+  wait_for_green                    = true
+  safe_destroy                      = true
+}
+
+# Spread the replicas over two zones, without allocating them all to the
+# remaining zone when the other one is lost
+resource "elasticsearch_cluster_settings" "awareness" {
+  cluster_routing_allocation_awareness_attributes = "zone"
+
+  cluster_routing_allocation_awareness_force {
+    attribute = "zone"
+    values    = ["zone-a", "zone-b"]
+  }
+}
+
+# Settings without a dedicated argument, only the declared keys are managed
+resource "elasticsearch_cluster_settings" "limits" {
+  persistent = jsonencode({
+    "cluster.max_shards_per_node" = 2000
+    "action.auto_create_index"    = "false"
+  })
+}
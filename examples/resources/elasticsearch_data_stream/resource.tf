@@ -0,0 +1,16 @@
+resource "elasticsearch_composable_index_template" "logs" {
+  name = "logs"
+  body = <<EOF
+{
+  "index_patterns": ["logs-*"],
+  "data_stream": {},
+  "priority": 200
+}
+EOF
+}
+
+resource "elasticsearch_data_stream" "app" {
+  name = "logs-app"
+
+  depends_on = [elasticsearch_composable_index_template.logs]
+}
@@ -0,0 +1,8 @@
+resource "elasticsearch_enrich_policy" "users" {
+  name          = "users"
+  policy_type   = "match"
+  indices       = ["users"]
+  match_field   = "email"
+  enrich_fields = ["first_name", "last_name", "city"]
+  force_execute = true
+}
@@ -0,0 +1,25 @@
+resource "elasticsearch_index" "logs" {
+  name               = "logs-000001"
+  number_of_shards   = 1
+  number_of_replicas = 1
+}
+
+# Write to the current index through the alias
+resource "elasticsearch_index_alias" "logs" {
+  index          = elasticsearch_index.logs.name
+  alias          = "logs"
+  is_write_index = true
+}
+
+# Only the errors of all the logs indices
+resource "elasticsearch_index_alias" "errors" {
+  index = "logs-*"
+  alias = "logs-errors"
+  filter = jsonencode({
+    term = {
+      level = "error"
+    }
+  })
+
+  depends_on = [elasticsearch_index.logs]
+}
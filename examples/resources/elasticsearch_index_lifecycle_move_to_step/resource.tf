@@ -0,0 +1,21 @@
+# Retry the rollover of an index stuck in the hot phase, e.g. after fixing
+# its rollover alias
+resource "elasticsearch_index_lifecycle_move_to_step" "logs" {
+  index = "logs-000012"
+
+  current_step {
+    phase  = "hot"
+    action = "rollover"
+    name   = "ERROR"
+  }
+
+  next_step {
+    phase  = "hot"
+    action = "rollover"
+    name   = "check-rollover-ready"
+  }
+
+  triggers = {
+    retry = "1"
+  }
+}
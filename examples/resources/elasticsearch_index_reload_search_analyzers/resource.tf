@@ -0,0 +1,6 @@
+resource "elasticsearch_index_reload_search_analyzers" "products" {
+  index = elasticsearch_index.products.name
+  triggers = {
+    synonyms = sha1(file("${path.module}/synonyms.txt"))
+  }
+}
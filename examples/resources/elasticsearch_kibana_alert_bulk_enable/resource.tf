@@ -0,0 +1,9 @@
+# Disable the alerts tagged `maintenance` for the duration of a maintenance
+# window, change the trigger to run the action again
+resource "elasticsearch_kibana_alert_bulk_enable" "maintenance" {
+  filter  = "alert.attributes.tags:maintenance"
+  enabled = false
+  triggers = {
+    window = "2026-10-15T22:00:00Z"
+  }
+}
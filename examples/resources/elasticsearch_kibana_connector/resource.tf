@@ -0,0 +1,13 @@
+resource "elasticsearch_kibana_connector" "webhook" {
+  name              = "terraform-webhook"
+  connector_type_id = ".webhook"
+  config = jsonencode({
+    url     = "https://example.com/hook"
+    method  = "post"
+    hasAuth = true
+  })
+  secrets = jsonencode({
+    user     = "terraform"
+    password = var.webhook_password
+  })
+}
@@ -0,0 +1,5 @@
+resource "elasticsearch_kibana_import" "dashboards" {
+  ndjson              = file("${path.module}/dashboards.ndjson")
+  space_id            = "production"
+  conflict_resolution = "overwrite"
+}
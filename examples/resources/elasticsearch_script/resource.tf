@@ -0,0 +1,16 @@
+resource "elasticsearch_script" "increment" {
+  script_id = "increment"
+  source    = "ctx._source.count += params.increment"
+}
+
+resource "elasticsearch_script" "search_template" {
+  script_id = "search-messages"
+  lang      = "mustache"
+  source = jsonencode({
+    query = {
+      match = {
+        message = "{{query_string}}"
+      }
+    }
+  })
+}
@@ -0,0 +1,16 @@
+resource "elasticsearch_search_template" "messages" {
+  template_id = "search-messages"
+  validate    = true
+  template = jsonencode({
+    query = {
+      match = {
+        message = "{{query_string}}"
+      }
+    }
+    size = "{{size}}"
+  })
+  params = jsonencode({
+    query_string = "hello world"
+    size         = 10
+  })
+}
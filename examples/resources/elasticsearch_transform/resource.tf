@@ -0,0 +1,35 @@
+resource "elasticsearch_transform" "customers" {
+  name        = "ecommerce-customers"
+  description = "Total spent per customer"
+  frequency   = "5m"
+  start       = true
+
+  source {
+    indices = ["kibana_sample_data_ecommerce"]
+  }
+
+  dest {
+    index = "ecommerce-customers"
+  }
+
+  pivot = jsonencode({
+    group_by = {
+      customer_id = { terms = { field = "customer_id" } }
+    }
+    aggregations = {
+      total_spent = { sum = { field = "taxful_total_price" } }
+    }
+  })
+
+  sync {
+    time {
+      field = "order_date"
+    }
+  }
+
+  # run the transform with the privileges of a dedicated API key instead of
+  # the ones of the provider credentials
+  headers = {
+    "es-secondary-authorization" = "ApiKey ${var.transform_api_key}"
+  }
+}
@@ -5,6 +5,7 @@ go 1.12
 require (
 	github.com/aws/aws-sdk-go v1.38.17
 	github.com/deoxxa/aws_signing_client v0.0.0-20161109131055-c20ee106809e
+	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
 	github.com/hashicorp/go-version v1.2.1
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.1.0
 	github.com/kr/pretty v0.2.0 // indirect
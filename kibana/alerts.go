@@ -4,11 +4,46 @@ type AlertSchedule struct {
 	Interval string `json:"interval,omitempty"`
 }
 
+type AlertActionFrequency struct {
+	Summary    bool   `json:"summary"`
+	NotifyWhen string `json:"notifyWhen,omitempty"`
+	Throttle   string `json:"throttle,omitempty"`
+}
+
 type AlertAction struct {
 	ID           string                 `json:"id"`
 	Group        string                 `json:"group"`
 	ActionTypeId string                 `json:"actionTypeId,omitempty"`
 	Params       map[string]interface{} `json:"params,omitempty"`
+	Frequency    *AlertActionFrequency  `json:"frequency,omitempty"`
+}
+
+type AlertExecutionError struct {
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+type AlertExecutionStatus struct {
+	Status            string               `json:"status"`
+	LastExecutionDate string               `json:"lastExecutionDate"`
+	LastDuration      int64                `json:"lastDuration,omitempty"`
+	Error             *AlertExecutionError `json:"error,omitempty"`
+}
+
+type AlertSnoozeRRule struct {
+	Dtstart   string   `json:"dtstart"`
+	Tzid      string   `json:"tzid"`
+	Freq      *int     `json:"freq,omitempty"`
+	Interval  int      `json:"interval,omitempty"`
+	Count     int      `json:"count,omitempty"`
+	Until     string   `json:"until,omitempty"`
+	Byweekday []string `json:"byweekday,omitempty"`
+}
+
+type AlertSnoozeSchedule struct {
+	ID       string           `json:"id,omitempty"`
+	Duration int64            `json:"duration"`
+	RRule    AlertSnoozeRRule `json:"rRule"`
 }
 
 type Alert struct {
@@ -23,4 +58,32 @@ type Alert struct {
 	Consumer    string                 `json:"consumer,omitempty"`
 	Params      map[string]interface{} `json:"params,omitempty"`
 	Actions     []AlertAction          `json:"actions,omitempty"`
+
+	ExecutionStatus *AlertExecutionStatus `json:"executionStatus,omitempty"`
+	SnoozeSchedule  []AlertSnoozeSchedule `json:"snoozeSchedule,omitempty"`
+	ActiveSnoozes   []string              `json:"activeSnoozes,omitempty"`
+	IsSnoozedUntil  string                `json:"isSnoozedUntil,omitempty"`
+	ScheduledTaskID string                `json:"scheduledTaskId,omitempty"`
+	// Version is the version of the alert saved object, it is compared to the
+	// current version before the updates
+	Version string `json:"version,omitempty"`
+}
+
+// AlertUpdate is the subset of Alert fields accepted by the update endpoint,
+// the alert type, consumer and enabled state can't be changed through it.
+type AlertUpdate struct {
+	Name       string                 `json:"name"`
+	Tags       []string               `json:"tags"`
+	Schedule   AlertSchedule          `json:"schedule"`
+	Throttle   string                 `json:"throttle,omitempty"`
+	NotifyWhen string                 `json:"notifyWhen,omitempty"`
+	Params     map[string]interface{} `json:"params"`
+	Actions    []AlertAction          `json:"actions"`
+}
+
+type AlertsFindResponse struct {
+	Page    int     `json:"page"`
+	PerPage int     `json:"perPage"`
+	Total   int     `json:"total"`
+	Data    []Alert `json:"data"`
 }
@@ -0,0 +1,31 @@
+package kibana
+
+// Connector is a Kibana connector, named action before Kibana 7.13. The
+// secrets are never returned by Kibana.
+type Connector struct {
+	ID              string                 `json:"id,omitempty"`
+	Name            string                 `json:"name"`
+	ConnectorTypeID string                 `json:"connector_type_id,omitempty"`
+	ActionTypeID    string                 `json:"actionTypeId,omitempty"`
+	Config          map[string]interface{} `json:"config,omitempty"`
+	Secrets         map[string]interface{} `json:"secrets,omitempty"`
+}
+
+// ConnectorUpdate is the subset of Connector fields accepted by the update
+// endpoint, the connector type can't be changed.
+type ConnectorUpdate struct {
+	Name    string                 `json:"name"`
+	Config  map[string]interface{} `json:"config"`
+	Secrets map[string]interface{} `json:"secrets"`
+}
+
+// ConnectorType is a type of Kibana connector, named action type before
+// Kibana 7.13.
+type ConnectorType struct {
+	ID                     string `json:"id"`
+	Name                   string `json:"name"`
+	Enabled                bool   `json:"enabled"`
+	EnabledInConfig        bool   `json:"enabled_in_config"`
+	EnabledInLicense       bool   `json:"enabled_in_license"`
+	MinimumLicenseRequired string `json:"minimum_license_required"`
+}
@@ -0,0 +1,163 @@
+package kibana
+
+// The alerting rule API replaces the alerts API from Kibana 7.13, its fields
+// are snake cased. The rules are converted from and to alerts so that the
+// provider handles a single type.
+
+type RuleActionFrequency struct {
+	Summary    bool   `json:"summary"`
+	NotifyWhen string `json:"notify_when,omitempty"`
+	Throttle   string `json:"throttle,omitempty"`
+}
+
+// RuleAction is an action of a rule, the connector type is only returned by
+// Kibana, it is rejected in the requests.
+type RuleAction struct {
+	ID              string                 `json:"id"`
+	Group           string                 `json:"group"`
+	ConnectorTypeID string                 `json:"connector_type_id,omitempty"`
+	Params          map[string]interface{} `json:"params,omitempty"`
+	Frequency       *RuleActionFrequency   `json:"frequency,omitempty"`
+}
+
+type RuleExecutionStatus struct {
+	Status            string               `json:"status"`
+	LastExecutionDate string               `json:"last_execution_date"`
+	LastDuration      int64                `json:"last_duration,omitempty"`
+	Error             *AlertExecutionError `json:"error,omitempty"`
+}
+
+type Rule struct {
+	ID         string                 `json:"id,omitempty"`
+	Name       string                 `json:"name"`
+	Tags       []string               `json:"tags,omitempty"`
+	RuleTypeID string                 `json:"rule_type_id,omitempty"`
+	Schedule   AlertSchedule          `json:"schedule,omitempty"`
+	Throttle   string                 `json:"throttle,omitempty"`
+	NotifyWhen string                 `json:"notify_when,omitempty"`
+	Enabled    bool                   `json:"enabled,omitempty"`
+	Consumer   string                 `json:"consumer,omitempty"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	Actions    []RuleAction           `json:"actions,omitempty"`
+
+	ExecutionStatus *RuleExecutionStatus  `json:"execution_status,omitempty"`
+	SnoozeSchedule  []AlertSnoozeSchedule `json:"snooze_schedule,omitempty"`
+	ActiveSnoozes   []string              `json:"active_snoozes,omitempty"`
+	IsSnoozedUntil  string                `json:"is_snoozed_until,omitempty"`
+	ScheduledTaskID string                `json:"scheduled_task_id,omitempty"`
+	Version         string                `json:"version,omitempty"`
+}
+
+// RuleUpdate is the subset of Rule fields accepted by the update endpoint.
+type RuleUpdate struct {
+	Name       string                 `json:"name"`
+	Tags       []string               `json:"tags"`
+	Schedule   AlertSchedule          `json:"schedule"`
+	Throttle   string                 `json:"throttle,omitempty"`
+	NotifyWhen string                 `json:"notify_when,omitempty"`
+	Params     map[string]interface{} `json:"params"`
+	Actions    []RuleAction           `json:"actions"`
+}
+
+type RulesFindResponse struct {
+	Page    int    `json:"page"`
+	PerPage int    `json:"per_page"`
+	Total   int    `json:"total"`
+	Data    []Rule `json:"data"`
+}
+
+// NewRule converts an alert to the body of a rule request.
+func NewRule(alert Alert) Rule {
+	return Rule{
+		ID:         alert.ID,
+		Name:       alert.Name,
+		Tags:       alert.Tags,
+		RuleTypeID: alert.AlertTypeID,
+		Schedule:   alert.Schedule,
+		Throttle:   alert.Throttle,
+		NotifyWhen: alert.NotifyWhen,
+		Enabled:    alert.Enabled,
+		Consumer:   alert.Consumer,
+		Params:     alert.Params,
+		Actions:    newRuleActions(alert.Actions),
+	}
+}
+
+// NewRuleUpdate converts an alert to the body of a rule update request.
+func NewRuleUpdate(alert Alert) RuleUpdate {
+	return RuleUpdate{
+		Name:       alert.Name,
+		Tags:       alert.Tags,
+		Schedule:   alert.Schedule,
+		Throttle:   alert.Throttle,
+		NotifyWhen: alert.NotifyWhen,
+		Params:     alert.Params,
+		Actions:    newRuleActions(alert.Actions),
+	}
+}
+
+func newRuleActions(actions []AlertAction) []RuleAction {
+	ruleActions := make([]RuleAction, 0, len(actions))
+	for _, action := range actions {
+		ruleAction := RuleAction{
+			ID:     action.ID,
+			Group:  action.Group,
+			Params: action.Params,
+		}
+		if action.Frequency != nil {
+			ruleAction.Frequency = &RuleActionFrequency{
+				Summary:    action.Frequency.Summary,
+				NotifyWhen: action.Frequency.NotifyWhen,
+				Throttle:   action.Frequency.Throttle,
+			}
+		}
+		ruleActions = append(ruleActions, ruleAction)
+	}
+	return ruleActions
+}
+
+// Alert converts a rule returned by Kibana to an alert.
+func (r Rule) Alert() Alert {
+	alert := Alert{
+		ID:              r.ID,
+		Name:            r.Name,
+		Tags:            r.Tags,
+		AlertTypeID:     r.RuleTypeID,
+		Schedule:        r.Schedule,
+		Throttle:        r.Throttle,
+		NotifyWhen:      r.NotifyWhen,
+		Enabled:         r.Enabled,
+		Consumer:        r.Consumer,
+		Params:          r.Params,
+		SnoozeSchedule:  r.SnoozeSchedule,
+		ActiveSnoozes:   r.ActiveSnoozes,
+		IsSnoozedUntil:  r.IsSnoozedUntil,
+		ScheduledTaskID: r.ScheduledTaskID,
+		Version:         r.Version,
+	}
+	for _, ruleAction := range r.Actions {
+		action := AlertAction{
+			ID:           ruleAction.ID,
+			Group:        ruleAction.Group,
+			ActionTypeId: ruleAction.ConnectorTypeID,
+			Params:       ruleAction.Params,
+		}
+		if ruleAction.Frequency != nil {
+			action.Frequency = &AlertActionFrequency{
+				Summary:    ruleAction.Frequency.Summary,
+				NotifyWhen: ruleAction.Frequency.NotifyWhen,
+				Throttle:   ruleAction.Frequency.Throttle,
+			}
+		}
+		alert.Actions = append(alert.Actions, action)
+	}
+	if r.ExecutionStatus != nil {
+		alert.ExecutionStatus = &AlertExecutionStatus{
+			Status:            r.ExecutionStatus.Status,
+			LastExecutionDate: r.ExecutionStatus.LastExecutionDate,
+			LastDuration:      r.ExecutionStatus.LastDuration,
+			Error:             r.ExecutionStatus.Error,
+		}
+	}
+	return alert
+}
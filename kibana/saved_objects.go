@@ -0,0 +1,46 @@
+package kibana
+
+// SavedObjectReference identifies a saved object, e.g. an object to export or
+// a missing reference of an imported object.
+type SavedObjectReference struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// SavedObjectsExport is the body of the export endpoint, either the types or
+// the objects are exported.
+type SavedObjectsExport struct {
+	Types                 []string               `json:"type,omitempty"`
+	Objects               []SavedObjectReference `json:"objects,omitempty"`
+	IncludeReferencesDeep bool                   `json:"includeReferencesDeep"`
+	ExcludeExportDetails  bool                   `json:"excludeExportDetails"`
+}
+
+type SavedObjectsImportSuccess struct {
+	Type          string `json:"type"`
+	ID            string `json:"id"`
+	DestinationID string `json:"destinationId,omitempty"`
+	Overwrite     bool   `json:"overwrite,omitempty"`
+}
+
+type SavedObjectsImportErrorDetails struct {
+	Type       string                 `json:"type"`
+	Message    string                 `json:"message,omitempty"`
+	References []SavedObjectReference `json:"references,omitempty"`
+}
+
+type SavedObjectsImportError struct {
+	Type  string                         `json:"type"`
+	ID    string                         `json:"id"`
+	Title string                         `json:"title,omitempty"`
+	Error SavedObjectsImportErrorDetails `json:"error"`
+}
+
+// SavedObjectsImportResponse is the result of an import, the objects without
+// errors are imported even if others fail.
+type SavedObjectsImportResponse struct {
+	Success        bool                        `json:"success"`
+	SuccessCount   int                         `json:"successCount"`
+	SuccessResults []SavedObjectsImportSuccess `json:"successResults"`
+	Errors         []SavedObjectsImportError   `json:"errors"`
+}